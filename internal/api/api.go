@@ -14,21 +14,26 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/audittools"
 	"github.com/sapcc/go-bits/gopherpolicy"
 	"github.com/sapcc/go-bits/httpapi"
 	"github.com/sapcc/go-bits/logg"
 	"github.com/sapcc/go-bits/respondwith"
+	"github.com/sapcc/go-bits/sqlext"
 	"go.xyrillian.de/gg/gsql"
+	. "go.xyrillian.de/gg/option"
+	"go.xyrillian.de/oblast"
 
 	"github.com/sapcc/castellum/internal/core"
 	"github.com/sapcc/castellum/internal/db"
 )
 
 type handler struct {
-	Config    core.Config
+	Config    *core.ConfigSource
 	DB        *gsql.DB
 	Team      core.AssetManagerTeam
+	Notifiers core.NotifierTeam
 	Validator gopherpolicy.Validator
 	Provider  core.ProviderClient
 	Auditor   audittools.Auditor
@@ -38,45 +43,72 @@ type handler struct {
 }
 
 // NewHandler constructs the main httpapi.API for this package.
-func NewHandler(cfg core.Config, dbi *gsql.DB, team core.AssetManagerTeam, validator gopherpolicy.Validator, provider core.ProviderClient, auditor audittools.Auditor, timeNow func() time.Time) httpapi.API {
-	return &handler{Config: cfg, DB: dbi, Team: team, Validator: validator, Provider: provider, Auditor: auditor, TimeNow: timeNow}
+func NewHandler(cfg *core.ConfigSource, dbi *gsql.DB, team core.AssetManagerTeam, notifiers core.NotifierTeam, validator gopherpolicy.Validator, provider core.ProviderClient, auditor audittools.Auditor, timeNow func() time.Time) httpapi.API {
+	return &handler{Config: cfg, DB: dbi, Team: team, Notifiers: notifiers, Validator: validator, Provider: provider, Auditor: auditor, TimeNow: timeNow}
 }
 
 // AddTo implements the httpapi.API interface.
 func (h *handler) AddTo(router *mux.Router) {
-	router.Methods("GET").
-		Path(`/v1/projects/{project_id}`).
-		HandlerFunc(h.GetProject)
+	// all endpoints below a project also exist below a domain
+	for _, scopePath := range []string{`/v1/projects/{project_id}`, `/v1/domains/{domain_id}`} {
+		router.Methods("GET").
+			Path(scopePath).
+			HandlerFunc(h.GetProject)
 
-	router.Methods("GET").
-		Path(`/v1/projects/{project_id}/resources/{asset_type}`).
-		HandlerFunc(h.GetResource)
-	router.Methods("PUT").
-		Path(`/v1/projects/{project_id}/resources/{asset_type}`).
-		HandlerFunc(h.PutResource)
-	router.Methods("DELETE").
-		Path(`/v1/projects/{project_id}/resources/{asset_type}`).
-		HandlerFunc(h.DeleteResource)
+		router.Methods("GET").
+			Path(scopePath + `/resources/{asset_type}`).
+			HandlerFunc(h.GetResource)
+		router.Methods("PUT").
+			Path(scopePath + `/resources/{asset_type}`).
+			HandlerFunc(h.PutResource)
+		router.Methods("DELETE").
+			Path(scopePath + `/resources/{asset_type}`).
+			HandlerFunc(h.DeleteResource)
+		router.Methods("POST").
+			Path(scopePath + `/resources/{asset_type}/simulate`).
+			HandlerFunc(h.PostResourceSimulation)
 
-	router.Methods("GET").
-		Path(`/v1/projects/{project_id}/assets/{asset_type}`).
-		HandlerFunc(h.GetAssets)
-	router.Methods("GET").
-		Path(`/v1/projects/{project_id}/assets/{asset_type}/{asset_uuid}`).
-		HandlerFunc(h.GetAsset)
-	router.Methods("POST").
-		Path(`/v1/projects/{project_id}/assets/{asset_type}/{asset_uuid}/error-resolved`).
-		HandlerFunc(h.PostAssetErrorResolved)
+		router.Methods("GET").
+			Path(scopePath + `/assets/{asset_type}`).
+			HandlerFunc(h.GetAssets)
+		router.Methods("GET").
+			Path(scopePath + `/assets/{asset_type}/{asset_uuid}`).
+			HandlerFunc(h.GetAsset)
+		router.Methods("POST").
+			Path(scopePath + `/assets/{asset_type}/{asset_uuid}/error-resolved`).
+			HandlerFunc(h.PostAssetErrorResolved)
+		router.Methods("POST").
+			Path(scopePath + `/assets/{asset_type}/{asset_uuid}/resize`).
+			HandlerFunc(h.PostAssetResize)
+		router.Methods("POST").
+			Path(scopePath + `/assets/{asset_type}/{asset_uuid}/operations/pending/greenlight`).
+			HandlerFunc(h.PostPendingOperationGreenlight)
+		router.Methods("DELETE").
+			Path(scopePath + `/assets/{asset_type}/{asset_uuid}/operations/pending`).
+			HandlerFunc(h.DeletePendingOperation)
+		router.Methods("GET").
+			Path(scopePath + `/assets/{asset_type}/{asset_uuid}/config`).
+			HandlerFunc(h.GetAssetOverride)
+		router.Methods("PUT").
+			Path(scopePath + `/assets/{asset_type}/{asset_uuid}/config`).
+			HandlerFunc(h.PutAssetOverride)
+		router.Methods("DELETE").
+			Path(scopePath + `/assets/{asset_type}/{asset_uuid}/config`).
+			HandlerFunc(h.DeleteAssetOverride)
+		router.Methods("GET").
+			Path(scopePath + `/assets/{asset_type}/{asset_uuid}/usage-history`).
+			HandlerFunc(h.GetAssetUsageHistory)
 
-	router.Methods("GET").
-		Path(`/v1/projects/{project_id}/resources/{asset_type}/operations/pending`).
-		HandlerFunc(h.GetPendingOperations)
-	router.Methods("GET").
-		Path(`/v1/projects/{project_id}/resources/{asset_type}/operations/recently-failed`).
-		HandlerFunc(h.GetRecentlyFailedOperations)
-	router.Methods("GET").
-		Path(`/v1/projects/{project_id}/resources/{asset_type}/operations/recently-succeeded`).
-		HandlerFunc(h.GetRecentlySucceededOperations)
+		router.Methods("GET").
+			Path(scopePath + `/resources/{asset_type}/operations/pending`).
+			HandlerFunc(h.GetPendingOperations)
+		router.Methods("GET").
+			Path(scopePath + `/resources/{asset_type}/operations/recently-failed`).
+			HandlerFunc(h.GetRecentlyFailedOperations)
+		router.Methods("GET").
+			Path(scopePath + `/resources/{asset_type}/operations/recently-succeeded`).
+			HandlerFunc(h.GetRecentlySucceededOperations)
+	}
 
 	router.Methods("GET").
 		Path(`/v1/operations/pending`).
@@ -87,6 +119,9 @@ func (h *handler) AddTo(router *mux.Router) {
 	router.Methods("GET").
 		Path(`/v1/operations/recently-succeeded`).
 		HandlerFunc(h.GetRecentlySucceededOperations)
+	router.Methods("GET").
+		Path(`/v1/operations/history`).
+		HandlerFunc(h.GetOperationHistory)
 
 	router.Methods("GET").
 		Path(`/v1/admin/resource-scrape-errors`).
@@ -113,41 +148,65 @@ func RequireJSON(w http.ResponseWriter, r *http.Request, data any) bool {
 }
 
 // CheckToken evaluates whether the Openstack access token fits to the scope specified
-// in the requests' variables or query arguments and returns the projectUUID and a token
-// when the validation was successful.
+// in the requests' variables or query arguments and returns the scope UUID (i.e.
+// the project or domain UUID) and a token when the validation was successful.
 func (h handler) CheckToken(w http.ResponseWriter, r *http.Request) (string, *gopherpolicy.Token) {
-	// for endpoints requiring the `project_id` variable, check that it's not empty
-	projectUUID, projectScoped := mux.Vars(r)["project_id"]
-	if projectScoped && projectUUID == "" {
+	// for endpoints requiring the `project_id` or `domain_id` variable, check that it's not empty
+	scopeType := scopeTypeOf(r)
+	scopeUUID, scoped := mux.Vars(r)[string(scopeType)+"_id"]
+	if scoped && scopeUUID == "" {
 		http.NotFound(w, r)
 		return "", nil
 	}
 	// other endpoints might have a project ID in the `project` query argument instead
-	if !projectScoped {
+	if !scoped {
 		if id := r.URL.Query().Get("project"); id != "" {
-			projectScoped = true
-			projectUUID = id
+			scoped = true
+			scopeUUID = id
 		}
 	}
 
 	token := h.Validator.CheckToken(r)
-	// all project-scoped endpoints require the user to have access to the
-	// selected project
-	if projectScoped {
-		projectExists, err := h.SetTokenToProjectScope(r.Context(), token, projectUUID)
-		if respondwith.ObfuscatedErrorText(w, err) || !token.Require(w, "project:access") {
+	// all scoped endpoints require the user to have access to the
+	// selected project or domain
+	if scoped {
+		scopeExists, err := h.SetTokenToScope(r.Context(), token, scopeType, scopeUUID)
+		if respondwith.ObfuscatedErrorText(w, err) || !token.Require(w, string(scopeType)+":access") {
 			return "", nil
 		}
 
 		// only report 404 after having checked access rules, otherwise we might leak
-		// information about which projects exist to unauthorized users
-		if !projectExists {
+		// information about which projects or domains exist to unauthorized users
+		if !scopeExists {
 			http.NotFound(w, r)
 			return "", nil
 		}
 	}
 
-	return projectUUID, token
+	return scopeUUID, token
+}
+
+// scopeTypeOf returns whether the request refers to a domain (for endpoints
+// below /v1/domains/:id) or a project (for all other endpoints).
+func scopeTypeOf(r *http.Request) db.ScopeType {
+	if _, ok := mux.Vars(r)["domain_id"]; ok {
+		return db.DomainScope
+	}
+	return db.ProjectScope
+}
+
+// identifyScopedEndpoint is like httpapi.IdentifyEndpoint, but for endpoints
+// that exist both below /v1/projects/:id and below /v1/domains/:id.
+func identifyScopedEndpoint(r *http.Request, subpath string) {
+	httpapi.IdentifyEndpoint(r, fmt.Sprintf("/v1/%ss/:id%s", scopeTypeOf(r), subpath))
+}
+
+// SetTokenToScope calls either SetTokenToProjectScope or SetTokenToDomainScope.
+func (h handler) SetTokenToScope(ctx context.Context, token *gopherpolicy.Token, scopeType db.ScopeType, scopeUUID string) (scopeExists bool, err error) {
+	if scopeType == db.DomainScope {
+		return h.SetTokenToDomainScope(ctx, token, scopeUUID)
+	}
+	return h.SetTokenToProjectScope(ctx, token, scopeUUID)
 }
 
 // SetTokenToProjectScope sets the tokens' request context to the specific projects
@@ -183,10 +242,30 @@ func (h handler) SetTokenToProjectScope(ctx context.Context, token *gopherpolicy
 	return projectExists, nil
 }
 
+// SetTokenToDomainScope is like SetTokenToProjectScope, but for a domain.
+func (h handler) SetTokenToDomainScope(ctx context.Context, token *gopherpolicy.Token, domainUUID string) (domainExists bool, err error) {
+	objectAttrs := map[string]string{
+		"domain_id":        domainUUID,
+		"target.domain.id": domainUUID,
+	}
+
+	domain, err := h.Provider.GetDomain(ctx, domainUUID)
+	if err != nil {
+		return false, err
+	}
+	if domain != nil {
+		objectAttrs["target.domain.name"] = domain.Name
+	}
+
+	token.Context.Request = objectAttrs
+	logg.Debug("token has object attributes = %v", token.Context.Request)
+	return domain != nil, nil
+}
+
 // LoadResource loads the requested db.Resource and returns it.
 // If the process fails, an error is written to the response and nil is returned.
 // If createIfMissing is true, a new db.Resource will be created.
-func (h handler) LoadResource(w http.ResponseWriter, r *http.Request, projectUUID string, token *gopherpolicy.Token, createIfMissing bool) *db.Resource {
+func (h handler) LoadResource(w http.ResponseWriter, r *http.Request, scopeUUID string, token *gopherpolicy.Token, createIfMissing bool) *db.Resource {
 	ctx := r.Context()
 	assetType := db.AssetType(mux.Vars(r)["asset_type"])
 	if assetType == "" {
@@ -200,11 +279,12 @@ func (h handler) LoadResource(w http.ResponseWriter, r *http.Request, projectUUI
 		return nil
 	}
 
-	if !token.Require(w, assetType.PolicyRuleForRead()) {
+	scopeType := scopeTypeOf(r)
+	if !token.Require(w, assetType.PolicyRuleForRead(scopeType)) {
 		return nil
 	}
 
-	resOrNone, err := db.ResourceStore.SelectOneOrNoneWhere(ctx, h.DB, `scope_uuid = $1 AND asset_type = $2`, projectUUID, assetType)
+	resOrNone, err := db.ResourceStore.SelectOneOrNoneWhere(ctx, h.DB, `scope_uuid = $1 AND asset_type = $2`, scopeUUID, assetType)
 	if respondwith.ObfuscatedErrorText(w, err) {
 		return nil
 	}
@@ -214,12 +294,19 @@ func (h handler) LoadResource(w http.ResponseWriter, r *http.Request, projectUUI
 	}
 
 	if createIfMissing {
-		proj, err := h.Provider.GetProject(r.Context(), projectUUID)
+		if scopeType == db.DomainScope {
+			return &db.Resource{
+				ScopeUUID:  scopeUUID,
+				DomainUUID: scopeUUID,
+				AssetType:  assetType,
+			}
+		}
+		proj, err := h.Provider.GetProject(r.Context(), scopeUUID)
 		if respondwith.ObfuscatedErrorText(w, err) {
 			return nil
 		}
 		return &db.Resource{
-			ScopeUUID:  projectUUID,
+			ScopeUUID:  scopeUUID,
 			DomainUUID: proj.DomainID,
 			AssetType:  assetType,
 		}
@@ -228,17 +315,104 @@ func (h handler) LoadResource(w http.ResponseWriter, r *http.Request, projectUUI
 	return nil
 }
 
-func (h handler) rejectIfResourceSeeded(w http.ResponseWriter, r *http.Request, res db.Resource) bool {
-	proj, err := h.Provider.GetProject(r.Context(), res.ScopeUUID)
+// LoadAsset loads the requested db.Asset within the given resource and returns it.
+// If the process fails, an error is written to the response and nil is returned.
+func (h handler) LoadAsset(w http.ResponseWriter, r *http.Request, res db.Resource) *db.Asset {
+	assetOrNone, err := db.AssetStore.SelectOneOrNoneWhere(r.Context(), h.DB, `resource_id = $1 AND uuid = $2`,
+		res.ID, mux.Vars(r)["asset_uuid"])
 	if respondwith.ObfuscatedErrorText(w, err) {
-		return true
+		return nil
 	}
-	if proj == nil {
-		http.Error(w, "project not found", http.StatusNotFound)
-		return true
+	asset, ok := assetOrNone.Unpack()
+	if !ok {
+		http.NotFound(w, r)
+		return nil
+	}
+	return &asset
+}
+
+// loadAssetOverrides returns the overrides for all assets in the given resource, indexed by asset ID.
+func (h handler) loadAssetOverrides(ctx context.Context, res db.Resource) (map[int64]db.AssetOverride, error) {
+	return assetOverrideByAssetIDIndex.IndexFrom(db.AssetOverrideStore.SelectWhere(ctx, h.DB,
+		`asset_id IN (SELECT id FROM assets WHERE resource_id = $1)`, res.ID))
+}
+
+var assetOverrideByAssetIDIndex = oblast.NewRuntimeIndex(func(o db.AssetOverride) int64 { return o.AssetID })
+
+// overrideFor looks up the override for a single asset in the result of loadAssetOverrides().
+func overrideFor(overrides map[int64]db.AssetOverride, assetID int64) Option[db.AssetOverride] {
+	override, exists := overrides[assetID]
+	if !exists {
+		return None[db.AssetOverride]()
 	}
+	return Some(override)
+}
 
-	domain, err := h.Provider.GetDomain(r.Context(), proj.DomainID)
+// loadUsageGrowth estimates the usage trend of all assets in the given
+// resource from their recent usage samples, indexed by asset ID.
+func (h handler) loadUsageGrowth(ctx context.Context, res db.Resource, info core.AssetTypeInfo) (map[int64]castellum.UsageValues, error) {
+	samplesByAssetID := make(map[int64][]db.AssetUsageSample)
+	err := db.AssetUsageSampleStore.SelectWhere(ctx, h.DB,
+		`asset_id IN (SELECT id FROM assets WHERE resource_id = $1) AND recorded_at >= $2 ORDER BY recorded_at`,
+		res.ID, h.TimeNow().Add(-core.UsageTrendLookback),
+	).Foreach(func(sample db.AssetUsageSample) error {
+		samplesByAssetID[sample.AssetID] = append(samplesByAssetID[sample.AssetID], sample)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]castellum.UsageValues, len(samplesByAssetID))
+	for assetID, samples := range samplesByAssetID {
+		result[assetID] = core.EstimateUsageGrowth(samples, info.UsageMetrics)
+	}
+	return result, nil
+}
+
+var getLastResizesQuery = sqlext.SimplifyWhitespace(`
+	SELECT DISTINCT ON (o.asset_id) o.* FROM finished_operations o
+	  JOIN assets a ON a.id = o.asset_id
+	 WHERE a.resource_id = $1 AND o.outcome = 'succeeded'
+	 ORDER BY o.asset_id, o.finished_at DESC
+`)
+
+// loadLastResizes returns the last operation that succeeded on each asset in
+// the given resource, indexed by asset ID.
+func (h handler) loadLastResizes(ctx context.Context, res db.Resource) (map[int64]db.FinishedOperation, error) {
+	return finishedOperationByAssetIDIndex.IndexFrom(db.FinishedOperationStore.Select(ctx, h.DB, getLastResizesQuery, res.ID))
+}
+
+var finishedOperationByAssetIDIndex = oblast.NewRuntimeIndex(func(op db.FinishedOperation) int64 { return op.AssetID })
+
+// lastResizeFor looks up the last resize of a single asset in the result of loadLastResizes().
+func lastResizeFor(lastResizes map[int64]db.FinishedOperation, assetID int64) Option[db.FinishedOperation] {
+	op, exists := lastResizes[assetID]
+	if !exists {
+		return None[db.FinishedOperation]()
+	}
+	return Some(op)
+}
+
+func (h handler) rejectIfResourceSeeded(w http.ResponseWriter, r *http.Request, res db.Resource) bool {
+	var (
+		proj     *core.CachedProject
+		domainID = res.DomainUUID
+	)
+	if res.ScopeType() == db.ProjectScope {
+		var err error
+		proj, err = h.Provider.GetProject(r.Context(), res.ScopeUUID)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			return true
+		}
+		if proj == nil {
+			http.Error(w, "project not found", http.StatusNotFound)
+			return true
+		}
+		domainID = proj.DomainID
+	}
+
+	domain, err := h.Provider.GetDomain(r.Context(), domainID)
 	if respondwith.ObfuscatedErrorText(w, err) {
 		return true
 	}
@@ -247,7 +421,13 @@ func (h handler) rejectIfResourceSeeded(w http.ResponseWriter, r *http.Request,
 		return true
 	}
 
-	if h.Config.IsSeededResource(*proj, *domain, res.AssetType) {
+	var isSeeded bool
+	if proj == nil {
+		isSeeded = h.Config.Current().IsSeededDomainResource(*domain, res.AssetType)
+	} else {
+		isSeeded = h.Config.Current().IsSeededResource(*proj, *domain, res.AssetType)
+	}
+	if isSeeded {
 		msg := fmt.Sprintf("cannot %s this resource because configuration comes from a static seed", r.Method)
 		http.Error(w, msg, http.StatusConflict)
 		return true
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/respondwith"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// conversion methods
+
+// AssetOverrideFromDB converts a db.AssetOverride into a core.AssetOverrideSpec.
+func AssetOverrideFromDB(o db.AssetOverride) core.AssetOverrideSpec {
+	spec := core.AssetOverrideSpec{Disabled: o.Disabled}
+	if vals, ok := o.LowThresholdPercent.Unpack(); ok {
+		spec.LowThreshold = Some(castellum.Threshold{UsagePercent: vals})
+	}
+	if vals, ok := o.HighThresholdPercent.Unpack(); ok {
+		spec.HighThreshold = Some(castellum.Threshold{UsagePercent: vals})
+	}
+	if vals, ok := o.CriticalThresholdPercent.Unpack(); ok {
+		spec.CriticalThreshold = Some(castellum.Threshold{UsagePercent: vals})
+	}
+	if o.MinimumSize.IsSome() || o.MaximumSize.IsSome() || o.MinimumFreeSize.IsSome() {
+		spec.SizeConstraints = Some(castellum.SizeConstraints{
+			Minimum:     o.MinimumSize,
+			Maximum:     o.MaximumSize,
+			MinimumFree: o.MinimumFreeSize,
+		})
+	}
+	return spec
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// HTTP handlers
+
+// GetAssetOverride handles GET /v1/(projects|domains)/:id/assets/:type/:uuid/config.
+func (h handler) GetAssetOverride(w http.ResponseWriter, r *http.Request) {
+	identifyScopedEndpoint(r, "/assets/:type/:uuid/config")
+	ctx := r.Context()
+	scopeUUID, token := h.CheckToken(w, r)
+	if token == nil {
+		return
+	}
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
+	if dbResource == nil {
+		return
+	}
+	dbAsset := h.LoadAsset(w, r, *dbResource)
+	if dbAsset == nil {
+		return
+	}
+
+	override, err := db.AssetOverrideStore.SelectOneOrNoneWhere(ctx, h.DB, `asset_id = $1`, dbAsset.ID)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		return
+	}
+	respondwith.JSON(w, http.StatusOK, AssetOverrideFromDB(override.UnwrapOr(db.AssetOverride{})))
+}
+
+// PutAssetOverride handles PUT /v1/(projects|domains)/:id/assets/:type/:uuid/config.
+func (h handler) PutAssetOverride(w http.ResponseWriter, r *http.Request) {
+	identifyScopedEndpoint(r, "/assets/:type/:uuid/config")
+	ctx := r.Context()
+	requestTime := time.Now()
+	scopeUUID, token := h.CheckToken(w, r)
+	if token == nil {
+		return
+	}
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
+	if dbResource == nil {
+		return
+	}
+	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite(dbResource.ScopeType())) {
+		return
+	}
+	dbAsset := h.LoadAsset(w, r, *dbResource)
+	if dbAsset == nil {
+		return
+	}
+
+	var input core.AssetOverrideSpec
+	if !RequireJSON(w, r, &input) {
+		return
+	}
+
+	// this allows to reuse h.Auditor.Record() with same parameters except reasonCode
+	doAudit := func(statusCode int) {
+		h.Auditor.Record(audittools.Event{
+			Time:       requestTime,
+			Request:    r,
+			User:       token,
+			ReasonCode: statusCode,
+			Action:     cadf.Action("override/" + string(dbResource.AssetType)),
+			Target: scalingEventTarget{
+				scopeType: dbResource.ScopeType(),
+				scopeUUID: scopeUUID,
+				override:  &assetOverridePayload{AssetUUID: dbAsset.UUID, Config: input},
+			},
+		})
+	}
+
+	// an empty override is equivalent to having no override at all
+	if input.IsEmpty() {
+		_, err := h.DB.Exec(`DELETE FROM asset_overrides WHERE asset_id = $1`, dbAsset.ID)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			doAudit(http.StatusInternalServerError)
+			return
+		}
+		doAudit(http.StatusAccepted)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	_, info := h.Team.ForResource(*dbResource)
+	override := db.AssetOverride{AssetID: dbAsset.ID}
+	errs := core.ApplyAssetOverrideSpecInto(&override, input, *dbResource, h.Config.Current(), info)
+	if len(errs) > 0 {
+		doAudit(http.StatusUnprocessableEntity)
+		http.Error(w, errs.Join("\n"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	err := db.AssetOverrideStore.Upsert(ctx, h.DB, &override)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError)
+		return
+	}
+
+	doAudit(http.StatusAccepted)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeleteAssetOverride handles DELETE /v1/(projects|domains)/:id/assets/:type/:uuid/config.
+func (h handler) DeleteAssetOverride(w http.ResponseWriter, r *http.Request) {
+	identifyScopedEndpoint(r, "/assets/:type/:uuid/config")
+	requestTime := time.Now()
+	scopeUUID, token := h.CheckToken(w, r)
+	if token == nil {
+		return
+	}
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
+	if dbResource == nil {
+		return
+	}
+	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite(dbResource.ScopeType())) {
+		return
+	}
+	dbAsset := h.LoadAsset(w, r, *dbResource)
+	if dbAsset == nil {
+		return
+	}
+
+	// this allows to reuse h.Auditor.Record() with same parameters except reasonCode
+	doAudit := func(statusCode int) {
+		h.Auditor.Record(audittools.Event{
+			Time:       requestTime,
+			Request:    r,
+			User:       token,
+			ReasonCode: statusCode,
+			Action:     cadf.Action("override/" + string(dbResource.AssetType)),
+			Target: scalingEventTarget{
+				scopeType: dbResource.ScopeType(),
+				scopeUUID: scopeUUID,
+				override:  &assetOverridePayload{AssetUUID: dbAsset.UUID},
+			},
+		})
+	}
+
+	_, err := h.DB.Exec(`DELETE FROM asset_overrides WHERE asset_id = $1`, dbAsset.ID)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError)
+		return
+	}
+
+	doAudit(http.StatusNoContent)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/easypg"
+	"github.com/sapcc/go-bits/httptest"
+	"go.xyrillian.de/gg/jsonmatch"
+
+	"github.com/sapcc/castellum/internal/test"
+)
+
+func TestAssetOverrideLifecycle(t *testing.T) {
+	s := test.NewSetup(t,
+		commonSetupOptionsForAPITest(),
+	)
+	commonSetupFillDB(t, s)
+	ctx := t.Context()
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// initially, there are no overrides
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset2/config").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{})
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/doesnotexist/config").
+		ExpectStatus(t, http.StatusNotFound)
+
+	// PUT requires edit access
+	override := jsonmatch.Object{
+		"low_threshold":    jsonmatch.Object{"usage_percent": 0},
+		"high_threshold":   jsonmatch.Object{"usage_percent": 70},
+		"size_constraints": jsonmatch.Object{"minimum": 1000},
+	}
+	s.Validator.Enforcer.Forbid("project:edit:foo")
+	s.Handler.RespondTo(ctx, "PUT /v1/projects/project1/assets/foo/fooasset2/config",
+		httptest.WithJSONBody(override),
+	).ExpectStatus(t, http.StatusForbidden)
+	s.Validator.Enforcer.Allow("project:edit:foo")
+
+	// invalid overrides are rejected
+	s.Auditor.IgnoreEventsUntilNow()
+	s.Handler.RespondTo(ctx, "PUT /v1/projects/project1/assets/foo/fooasset2/config",
+		httptest.WithJSONBody(jsonmatch.Object{"critical_threshold": jsonmatch.Object{"usage_percent": 95}}),
+	).ExpectText(t, http.StatusUnprocessableEntity, "critical threshold cannot be overridden because it is not configured on the resource\n")
+	tr.DBChanges().AssertEmpty()
+	s.Auditor.ExpectEvents(t, cadf.Event{
+		Action:      "override/foo",
+		Outcome:     "failure",
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "422"},
+		RequestPath: "/v1/projects/project1/assets/foo/fooasset2/config",
+		Target: cadf.Resource{
+			TypeURI:   "data/security/project",
+			ID:        "project1",
+			ProjectID: "project1",
+			Attachments: []cadf.Attachment{{
+				Name:    "payload",
+				TypeURI: "mime:application/json",
+				Content: `{"asset_id":"fooasset2","config":{"critical_threshold":{"usage_percent":95}}}`,
+			}},
+		},
+	})
+
+	// valid overrides are stored
+	s.Handler.RespondTo(ctx, "PUT /v1/projects/project1/assets/foo/fooasset2/config",
+		httptest.WithJSONBody(override),
+	).ExpectStatus(t, http.StatusAccepted)
+	tr.DBChanges().AssertEqual(`
+		INSERT INTO asset_overrides (asset_id, low_threshold_percent, high_threshold_percent, min_size) VALUES (2, '{"singular":0}', '{"singular":70}', 1000);
+	`)
+	s.Auditor.ExpectEvents(t, cadf.Event{
+		Action:      "override/foo",
+		Outcome:     "success",
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "202"},
+		RequestPath: "/v1/projects/project1/assets/foo/fooasset2/config",
+		Target: cadf.Resource{
+			TypeURI:   "data/security/project",
+			ID:        "project1",
+			ProjectID: "project1",
+			Attachments: []cadf.Attachment{{
+				Name:    "payload",
+				TypeURI: "mime:application/json",
+				Content: `{"asset_id":"fooasset2","config":{"low_threshold":{"usage_percent":0},"high_threshold":{"usage_percent":70},"size_constraints":{"minimum":1000}}}`,
+			}},
+		},
+	})
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset2/config").
+		ExpectJSON(t, http.StatusOK, override)
+
+	// overrides can be replaced
+	s.Handler.RespondTo(ctx, "PUT /v1/projects/project1/assets/foo/fooasset2/config",
+		httptest.WithJSONBody(jsonmatch.Object{"disabled": true}),
+	).ExpectStatus(t, http.StatusAccepted)
+	tr.DBChanges().AssertEqual(`
+		UPDATE asset_overrides SET disabled = TRUE, low_threshold_percent = NULL, high_threshold_percent = NULL, min_size = NULL WHERE asset_id = 2;
+	`)
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset2/config").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"disabled": true})
+
+	// the simulation takes overrides into account
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/resources/foo/simulate",
+		httptest.WithJSONBody(jsonmatch.Object{
+			"low_threshold":  jsonmatch.Object{"usage_percent": 20, "delay_seconds": 3600},
+			"high_threshold": jsonmatch.Object{"usage_percent": 80, "delay_seconds": 1800},
+			"size_steps":     jsonmatch.Object{"percent": 20},
+		}),
+	).ExpectJSON(t, http.StatusOK, jsonmatch.Object{
+		"assets": jsonmatch.Array{
+			jsonmatch.Object{
+				"id":                  "fooasset1",
+				"size":                1024,
+				"usage_percent":       50,
+				"no_operation_reason": "asset is currently being resized",
+			},
+			jsonmatch.Object{
+				"id":                  "fooasset2",
+				"size":                512,
+				"usage_percent":       80,
+				"no_operation_reason": "autoscaling is disabled for this asset",
+			},
+		},
+	})
+
+	// an empty override is the same as no override
+	s.Handler.RespondTo(ctx, "PUT /v1/projects/project1/assets/foo/fooasset2/config",
+		httptest.WithJSONBody(jsonmatch.Object{}),
+	).ExpectStatus(t, http.StatusAccepted)
+	tr.DBChanges().AssertEqual(`DELETE FROM asset_overrides WHERE asset_id = 2;`)
+
+	// DELETE removes the override
+	s.Handler.RespondTo(ctx, "PUT /v1/projects/project1/assets/foo/fooasset2/config",
+		httptest.WithJSONBody(override),
+	).ExpectStatus(t, http.StatusAccepted)
+	tr.DBChanges().Ignore()
+	s.Handler.RespondTo(ctx, "DELETE /v1/projects/project1/assets/foo/fooasset2/config").
+		ExpectStatus(t, http.StatusNoContent)
+	tr.DBChanges().AssertEqual(`DELETE FROM asset_overrides WHERE asset_id = 2;`)
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset2/config").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{})
+}
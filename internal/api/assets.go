@@ -8,12 +8,15 @@ import (
 	"errors"
 	"net/http"
 	"sort"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/sapcc/go-api-declarations/cadf"
 	"github.com/sapcc/go-api-declarations/castellum"
-	"github.com/sapcc/go-bits/httpapi"
+	"github.com/sapcc/go-bits/audittools"
 	"github.com/sapcc/go-bits/respondwith"
 	"github.com/sapcc/go-bits/sqlext"
+	"go.xyrillian.de/gg/is"
 	. "go.xyrillian.de/gg/option"
 	"go.xyrillian.de/gg/options"
 
@@ -21,15 +24,29 @@ import (
 	"github.com/sapcc/castellum/internal/db"
 )
 
+// Asset is the representation of an asset in the API. It extends
+// castellum.Asset with attributes that are not covered by the shared API
+// declarations.
+type Asset struct {
+	castellum.Asset
+	ExclusionReason   string                              `json:"excluded,omitempty"`
+	CooldownUntil     map[castellum.OperationReason]int64 `json:"cooldown_until,omitempty"`
+	MaintenanceStatus string                              `json:"maintenance,omitempty"`
+}
+
 // AssetFromDB converts a db.Asset into an api.Asset.
-func AssetFromDB(asset db.Asset) castellum.Asset {
-	a := castellum.Asset{
-		UUID:         asset.UUID,
-		Size:         asset.Size,
-		MinimumSize:  asset.StrictMinimumSize,
-		MaximumSize:  asset.StrictMaximumSize,
-		UsagePercent: core.GetMultiUsagePercent(asset.Size, asset.Usage),
-		Stale:        asset.ExpectedSize.IsSome(),
+func AssetFromDB(asset db.Asset) Asset {
+	a := Asset{
+		Asset: castellum.Asset{
+			UUID:         asset.UUID,
+			Size:         asset.Size,
+			MinimumSize:  asset.StrictMinimumSize,
+			MaximumSize:  asset.StrictMaximumSize,
+			UsagePercent: core.GetMultiUsagePercent(asset.Size, asset.Usage),
+			Stale:        asset.ExpectedSize.IsSome(),
+		},
+		ExclusionReason:   asset.ExclusionReason,
+		MaintenanceStatus: asset.MaintenanceStatus,
 	}
 	if asset.ScrapeErrorMessage != "" {
 		a.Checked = Some(castellum.Checked{
@@ -39,12 +56,32 @@ func AssetFromDB(asset db.Asset) castellum.Asset {
 	return a
 }
 
+// Computes the value of the Asset.CooldownUntil field: For each reason that
+// is currently in cooldown, the UNIX timestamp when the cooldown ends.
+func cooldownUntilOf(res db.Resource, lastResize Option[db.FinishedOperation], now time.Time) map[castellum.OperationReason]int64 {
+	op, ok := lastResize.Unpack()
+	if !ok {
+		return nil
+	}
+	var result map[castellum.OperationReason]int64
+	for _, reason := range core.CooldownReasons {
+		end, ok := core.CooldownEndOf(res, reason, op).Unpack()
+		if ok && end.After(now) {
+			if result == nil {
+				result = make(map[castellum.OperationReason]int64)
+			}
+			result[reason] = end.Unix()
+		}
+	}
+	return result
+}
+
 // PendingOperationFromDB converts a db.PendingOperation into an api.Operation.
-func PendingOperationFromDB(dbOp db.PendingOperation, assetID string, res *db.Resource) castellum.StandaloneOperation {
+func PendingOperationFromDB(dbOp db.PendingOperation, assetID string, res *db.Resource, now time.Time) castellum.StandaloneOperation {
 	op := castellum.StandaloneOperation{
 		AssetID: assetID,
 		Operation: castellum.Operation{
-			State:   dbOp.State(),
+			State:   dbOp.StateAt(now),
 			Reason:  dbOp.Reason,
 			OldSize: dbOp.OldSize,
 			NewSize: dbOp.NewSize,
@@ -56,7 +93,10 @@ func PendingOperationFromDB(dbOp db.PendingOperation, assetID string, res *db.Re
 		},
 	}
 	if res != nil {
-		op.ProjectUUID = res.ScopeUUID
+		// .ScopeUUID is either a domain- or project UUID.
+		if res.ScopeType() == db.ProjectScope {
+			op.ProjectUUID = res.ScopeUUID
+		}
 		op.AssetType = string(res.AssetType)
 	}
 	if t, ok := dbOp.ConfirmedAt.Unpack(); ok {
@@ -93,7 +133,10 @@ func FinishedOperationFromDB(dbOp db.FinishedOperation, assetID string, res *db.
 		},
 	}
 	if res != nil {
-		op.ProjectUUID = res.ScopeUUID
+		// .ScopeUUID is either a domain- or project UUID.
+		if res.ScopeType() == db.ProjectScope {
+			op.ProjectUUID = res.ScopeUUID
+		}
 		op.AssetType = string(res.AssetType)
 	}
 	if t, ok := dbOp.ConfirmedAt.Unpack(); ok {
@@ -113,22 +156,34 @@ func FinishedOperationFromDB(dbOp db.FinishedOperation, assetID string, res *db.
 ////////////////////////////////////////////////////////////////////////////////
 // HTTP handlers
 
-// GetAssets handles GET /v1/projects/:id/assets/:type.
+// GetAssets handles GET /v1/(projects|domains)/:id/assets/:type.
 func (h handler) GetAssets(w http.ResponseWriter, r *http.Request) {
-	httpapi.IdentifyEndpoint(r, "/v1/projects/:id/assets/:type")
+	identifyScopedEndpoint(r, "/assets/:type")
 	ctx := r.Context()
-	projectUUID, token := h.CheckToken(w, r)
+	scopeUUID, token := h.CheckToken(w, r)
 	if token == nil {
 		return
 	}
-	dbResource := h.LoadResource(w, r, projectUUID, token, false)
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
 	if dbResource == nil {
 		return
 	}
 
-	var assets []castellum.Asset
+	var lastResizes map[int64]db.FinishedOperation
+	if core.HasCooldown(*dbResource) {
+		var err error
+		lastResizes, err = h.loadLastResizes(ctx, *dbResource)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			return
+		}
+	}
+	now := h.TimeNow()
+
+	var assets []Asset
 	err := db.AssetStore.SelectWhere(ctx, h.DB, `resource_id = $1 ORDER BY uuid`, dbResource.ID).Foreach(func(dbAsset db.Asset) error {
-		assets = append(assets, AssetFromDB(dbAsset))
+		asset := AssetFromDB(dbAsset)
+		asset.CooldownUntil = cooldownUntilOf(*dbResource, lastResizeFor(lastResizes, dbAsset.ID), now)
+		assets = append(assets, asset)
 		return nil
 	})
 	if respondwith.ObfuscatedErrorText(w, err) {
@@ -137,41 +192,46 @@ func (h handler) GetAssets(w http.ResponseWriter, r *http.Request) {
 	sort.Slice(assets, func(i, j int) bool { return assets[i].UUID < assets[j].UUID })
 
 	result := struct {
-		Assets []castellum.Asset `json:"assets"`
+		Assets []Asset `json:"assets"`
 	}{assets}
 	respondwith.JSON(w, http.StatusOK, result)
 }
 
-// GetAsset handles GET /v1/projects/:id/assets/:type/:uuid.
+// GetAsset handles GET /v1/(projects|domains)/:id/assets/:type/:uuid.
 func (h handler) GetAsset(w http.ResponseWriter, r *http.Request) {
-	httpapi.IdentifyEndpoint(r, "/v1/projects/:id/assets/:type/:uuid")
+	identifyScopedEndpoint(r, "/assets/:type/:uuid")
 	ctx := r.Context()
-	projectUUID, token := h.CheckToken(w, r)
+	scopeUUID, token := h.CheckToken(w, r)
 	if token == nil {
 		return
 	}
-	dbResource := h.LoadResource(w, r, projectUUID, token, false)
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
 	if dbResource == nil {
 		return
 	}
 
-	dbAssetOrNone, err := db.AssetStore.SelectOneOrNoneWhere(ctx, h.DB, `resource_id = $1 AND uuid = $2`,
-		dbResource.ID, mux.Vars(r)["asset_uuid"])
-	if respondwith.ObfuscatedErrorText(w, err) {
+	dbAsset := h.LoadAsset(w, r, *dbResource)
+	if dbAsset == nil {
 		return
 	}
-	dbAsset, ok := dbAssetOrNone.Unpack()
-	if !ok {
-		http.NotFound(w, r)
-		return
-	}
-	asset := AssetFromDB(dbAsset)
+	asset := AssetFromDB(*dbAsset)
 
 	dbPendingOp, err := db.PendingOperationStore.SelectOneOrNoneWhere(ctx, h.DB, `asset_id = $1`, dbAsset.ID)
 	if respondwith.ObfuscatedErrorText(w, err) {
 		return
 	}
-	asset.PendingOperation = options.Map(dbPendingOp, func(op db.PendingOperation) castellum.StandaloneOperation { return PendingOperationFromDB(op, "", nil) })
+	asset.PendingOperation = options.Map(dbPendingOp, func(op db.PendingOperation) castellum.StandaloneOperation {
+		return PendingOperationFromDB(op, "", nil, h.TimeNow())
+	})
+
+	if core.HasCooldown(*dbResource) {
+		lastResize, err := db.FinishedOperationStore.SelectOneOrNoneWhere(ctx, h.DB,
+			`asset_id = $1 AND outcome = 'succeeded' ORDER BY finished_at DESC LIMIT 1`, dbAsset.ID)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			return
+		}
+		asset.CooldownUntil = cooldownUntilOf(*dbResource, lastResize, h.TimeNow())
+	}
 
 	_, wantsFinishedOps := r.URL.Query()["history"]
 	if wantsFinishedOps {
@@ -199,19 +259,19 @@ var (
 	`)
 )
 
-// PostAssetErrorResolved handles POST /v1/projects/:id/assets/:type/:uuid/error-resolved.
+// PostAssetErrorResolved handles POST /v1/(projects|domains)/:id/assets/:type/:uuid/error-resolved.
 func (h handler) PostAssetErrorResolved(w http.ResponseWriter, r *http.Request) {
-	httpapi.IdentifyEndpoint(r, "/v1/projects/:id/assets/:type/:uuid/error-resolved")
+	identifyScopedEndpoint(r, "/assets/:type/:uuid/error-resolved")
 	ctx := r.Context()
 
-	projectUUID, token := h.CheckToken(w, r)
+	scopeUUID, token := h.CheckToken(w, r)
 	if token == nil {
 		return
 	}
 	if !token.Require(w, "cluster:access") {
 		return
 	}
-	dbResource := h.LoadResource(w, r, projectUUID, token, false)
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
 	if dbResource == nil {
 		return
 	}
@@ -254,3 +314,376 @@ func (h handler) PostAssetErrorResolved(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// PostPendingOperationGreenlight handles POST /v1/(projects|domains)/:id/assets/:type/:uuid/operations/pending/greenlight.
+func (h handler) PostPendingOperationGreenlight(w http.ResponseWriter, r *http.Request) {
+	identifyScopedEndpoint(r, "/assets/:type/:uuid/operations/pending/greenlight")
+	ctx := r.Context()
+	requestTime := time.Now()
+	scopeUUID, token := h.CheckToken(w, r)
+	if token == nil {
+		return
+	}
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
+	if dbResource == nil {
+		return
+	}
+	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite(dbResource.ScopeType())) {
+		return
+	}
+	dbAsset := h.LoadAsset(w, r, *dbResource)
+	if dbAsset == nil {
+		return
+	}
+
+	// this allows to reuse h.Auditor.Record() with same parameters except reasonCode
+	// (the operation is only known once it has been greenlit successfully)
+	doAudit := func(statusCode int, op *castellum.StandaloneOperation) {
+		h.Auditor.Record(audittools.Event{
+			Time:       requestTime,
+			Request:    r,
+			User:       token,
+			ReasonCode: statusCode,
+			Action:     cadf.Action("greenlight/" + string(dbResource.AssetType)),
+			Target: scalingEventTarget{
+				scopeType: dbResource.ScopeType(),
+				scopeUUID: scopeUUID,
+				operation: op,
+			},
+		})
+	}
+
+	// the request body is optional; without it, the operation is greenlit immediately
+	var input struct {
+		AtUnix Option[int64] `json:"at,omitzero"`
+	}
+	if r.ContentLength != 0 && !RequireJSON(w, r, &input) {
+		return
+	}
+	now := h.TimeNow()
+	greenlitAt := now
+	if at, ok := input.AtUnix.Unpack(); ok {
+		greenlitAt = time.Unix(at, 0).UTC()
+		if greenlitAt.Before(now) {
+			doAudit(http.StatusUnprocessableEntity, nil)
+			http.Error(w, "cannot greenlight operation for a point in time in the past", http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	tx, err := h.DB.Begin()
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	defer sqlext.RollbackUnlessCommitted(tx)
+
+	// lock the operation to avoid racing with the observer
+	dbOpOrNone, err := db.PendingOperationStore.SelectOneOrNoneWhere(ctx, tx, `asset_id = $1 FOR UPDATE`, dbAsset.ID)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	dbOp, ok := dbOpOrNone.Unpack()
+	if !ok {
+		doAudit(http.StatusNotFound, nil)
+		http.Error(w, "no pending operation for this asset", http.StatusNotFound)
+		return
+	}
+	if dbOp.ConfirmedAt.IsNone() {
+		doAudit(http.StatusConflict, nil)
+		http.Error(w, "cannot greenlight operation that has not been confirmed yet", http.StatusConflict)
+		return
+	}
+	if dbOp.GreenlitAt.IsSomeAnd(is.NotAfter(now)) {
+		doAudit(http.StatusConflict, nil)
+		http.Error(w, "operation has already been greenlit", http.StatusConflict)
+		return
+	}
+
+	previousState := dbOp.StateAt(now)
+	dbOp.GreenlitAt = Some(greenlitAt)
+	dbOp.GreenlitByUserUUID = Some(token.UserUUID())
+	err = db.PendingOperationStore.Update(ctx, tx, dbOp)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	// (a greenlight that is scheduled for the future does not change the state yet)
+	if newState := dbOp.StateAt(now); previousState != newState {
+		transition := core.TransitionOfPendingOperation(*dbResource, dbAsset.UUID, dbOp, previousState, newState)
+		err = h.Notifiers.RecordStateTransition(ctx, tx, transition, now)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			doAudit(http.StatusInternalServerError, nil)
+			return
+		}
+	}
+	err = tx.Commit()
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+
+	op := PendingOperationFromDB(dbOp, dbAsset.UUID, dbResource, now)
+	doAudit(http.StatusOK, &op)
+	respondwith.JSON(w, http.StatusOK, op)
+}
+
+// PostAssetResize handles POST /v1/(projects|domains)/:id/assets/:type/:uuid/resize.
+func (h handler) PostAssetResize(w http.ResponseWriter, r *http.Request) {
+	identifyScopedEndpoint(r, "/assets/:type/:uuid/resize")
+	ctx := r.Context()
+	requestTime := time.Now()
+	scopeUUID, token := h.CheckToken(w, r)
+	if token == nil {
+		return
+	}
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
+	if dbResource == nil {
+		return
+	}
+	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite(dbResource.ScopeType())) {
+		return
+	}
+	dbAsset := h.LoadAsset(w, r, *dbResource)
+	if dbAsset == nil {
+		return
+	}
+
+	var input struct {
+		NewSize uint64 `json:"new_size"`
+	}
+	if !RequireJSON(w, r, &input) {
+		return
+	}
+
+	// this allows to reuse h.Auditor.Record() with same parameters except reasonCode
+	doAudit := func(statusCode int, op *castellum.StandaloneOperation) {
+		h.Auditor.Record(audittools.Event{
+			Time:       requestTime,
+			Request:    r,
+			User:       token,
+			ReasonCode: statusCode,
+			Action:     cadf.Action("resize/" + string(dbResource.AssetType)),
+			Target: scalingEventTarget{
+				scopeType: dbResource.ScopeType(),
+				scopeUUID: scopeUUID,
+				operation: op,
+			},
+		})
+	}
+
+	tx, err := h.DB.Begin()
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	defer sqlext.RollbackUnlessCommitted(tx)
+
+	// reload the asset to avoid racing with the observer and the worker
+	*dbAsset, err = db.AssetStore.SelectOneWhere(ctx, tx, `id = $1 FOR UPDATE`, dbAsset.ID)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	// we cannot compute a meaningful operation from a size that we know to be outdated
+	if dbAsset.NeverScraped {
+		doAudit(http.StatusConflict, nil)
+		http.Error(w, "cannot resize before the current size of the asset is known", http.StatusConflict)
+		return
+	}
+	if dbAsset.ExpectedSize.IsSome() {
+		doAudit(http.StatusConflict, nil)
+		http.Error(w, "cannot resize while the previous resize is not yet reflected in the backend", http.StatusConflict)
+		return
+	}
+	if dbAsset.ExclusionReason != "" {
+		doAudit(http.StatusConflict, nil)
+		http.Error(w, "cannot resize an asset that is "+dbAsset.ExclusionReason, http.StatusConflict)
+		return
+	}
+	errs := core.CheckManualResize(*dbResource, *dbAsset, h.Config.Current(), input.NewSize)
+	if len(errs) > 0 {
+		doAudit(http.StatusUnprocessableEntity, nil)
+		http.Error(w, errs.Join("\n"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// a pending operation that was not greenlit yet is replaced by the manual
+	// operation, but greenlit operations may already be executing on a worker
+	now := h.TimeNow()
+	oldOpOrNone, err := db.PendingOperationStore.SelectOneOrNoneWhere(ctx, tx, `asset_id = $1 FOR UPDATE`, dbAsset.ID)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	if oldOp, ok := oldOpOrNone.Unpack(); ok {
+		if oldOp.GreenlitAt.IsSomeAnd(is.NotAfter(now)) {
+			doAudit(http.StatusConflict, nil)
+			http.Error(w, "cannot resize while another operation is being executed", http.StatusConflict)
+			return
+		}
+		finishedOp := oldOp.IntoFinishedOperation(castellum.OperationOutcomeCancelled, now)
+		transition := core.TransitionOfFinishedOperation(*dbResource, dbAsset.UUID, finishedOp, oldOp.StateAt(now))
+		err = h.Notifiers.RecordStateTransition(ctx, tx, transition, now)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			doAudit(http.StatusInternalServerError, nil)
+			return
+		}
+		err = db.PendingOperationStore.Delete(ctx, tx, oldOp)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			doAudit(http.StatusInternalServerError, nil)
+			return
+		}
+		err = db.FinishedOperationStore.Insert(ctx, tx, &finishedOp)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			doAudit(http.StatusInternalServerError, nil)
+			return
+		}
+	}
+
+	// since the user explicitly asked for this operation, it does not need to go
+	// through confirmation or approval (but it does need to honor the
+	// maintenance window of the resource)
+	dbOp := db.PendingOperation{
+		AssetID:            dbAsset.ID,
+		Reason:             db.OperationReasonManual,
+		OldSize:            dbAsset.Size,
+		NewSize:            input.NewSize,
+		Usage:              dbAsset.Usage,
+		CreatedAt:          now,
+		ConfirmedAt:        Some(now),
+		GreenlitAt:         core.GreenlightTimeOf(*dbResource, db.OperationReasonManual, now),
+		GreenlitByUserUUID: Some(token.UserUUID()),
+	}
+	err = db.PendingOperationStore.Insert(ctx, tx, &dbOp)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	transition := core.TransitionOfPendingOperation(*dbResource, dbAsset.UUID, dbOp, castellum.OperationStateDidNotExist, dbOp.StateAt(now))
+	err = h.Notifiers.RecordStateTransition(ctx, tx, transition, now)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	err = tx.Commit()
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+
+	op := PendingOperationFromDB(dbOp, dbAsset.UUID, dbResource, now)
+	doAudit(http.StatusAccepted, &op)
+	respondwith.JSON(w, http.StatusAccepted, op)
+}
+
+// DeletePendingOperation handles DELETE /v1/(projects|domains)/:id/assets/:type/:uuid/operations/pending.
+func (h handler) DeletePendingOperation(w http.ResponseWriter, r *http.Request) {
+	identifyScopedEndpoint(r, "/assets/:type/:uuid/operations/pending")
+	ctx := r.Context()
+	requestTime := time.Now()
+	scopeUUID, token := h.CheckToken(w, r)
+	if token == nil {
+		return
+	}
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
+	if dbResource == nil {
+		return
+	}
+	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite(dbResource.ScopeType())) {
+		return
+	}
+	dbAsset := h.LoadAsset(w, r, *dbResource)
+	if dbAsset == nil {
+		return
+	}
+	snoozeDuration, err := ParseAge(r.URL.Query(), "snooze", "0m")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// this allows to reuse h.Auditor.Record() with same parameters except reasonCode
+	// (the operation is only known once it has been cancelled successfully)
+	doAudit := func(statusCode int, op *castellum.StandaloneOperation) {
+		h.Auditor.Record(audittools.Event{
+			Time:       requestTime,
+			Request:    r,
+			User:       token,
+			ReasonCode: statusCode,
+			Action:     cadf.Action("cancel/" + string(dbResource.AssetType)),
+			Target: scalingEventTarget{
+				scopeType: dbResource.ScopeType(),
+				scopeUUID: scopeUUID,
+				operation: op,
+			},
+		})
+	}
+
+	tx, err := h.DB.Begin()
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	defer sqlext.RollbackUnlessCommitted(tx)
+
+	// lock the operation to avoid racing with the observer and the worker
+	dbOpOrNone, err := db.PendingOperationStore.SelectOneOrNoneWhere(ctx, tx, `asset_id = $1 FOR UPDATE`, dbAsset.ID)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	dbOp, ok := dbOpOrNone.Unpack()
+	if !ok {
+		doAudit(http.StatusNotFound, nil)
+		http.Error(w, "no pending operation for this asset", http.StatusNotFound)
+		return
+	}
+	now := h.TimeNow()
+	if dbOp.GreenlitAt.IsSomeAnd(is.NotAfter(now)) {
+		doAudit(http.StatusConflict, nil)
+		http.Error(w, "cannot cancel operation that has already been greenlit", http.StatusConflict)
+		return
+	}
+
+	finishedOp := dbOp.IntoFinishedOperation(castellum.OperationOutcomeCancelled, now)
+	finishedOp.CancelledByUserUUID = Some(token.UserUUID())
+	err = db.PendingOperationStore.Delete(ctx, tx, dbOp)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	err = db.FinishedOperationStore.Insert(ctx, tx, &finishedOp)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+	transition := core.TransitionOfFinishedOperation(*dbResource, dbAsset.UUID, finishedOp, dbOp.StateAt(now))
+	err = h.Notifiers.RecordStateTransition(ctx, tx, transition, now)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+
+	// if requested, keep the observer from recreating the same operation right away
+	// (this only updates the snooze fields to not overwrite concurrent changes by the observer)
+	if snoozeDuration > 0 {
+		_, err = tx.Exec(`UPDATE assets SET snoozed_reason = $1, snoozed_until = $2 WHERE id = $3`,
+			dbOp.Reason, now.Add(snoozeDuration), dbAsset.ID)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			doAudit(http.StatusInternalServerError, nil)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if respondwith.ObfuscatedErrorText(w, err) {
+		doAudit(http.StatusInternalServerError, nil)
+		return
+	}
+
+	op := FinishedOperationFromDB(finishedOp, dbAsset.UUID, dbResource)
+	doAudit(http.StatusOK, &op)
+	respondwith.JSON(w, http.StatusOK, op)
+}
@@ -8,8 +8,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sapcc/go-api-declarations/cadf"
 	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/easypg"
+	"github.com/sapcc/go-bits/httptest"
 	"github.com/sapcc/go-bits/must"
 	"go.xyrillian.de/gg/jsonmatch"
 	. "go.xyrillian.de/gg/option"
@@ -52,6 +54,21 @@ func TestGetAssets(t *testing.T) {
 	s.Validator.Enforcer.Forbid("project:edit:foo") // this should not be an issue
 	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo").
 		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"assets": expectedAssets})
+
+	// excluded assets show the reason for their exclusion
+	must.SucceedT(t, s.DBExec(`UPDATE assets SET exclusion_reason = $1 WHERE id = 2`, "excluded by asset ID"))
+	expectedAssets[1]["excluded"] = "excluded by asset ID"
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"assets": expectedAssets})
+
+	// assets that were resized recently show until when they are in cooldown
+	// (fooasset1 was upsized at t=44, and the current time is t=3600)
+	must.SucceedT(t, s.DBExec(`UPDATE resources SET low_cooldown_seconds = 7200, high_cooldown_seconds = 600 WHERE id = 1`))
+	expectedAssets[0]["cooldown_until"] = jsonmatch.Object{"low": 7244}
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"assets": expectedAssets})
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset1").
+		ExpectJSON(t, http.StatusOK, expectedAssets[0])
 }
 
 func TestGetAsset(t *testing.T) {
@@ -246,3 +263,481 @@ func TestPostAssetErrorResolved(t *testing.T) {
 	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/fooasset1/error-resolved").
 		ExpectStatus(t, http.StatusConflict)
 }
+
+func TestPostPendingOperationGreenlight(t *testing.T) {
+	s := test.NewSetup(t,
+		commonSetupOptionsForAPITest(),
+	)
+	commonSetupFillDB(t, s)
+	ctx := t.Context()
+	s.Validator.Auth = map[string]string{"user_id": "user4"}
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	path := "POST /v1/projects/project1/assets/foo/fooasset1/operations/pending/greenlight"
+	failureEvent := func(reasonCode string) cadf.Event {
+		return cadf.Event{
+			Action:      "greenlight/foo",
+			Outcome:     "failure",
+			Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: reasonCode},
+			RequestPath: "/v1/projects/project1/assets/foo/fooasset1/operations/pending/greenlight",
+			Target: cadf.Resource{
+				TypeURI:   "data/security/project",
+				ID:        "project1",
+				ProjectID: "project1",
+			},
+		}
+	}
+
+	// endpoint requires edit access
+	s.Validator.Enforcer.Forbid("project:edit:foo")
+	s.Handler.RespondTo(ctx, path).
+		ExpectStatus(t, http.StatusForbidden)
+	s.Validator.Enforcer.Allow("project:edit:foo")
+
+	// expect error for unknown asset
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/doesnotexist/operations/pending/greenlight").
+		ExpectStatus(t, http.StatusNotFound)
+
+	// expect error for asset without pending operation
+	s.Auditor.IgnoreEventsUntilNow()
+	s.Handler.RespondTo(ctx, path).
+		ExpectText(t, http.StatusNotFound, "no pending operation for this asset\n")
+	s.Auditor.ExpectEvents(t, failureEvent("404"))
+
+	// expect error for operation that is not confirmed yet
+	pendingOp := db.PendingOperation{
+		AssetID:   1,
+		Reason:    castellum.OperationReasonLow,
+		OldSize:   1024,
+		NewSize:   512,
+		Usage:     castellum.UsageValues{castellum.SingularUsageMetric: 128},
+		CreatedAt: s.Clock.Now(),
+	}
+	must.SucceedT(t, db.PendingOperationStore.Insert(ctx, s.DB, &pendingOp))
+	tr.DBChanges().Ignore()
+	s.Handler.RespondTo(ctx, path).
+		ExpectText(t, http.StatusConflict, "cannot greenlight operation that has not been confirmed yet\n")
+	s.Auditor.ExpectEvents(t, failureEvent("409"))
+
+	// expect error for greenlight in the past
+	s.Clock.StepBy(time.Minute)
+	pendingOp.ConfirmedAt = Some(s.Clock.Now())
+	must.SucceedT(t, db.PendingOperationStore.Update(ctx, s.DB, pendingOp))
+	tr.DBChanges().Ignore()
+	s.Handler.RespondTo(ctx, path,
+		httptest.WithJSONBody(jsonmatch.Object{"at": s.Clock.Now().Unix() - 10}),
+	).ExpectText(t, http.StatusUnprocessableEntity, "cannot greenlight operation for a point in time in the past\n")
+	tr.DBChanges().AssertEmpty()
+	s.Auditor.ExpectEvents(t, failureEvent("422"))
+
+	// happy path: greenlight for a point in time in the future (the operation
+	// stays in state "confirmed" until then)
+	futureTime := s.Clock.Now().Add(time.Hour)
+	expectedOpJSON := jsonmatch.Object{
+		"project_id": "project1",
+		"asset_type": "foo",
+		"asset_id":   "fooasset1",
+		"state":      "confirmed",
+		"reason":     "low",
+		"old_size":   1024,
+		"new_size":   512,
+		"created": jsonmatch.Object{
+			"at":            pendingOp.CreatedAt.Unix(),
+			"usage_percent": 12.5,
+		},
+		"confirmed": jsonmatch.Object{
+			"at": s.Clock.Now().Unix(),
+		},
+		"greenlit": jsonmatch.Object{
+			"at":      futureTime.Unix(),
+			"by_user": "user4",
+		},
+	}
+	s.Handler.RespondTo(ctx, path,
+		httptest.WithJSONBody(jsonmatch.Object{"at": futureTime.Unix()}),
+	).ExpectJSON(t, http.StatusOK, expectedOpJSON)
+	tr.DBChanges().AssertEqualf(`
+		UPDATE pending_operations SET greenlit_at = %[1]d, greenlit_by_user_uuid = 'user4' WHERE id = 1 AND asset_id = 1;
+	`,
+		futureTime.Unix())
+	s.Auditor.ExpectEvents(t, cadf.Event{
+		Action:      "greenlight/foo",
+		Outcome:     "success",
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "200"},
+		RequestPath: "/v1/projects/project1/assets/foo/fooasset1/operations/pending/greenlight",
+		Target: cadf.Resource{
+			TypeURI:   "data/security/project",
+			ID:        "project1",
+			ProjectID: "project1",
+			Attachments: []cadf.Attachment{{
+				Name:    "payload",
+				TypeURI: "mime:application/json",
+				Content: toJSONVia[castellum.StandaloneOperation](expectedOpJSON),
+			}},
+		},
+	})
+
+	// a scheduled greenlight can be moved forward to "now"
+	expectedOpJSON["state"] = "greenlit"
+	expectedOpJSON["greenlit"] = jsonmatch.Object{"at": s.Clock.Now().Unix(), "by_user": "user4"}
+	s.Handler.RespondTo(ctx, path).
+		ExpectJSON(t, http.StatusOK, expectedOpJSON)
+	tr.DBChanges().AssertEqualf(`
+		UPDATE pending_operations SET greenlit_at = %[1]d WHERE id = 1 AND asset_id = 1;
+	`,
+		s.Clock.Now().Unix())
+	s.Auditor.ExpectEvents(t, cadf.Event{
+		Action:      "greenlight/foo",
+		Outcome:     "success",
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "200"},
+		RequestPath: "/v1/projects/project1/assets/foo/fooasset1/operations/pending/greenlight",
+		Target: cadf.Resource{
+			TypeURI:   "data/security/project",
+			ID:        "project1",
+			ProjectID: "project1",
+			Attachments: []cadf.Attachment{{
+				Name:    "payload",
+				TypeURI: "mime:application/json",
+				Content: toJSONVia[castellum.StandaloneOperation](expectedOpJSON),
+			}},
+		},
+	})
+
+	// expect error when trying to greenlight again
+	s.Handler.RespondTo(ctx, path).
+		ExpectText(t, http.StatusConflict, "operation has already been greenlit\n")
+	tr.DBChanges().AssertEmpty()
+	s.Auditor.ExpectEvents(t, failureEvent("409"))
+}
+
+func TestPostAssetResize(t *testing.T) {
+	s := test.NewSetup(t,
+		commonSetupOptionsForAPITest(),
+	)
+	commonSetupFillDB(t, s)
+	ctx := t.Context()
+	s.Validator.Auth = map[string]string{"user_id": "user4"}
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// endpoint requires edit access
+	s.Validator.Enforcer.Forbid("project:edit:foo")
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/fooasset2/resize",
+		httptest.WithJSONBody(jsonmatch.Object{"new_size": 800}),
+	).ExpectStatus(t, http.StatusForbidden)
+	s.Validator.Enforcer.Allow("project:edit:foo")
+
+	// expect error for unknown asset
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/doesnotexist/resize",
+		httptest.WithJSONBody(jsonmatch.Object{"new_size": 800}),
+	).ExpectStatus(t, http.StatusNotFound)
+
+	// expect error for target size that violates the asset's constraints
+	s.Auditor.IgnoreEventsUntilNow()
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/fooasset2/resize",
+		httptest.WithJSONBody(jsonmatch.Object{"new_size": 2000}),
+	).ExpectText(t, http.StatusUnprocessableEntity, "new size must be at most 1024 (maximum size of asset)\n")
+	tr.DBChanges().AssertEmpty()
+	s.Auditor.ExpectEvents(t, cadf.Event{
+		Action:      "resize/foo",
+		Outcome:     "failure",
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "422"},
+		RequestPath: "/v1/projects/project1/assets/foo/fooasset2/resize",
+		Target: cadf.Resource{
+			TypeURI:   "data/security/project",
+			ID:        "project1",
+			ProjectID: "project1",
+		},
+	})
+
+	// happy path: a pending operation that has not been greenlit yet gets
+	// replaced by the manual operation, which is greenlit immediately
+	pendingOp := db.PendingOperation{
+		AssetID:   2,
+		Reason:    castellum.OperationReasonHigh,
+		OldSize:   512,
+		NewSize:   614,
+		Usage:     castellum.UsageValues{castellum.SingularUsageMetric: 409.6},
+		CreatedAt: s.Clock.Now(),
+	}
+	must.SucceedT(t, db.PendingOperationStore.Insert(ctx, s.DB, &pendingOp))
+	tr.DBChanges().Ignore()
+
+	s.Clock.StepBy(time.Minute)
+	expectedOpJSON := jsonmatch.Object{
+		"project_id": "project1",
+		"asset_type": "foo",
+		"asset_id":   "fooasset2",
+		"state":      "greenlit",
+		"reason":     "manual",
+		"old_size":   512,
+		"new_size":   800,
+		"created": jsonmatch.Object{
+			"at":            s.Clock.Now().Unix(),
+			"usage_percent": 80,
+		},
+		"confirmed": jsonmatch.Object{
+			"at": s.Clock.Now().Unix(),
+		},
+		"greenlit": jsonmatch.Object{
+			"at":      s.Clock.Now().Unix(),
+			"by_user": "user4",
+		},
+	}
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/fooasset2/resize",
+		httptest.WithJSONBody(jsonmatch.Object{"new_size": 800}),
+	).ExpectJSON(t, http.StatusAccepted, expectedOpJSON)
+	tr.DBChanges().AssertEqualf(`
+		INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, finished_at, usage) VALUES (2, 'high', 'cancelled', 512, 614, %[1]d, %[2]d, '{"singular":409.6}');
+		DELETE FROM pending_operations WHERE id = 1 AND asset_id = 2;
+		INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, confirmed_at, greenlit_at, greenlit_by_user_uuid, usage) VALUES (2, 2, 'manual', 512, 800, %[2]d, %[2]d, %[2]d, 'user4', '{"singular":409.6}');
+	`,
+		pendingOp.CreatedAt.Unix(),
+		s.Clock.Now().Unix(),
+	)
+	s.Auditor.ExpectEvents(t, cadf.Event{
+		Action:      "resize/foo",
+		Outcome:     "success",
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "202"},
+		RequestPath: "/v1/projects/project1/assets/foo/fooasset2/resize",
+		Target: cadf.Resource{
+			TypeURI:   "data/security/project",
+			ID:        "project1",
+			ProjectID: "project1",
+			Attachments: []cadf.Attachment{{
+				Name:    "payload",
+				TypeURI: "mime:application/json",
+				Content: toJSONVia[castellum.StandaloneOperation](expectedOpJSON),
+			}},
+		},
+	})
+
+	// the manual operation shows up in the list of pending operations
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/resources/foo/operations/pending").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{
+			"pending_operations": jsonmatch.Array{expectedOpJSON},
+		})
+
+	// the greenlit operation may already be executing, so it cannot be replaced
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/fooasset2/resize",
+		httptest.WithJSONBody(jsonmatch.Object{"new_size": 900}),
+	).ExpectText(t, http.StatusConflict, "cannot resize while another operation is being executed\n")
+	tr.DBChanges().AssertEmpty()
+
+	// expect error while a previous resize has not been reflected in the asset size yet
+	s.Auditor.IgnoreEventsUntilNow()
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/fooasset1/resize",
+		httptest.WithJSONBody(jsonmatch.Object{"new_size": 1100}),
+	).ExpectText(t, http.StatusConflict, "cannot resize while the previous resize is not yet reflected in the backend\n")
+	tr.DBChanges().AssertEmpty()
+	s.Auditor.ExpectEvents(t, cadf.Event{
+		Action:      "resize/foo",
+		Outcome:     "failure",
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "409"},
+		RequestPath: "/v1/projects/project1/assets/foo/fooasset1/resize",
+		Target: cadf.Resource{
+			TypeURI:   "data/security/project",
+			ID:        "project1",
+			ProjectID: "project1",
+		},
+	})
+
+	// excluded assets cannot be resized
+	must.SucceedT(t, s.DBExec(`UPDATE assets SET expected_size = NULL, exclusion_reason = $1 WHERE id = 1`, "excluded by asset ID"))
+	tr.DBChanges().Ignore()
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/fooasset1/resize",
+		httptest.WithJSONBody(jsonmatch.Object{"new_size": 1100}),
+	).ExpectText(t, http.StatusConflict, "cannot resize an asset that is excluded by asset ID\n")
+	tr.DBChanges().AssertEmpty()
+	must.SucceedT(t, s.DBExec(`UPDATE assets SET exclusion_reason = '' WHERE id = 1`))
+	tr.DBChanges().Ignore()
+
+	// expect error while the current asset size is not known
+	must.SucceedT(t, s.DBExec(`UPDATE assets SET expected_size = NULL, never_scraped = TRUE WHERE id = 1`))
+	tr.DBChanges().Ignore()
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/fooasset1/resize",
+		httptest.WithJSONBody(jsonmatch.Object{"new_size": 1100}),
+	).ExpectText(t, http.StatusConflict, "cannot resize before the current size of the asset is known\n")
+	tr.DBChanges().AssertEmpty()
+
+	// manual operations do not need approval, but they honor the maintenance
+	// window (between 12:00 and 13:00 UTC, while the test clock is shortly after 01:00 UTC)
+	must.SucceedT(t, s.DBExec(`UPDATE assets SET never_scraped = FALSE WHERE id = 1`))
+	must.SucceedT(t, s.DBExec(`UPDATE resources SET high_requires_approval = TRUE, maintenance_window_json = $1 WHERE id = 1`, `{"start":"12:00","end":"13:00"}`))
+	tr.DBChanges().Ignore()
+	windowStart := time.Unix(12*3600, 0).UTC()
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/assets/foo/fooasset1/resize",
+		httptest.WithJSONBody(jsonmatch.Object{"new_size": 1100}),
+	).ExpectJSON(t, http.StatusAccepted, jsonmatch.Object{
+		"project_id": "project1",
+		"asset_type": "foo",
+		"asset_id":   "fooasset1",
+		"state":      "confirmed",
+		"reason":     "manual",
+		"old_size":   1024,
+		"new_size":   1100,
+		"created": jsonmatch.Object{
+			"at":            s.Clock.Now().Unix(),
+			"usage_percent": 50,
+		},
+		"confirmed": jsonmatch.Object{
+			"at": s.Clock.Now().Unix(),
+		},
+		"greenlit": jsonmatch.Object{
+			"at":      windowStart.Unix(),
+			"by_user": "user4",
+		},
+	})
+	tr.DBChanges().AssertEqualf(`
+		INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, confirmed_at, greenlit_at, greenlit_by_user_uuid, usage) VALUES (3, 1, 'manual', 1024, 1100, %[1]d, %[1]d, %[2]d, 'user4', '{"singular":512}');
+	`,
+		s.Clock.Now().Unix(),
+		windowStart.Unix(),
+	)
+}
+
+func TestDeletePendingOperation(t *testing.T) {
+	s := test.NewSetup(t,
+		commonSetupOptionsForAPITest(),
+	)
+	commonSetupFillDB(t, s)
+	ctx := t.Context()
+	s.Validator.Auth = map[string]string{"user_id": "user4"}
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	path := "DELETE /v1/projects/project1/assets/foo/fooasset1/operations/pending"
+	failureEvent := func(reasonCode string) cadf.Event {
+		return cadf.Event{
+			Action:      "cancel/foo",
+			Outcome:     "failure",
+			Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: reasonCode},
+			RequestPath: "/v1/projects/project1/assets/foo/fooasset1/operations/pending",
+			Target: cadf.Resource{
+				TypeURI:   "data/security/project",
+				ID:        "project1",
+				ProjectID: "project1",
+			},
+		}
+	}
+
+	// endpoint requires edit access
+	s.Validator.Enforcer.Forbid("project:edit:foo")
+	s.Handler.RespondTo(ctx, path).
+		ExpectStatus(t, http.StatusForbidden)
+	s.Validator.Enforcer.Allow("project:edit:foo")
+
+	// expect error for unknown asset
+	s.Handler.RespondTo(ctx, "DELETE /v1/projects/project1/assets/foo/doesnotexist/operations/pending").
+		ExpectStatus(t, http.StatusNotFound)
+
+	// expect error for malformed snooze duration
+	s.Handler.RespondTo(ctx, path+"?snooze=1w").
+		ExpectText(t, http.StatusBadRequest, "invalid snooze: expected a value like \"30m\", \"12h\" or \"7d\"; got \"1w\"\n")
+
+	// expect error for asset without pending operation
+	s.Auditor.IgnoreEventsUntilNow()
+	s.Handler.RespondTo(ctx, path).
+		ExpectText(t, http.StatusNotFound, "no pending operation for this asset\n")
+	s.Auditor.ExpectEvents(t, failureEvent("404"))
+
+	// expect error for operation that is already greenlit
+	pendingOp := db.PendingOperation{
+		AssetID:     1,
+		Reason:      castellum.OperationReasonHigh,
+		OldSize:     1024,
+		NewSize:     1229,
+		Usage:       castellum.UsageValues{castellum.SingularUsageMetric: 512},
+		CreatedAt:   s.Clock.Now(),
+		ConfirmedAt: Some(s.Clock.Now()),
+		GreenlitAt:  Some(s.Clock.Now()),
+	}
+	must.SucceedT(t, db.PendingOperationStore.Insert(ctx, s.DB, &pendingOp))
+	tr.DBChanges().Ignore()
+	s.Handler.RespondTo(ctx, path).
+		ExpectText(t, http.StatusConflict, "cannot cancel operation that has already been greenlit\n")
+	tr.DBChanges().AssertEmpty()
+	s.Auditor.ExpectEvents(t, failureEvent("409"))
+
+	// happy path: an operation with a greenlight scheduled for the future can
+	// still be cancelled (the scheduled greenlight does not appear in the
+	// finished operation since it never took effect)
+	pendingOp.GreenlitAt = Some(s.Clock.Now().Add(time.Hour))
+	pendingOp.GreenlitByUserUUID = Some("user1")
+	must.SucceedT(t, db.PendingOperationStore.Update(ctx, s.DB, pendingOp))
+	tr.DBChanges().Ignore()
+
+	s.Clock.StepBy(time.Minute)
+	expectedOpJSON := jsonmatch.Object{
+		"project_id": "project1",
+		"asset_type": "foo",
+		"asset_id":   "fooasset1",
+		"state":      "cancelled",
+		"reason":     "high",
+		"old_size":   1024,
+		"new_size":   1229,
+		"created": jsonmatch.Object{
+			"at":            pendingOp.CreatedAt.Unix(),
+			"usage_percent": 50,
+		},
+		"confirmed": jsonmatch.Object{
+			"at": pendingOp.CreatedAt.Unix(),
+		},
+		"finished": jsonmatch.Object{
+			"at": s.Clock.Now().Unix(),
+		},
+	}
+	s.Handler.RespondTo(ctx, path).
+		ExpectJSON(t, http.StatusOK, expectedOpJSON)
+	tr.DBChanges().AssertEqualf(`
+		INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, confirmed_at, finished_at, usage, cancelled_by_user_uuid) VALUES (1, 'high', 'cancelled', 1024, 1229, %[1]d, %[1]d, %[2]d, '{"singular":512}', 'user4');
+		DELETE FROM pending_operations WHERE id = 1 AND asset_id = 1;
+	`,
+		pendingOp.CreatedAt.Unix(),
+		s.Clock.Now().Unix(),
+	)
+	s.Auditor.ExpectEvents(t, cadf.Event{
+		Action:      "cancel/foo",
+		Outcome:     "success",
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "200"},
+		RequestPath: "/v1/projects/project1/assets/foo/fooasset1/operations/pending",
+		Target: cadf.Resource{
+			TypeURI:   "data/security/project",
+			ID:        "project1",
+			ProjectID: "project1",
+			Attachments: []cadf.Attachment{{
+				Name:    "payload",
+				TypeURI: "mime:application/json",
+				Content: toJSONVia[castellum.StandaloneOperation](expectedOpJSON),
+			}},
+		},
+	})
+
+	// when cancelling with a snooze duration, the asset remembers not to
+	// recreate this kind of operation until then
+	pendingOp = db.PendingOperation{
+		AssetID:   1,
+		Reason:    castellum.OperationReasonLow,
+		OldSize:   1024,
+		NewSize:   820,
+		Usage:     castellum.UsageValues{castellum.SingularUsageMetric: 512},
+		CreatedAt: s.Clock.Now(),
+	}
+	must.SucceedT(t, db.PendingOperationStore.Insert(ctx, s.DB, &pendingOp))
+	tr.DBChanges().Ignore()
+
+	s.Handler.RespondTo(ctx, path+"?snooze=12h").
+		ExpectStatus(t, http.StatusOK)
+	tr.DBChanges().AssertEqualf(`
+		UPDATE assets SET snoozed_reason = 'low', snoozed_until = %[2]d WHERE id = 1 AND resource_id = 1 AND uuid = 'fooasset1';
+		INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, finished_at, usage, cancelled_by_user_uuid) VALUES (1, 'low', 'cancelled', 1024, 820, %[1]d, %[1]d, '{"singular":512}', 'user4');
+		DELETE FROM pending_operations WHERE id = 2 AND asset_id = 1;
+	`,
+		s.Clock.Now().Unix(),
+		s.Clock.Now().Add(12*time.Hour).Unix(),
+	)
+}
@@ -7,24 +7,48 @@ import (
 	"github.com/sapcc/go-api-declarations/cadf"
 	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/must"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
 )
 
 // EventParams contains parameters for creating an audit event.
 type scalingEventTarget struct {
-	projectID string
-	resource  *castellum.Resource // only used for enable/update action events
+	scopeType db.ScopeType
+	scopeUUID string
+	resource  *core.ResourceSpec             // only used for enable/update action events
+	operation *castellum.StandaloneOperation // only used for events concerning a single operation
+	override  *assetOverridePayload          // only used for events concerning asset overrides
+}
+
+// assetOverridePayload appears in type scalingEventTarget.
+type assetOverridePayload struct {
+	AssetUUID string                 `json:"asset_id"`
+	Config    core.AssetOverrideSpec `json:"config"`
 }
 
 // Render implements the audittools.Target interface.
 func (t scalingEventTarget) Render() cadf.Resource {
 	result := cadf.Resource{
-		TypeURI:   "data/security/project",
-		ID:        t.projectID,
-		ProjectID: t.projectID,
+		TypeURI: "data/security/" + string(t.scopeType),
+		ID:      t.scopeUUID,
+	}
+	if t.scopeType == db.DomainScope {
+		result.DomainID = t.scopeUUID
+	} else {
+		result.ProjectID = t.scopeUUID
 	}
 	if t.resource != nil {
 		attachment := must.Return(cadf.NewJSONAttachment("payload", *t.resource))
 		result.Attachments = append(result.Attachments, attachment)
 	}
+	if t.operation != nil {
+		attachment := must.Return(cadf.NewJSONAttachment("payload", *t.operation))
+		result.Attachments = append(result.Attachments, attachment)
+	}
+	if t.override != nil {
+		attachment := must.Return(cadf.NewJSONAttachment("payload", *t.override))
+		result.Attachments = append(result.Attachments, attachment)
+	}
 	return result
 }
@@ -6,14 +6,21 @@ package api
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/lib/pq"
 	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/gopherpolicy"
 	"github.com/sapcc/go-bits/httpapi"
+	"github.com/sapcc/go-bits/must"
 	"github.com/sapcc/go-bits/respondwith"
 	"github.com/sapcc/go-bits/sqlext"
 	"go.xyrillian.de/gg/gsql"
@@ -26,9 +33,9 @@ import (
 func (h handler) loadMatchingResources(w http.ResponseWriter, r *http.Request) (map[int64]db.Resource, bool) {
 	ctx := r.Context()
 
-	// CheckToken discovers project ID in both URL path and query
+	// CheckToken discovers project ID in both URL path and query (or domain ID in URL path)
 	var token *gopherpolicy.Token
-	projectUUID, token := h.CheckToken(w, r)
+	scopeUUID, token := h.CheckToken(w, r)
 	if token == nil {
 		return nil, false
 	}
@@ -63,8 +70,8 @@ func (h handler) loadMatchingResources(w http.ResponseWriter, r *http.Request) (
 		sqlConditions = append(sqlConditions, cond)
 		sqlBindValues = append(sqlBindValues, value)
 	}
-	if projectUUID != "" {
-		addSQLCondition("scope_uuid", projectUUID)
+	if scopeUUID != "" {
+		addSQLCondition("scope_uuid", scopeUUID)
 	}
 	if domainUUID != "" {
 		addSQLCondition("domain_uuid", domainUUID)
@@ -82,27 +89,27 @@ func (h handler) loadMatchingResources(w http.ResponseWriter, r *http.Request) (
 
 	// check if user has access to all these resources
 	allowedResources := make(map[int64]db.Resource)
-	canAccessAnyMatchingProject := false
+	canAccessAnyMatchingScope := false
 	for _, res := range allResources {
-		projectExists, err := h.SetTokenToProjectScope(r.Context(), token, res.ScopeUUID)
+		scopeExists, err := h.SetTokenToScope(r.Context(), token, res.ScopeType(), res.ScopeUUID)
 		if respondwith.ObfuscatedErrorText(w, err) {
 			return nil, false
 		}
-		if !projectExists || !token.Check("project:access") {
+		if !scopeExists || !token.Check(string(res.ScopeType())+":access") {
 			continue
 		}
-		canAccessAnyMatchingProject = true
-		if token.Check(res.AssetType.PolicyRuleForRead()) {
+		canAccessAnyMatchingScope = true
+		if token.Check(res.AssetType.PolicyRuleForRead(res.ScopeType())) {
 			allowedResources[res.ID] = res
 		}
 	}
 
 	// if there are no allowed resources, generate 4xx response
 	if len(allowedResources) == 0 {
-		if canAccessAnyMatchingProject {
+		if canAccessAnyMatchingScope {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 		} else {
-			// do not leak information about project/resource existence to unauthorized users
+			// do not leak information about project/domain/resource existence to unauthorized users
 			http.NotFound(w, r)
 		}
 		return nil, false
@@ -126,6 +133,7 @@ func (h handler) GetPendingOperations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	now := h.TimeNow()
 	allOps := []castellum.StandaloneOperation{}
 	for _, dbResource := range dbResources {
 		// find asset UUIDs
@@ -137,7 +145,7 @@ func (h handler) GetPendingOperations(w http.ResponseWriter, r *http.Request) {
 		// find operations
 		err = db.PendingOperationStore.Select(ctx, h.DB, getPendingOpsByResourceIDQuery, dbResource.ID).
 			Foreach(func(op db.PendingOperation) error {
-				allOps = append(allOps, PendingOperationFromDB(op, assetUUIDs[op.AssetID], &dbResource))
+				allOps = append(allOps, PendingOperationFromDB(op, assetUUIDs[op.AssetID], &dbResource, now))
 				return nil
 			})
 		if respondwith.ObfuscatedErrorText(w, err) {
@@ -176,9 +184,10 @@ func (h handler) GetRecentlyFailedOperations(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	cfg := h.Config.Current()
 	relevantOps := []castellum.StandaloneOperation{}
 	for _, dbResource := range dbResources {
-		_, info := h.Team.ForAssetType(dbResource.AssetType)
+		_, info := h.Team.ForResource(dbResource)
 
 		failedOpsByAssetID, err := recentOperationQuery{
 			DB:           h.DB,
@@ -190,6 +199,11 @@ func (h handler) GetRecentlyFailedOperations(w http.ResponseWriter, r *http.Requ
 			return
 		}
 
+		overrides, err := h.loadAssetOverrides(ctx, dbResource)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			return
+		}
+
 		// check if the assets in question are still eligible for resizing
 		err = db.AssetStore.SelectWhere(ctx, h.DB, `resource_id = $1 ORDER BY uuid`, dbResource.ID).
 			Foreach(func(asset db.Asset) error {
@@ -197,7 +211,9 @@ func (h handler) GetRecentlyFailedOperations(w http.ResponseWriter, r *http.Requ
 				if !exists {
 					return nil
 				}
-				if _, exists := core.GetEligibleOperations(core.LogicOfResource(dbResource, info), core.StatusOfAsset(asset, h.Config, dbResource))[op.Reason]; exists {
+				// (failed manual and repair operations are always relevant since they are not tied to a threshold)
+				_, exists = core.GetEligibleOperations(core.LogicOfResource(dbResource, info, overrideFor(overrides, asset.ID)), core.StatusOfAsset(asset, cfg, dbResource))[op.Reason]
+				if exists || op.Reason == db.OperationReasonManual || op.Reason == db.OperationReasonRepair {
 					relevantOps = append(relevantOps, FinishedOperationFromDB(op, asset.UUID, &dbResource))
 				}
 				return nil
@@ -298,3 +314,187 @@ func (q recentOperationQuery) execute(ctx context.Context) (map[int64]db.Finishe
 
 	return finishedOpByAssetIDIndex.IndexFrom(db.FinishedOperationStore.Select(ctx, q.DB, queryStr, q.ResourceID))
 }
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// historyCursor identifies the last finished operation on a page of
+// GET /v1/operations/history. Since finished_operations does not have a
+// primary key, operations are ordered by a combination of columns that is
+// unique in practice.
+type historyCursor struct {
+	FinishedAtMicros int64 `json:"f"`
+	AssetID          int64 `json:"a"`
+	CreatedAtMicros  int64 `json:"c"`
+}
+
+func historyCursorOf(op db.FinishedOperation) string {
+	buf := must.Return(json.Marshal(historyCursor{
+		FinishedAtMicros: op.FinishedAt.UnixMicro(),
+		AssetID:          op.AssetID,
+		CreatedAtMicros:  op.CreatedAt.UnixMicro(),
+	}))
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func parseHistoryCursor(input string) (historyCursor, error) {
+	var c historyCursor
+	buf, err := base64.RawURLEncoding.DecodeString(input)
+	if err == nil {
+		err = json.Unmarshal(buf, &c)
+	}
+	if err != nil {
+		return historyCursor{}, fmt.Errorf("invalid cursor: %q", input)
+	}
+	return c, nil
+}
+
+// GetOperationHistory handles GET /v1/operations/history.
+func (h handler) GetOperationHistory(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/operations/history")
+	ctx := r.Context()
+	dbResources, ok := h.loadMatchingResources(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	var (
+		sqlConditions []string
+		sqlBindValues []any
+	)
+	addSQLCondition := func(condition string, values ...any) {
+		// `condition` contains "%d" placeholders for the positions of the bind values
+		positions := make([]any, len(values))
+		for idx := range values {
+			positions[idx] = len(sqlBindValues) + idx + 1
+		}
+		sqlConditions = append(sqlConditions, fmt.Sprintf(condition, positions...))
+		sqlBindValues = append(sqlBindValues, values...)
+	}
+
+	resourceIDs := make([]int64, 0, len(dbResources))
+	for id := range dbResources {
+		resourceIDs = append(resourceIDs, id)
+	}
+	addSQLCondition("a.resource_id = ANY($%d)", pq.Array(resourceIDs))
+	// (markers for resolved errors are not operations)
+	sqlConditions = append(sqlConditions, "o.outcome != 'error-resolved'")
+
+	if assetUUID := query.Get("asset"); assetUUID != "" {
+		addSQLCondition("a.uuid = $%d", assetUUID)
+	}
+	if reason := query.Get("reason"); reason != "" {
+		if !slices.Contains(historyReasons, castellum.OperationReason(reason)) {
+			http.Error(w, fmt.Sprintf("invalid reason: %q", reason), http.StatusBadRequest)
+			return
+		}
+		addSQLCondition("o.reason = $%d", reason)
+	}
+	if outcome := query.Get("outcome"); outcome != "" {
+		if !slices.Contains(historyOutcomes, castellum.OperationOutcome(outcome)) {
+			http.Error(w, fmt.Sprintf("invalid outcome: %q", outcome), http.StatusBadRequest)
+			return
+		}
+		addSQLCondition("o.outcome = $%d", outcome)
+	}
+	for _, param := range []struct {
+		Key       string
+		Condition string
+	}{
+		{"since", "o.finished_at >= $%d"},
+		{"until", "o.finished_at < $%d"},
+	} {
+		value := query.Get(param.Key)
+		if value == "" {
+			continue
+		}
+		timestamp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid %s: expected a UNIX timestamp, got %q", param.Key, value), http.StatusBadRequest)
+			return
+		}
+		addSQLCondition(param.Condition, time.Unix(timestamp, 0).UTC())
+	}
+	if cursorStr := query.Get("cursor"); cursorStr != "" {
+		cursor, err := parseHistoryCursor(cursorStr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		addSQLCondition("(o.finished_at, o.asset_id, o.created_at) < ($%d, $%d, $%d)",
+			time.UnixMicro(cursor.FinishedAtMicros).UTC(), cursor.AssetID, time.UnixMicro(cursor.CreatedAtMicros).UTC())
+	}
+
+	limit := defaultHistoryLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxHistoryLimit {
+			http.Error(w, fmt.Sprintf("invalid limit: expected a number between 1 and %d, got %q", maxHistoryLimit, limitStr), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// find operations (we ask for one more than `limit` to know whether there is a next page)
+	queryStr := fmt.Sprintf(getOperationHistoryQuery, strings.Join(sqlConditions, " AND "), limit+1)
+	dbOps, err := db.FinishedOperationStore.Select(ctx, h.DB, queryStr, sqlBindValues...).Collect()
+	if respondwith.ObfuscatedErrorText(w, err) {
+		return
+	}
+	var nextCursor string
+	if len(dbOps) > limit {
+		dbOps = dbOps[:limit]
+		nextCursor = historyCursorOf(dbOps[limit-1])
+	}
+
+	// find assets for these operations
+	assetIDs := make([]int64, len(dbOps))
+	for idx, op := range dbOps {
+		assetIDs[idx] = op.AssetID
+	}
+	assetsByID, err := assetByIDIndex.IndexFrom(db.AssetStore.SelectWhere(ctx, h.DB, `id = ANY($1)`, pq.Array(assetIDs)))
+	if respondwith.ObfuscatedErrorText(w, err) {
+		return
+	}
+
+	ops := make([]castellum.StandaloneOperation, len(dbOps))
+	for idx, op := range dbOps {
+		asset := assetsByID[op.AssetID]
+		res := dbResources[asset.ResourceID]
+		ops[idx] = FinishedOperationFromDB(op, asset.UUID, &res)
+	}
+
+	respondwith.JSON(w, http.StatusOK, struct {
+		Operations []castellum.StandaloneOperation `json:"operations"`
+		NextCursor string                          `json:"next_cursor,omitempty"`
+	}{ops, nextCursor})
+}
+
+var (
+	historyReasons = []castellum.OperationReason{
+		castellum.OperationReasonLow,
+		castellum.OperationReasonHigh,
+		castellum.OperationReasonCritical,
+		db.OperationReasonManual,
+		db.OperationReasonRepair,
+	}
+	historyOutcomes = []castellum.OperationOutcome{
+		castellum.OperationOutcomeSucceeded,
+		castellum.OperationOutcomeFailed,
+		castellum.OperationOutcomeErrored,
+		castellum.OperationOutcomeCancelled,
+	}
+)
+
+var getOperationHistoryQuery = sqlext.SimplifyWhitespace(`
+	SELECT o.* FROM finished_operations o
+	  JOIN assets a ON a.id = o.asset_id
+	 WHERE %s
+	 ORDER BY o.finished_at DESC, o.asset_id DESC, o.created_at DESC
+	 LIMIT %d
+`)
+
+var assetByIDIndex = oblast.NewRuntimeIndex(func(asset db.Asset) int64 { return asset.ID })
@@ -294,3 +294,102 @@ func TestGetRecentlySucceededOperationsForResource(t *testing.T) {
 			ExpectJSON(t, http.StatusOK, jsonmatch.Object{"recently_succeeded_operations": jsonmatch.Array{}})
 	})
 }
+
+func TestGetOperationHistory(t *testing.T) {
+	s := test.NewSetup(t,
+		commonSetupOptionsForAPITest(),
+	)
+	commonSetupFillDB(t, s)
+	ctx := t.Context()
+
+	// the history lists all finished operations (except for resolved errors), newest first
+	criticalOpJSON := jsonmatch.Object{
+		"project_id": "project1",
+		"asset_type": "foo",
+		"asset_id":   "fooasset1",
+		"reason":     "critical",
+		"state":      "errored",
+		"old_size":   1024,
+		"new_size":   1025,
+		"created":    jsonmatch.Object{"at": 51, "usage_percent": 96},
+		"confirmed":  jsonmatch.Object{"at": 52},
+		"greenlit":   jsonmatch.Object{"at": 52},
+		"finished":   jsonmatch.Object{"at": 53, "error": "datacenter is on fire"},
+	}
+	highOpJSON := jsonmatch.Object{
+		"project_id": "project1",
+		"asset_type": "foo",
+		"asset_id":   "fooasset1",
+		"reason":     "high",
+		"state":      "succeeded",
+		"old_size":   1023,
+		"new_size":   1024,
+		"created":    jsonmatch.Object{"at": 41, "usage_percent": 80},
+		"confirmed":  jsonmatch.Object{"at": 42},
+		"greenlit":   jsonmatch.Object{"at": 43, "by_user": "user2"},
+		"finished":   jsonmatch.Object{"at": 44},
+	}
+	lowOpJSON := jsonmatch.Object{
+		"project_id": "project1",
+		"asset_type": "foo",
+		"asset_id":   "fooasset1",
+		"reason":     "low",
+		"state":      "cancelled",
+		"old_size":   1000,
+		"new_size":   900,
+		"created":    jsonmatch.Object{"at": 31, "usage_percent": 20},
+		"finished":   jsonmatch.Object{"at": 32},
+	}
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=domain1").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{
+			"operations": jsonmatch.Array{criticalOpJSON, highOpJSON, lowOpJSON},
+		})
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?project=project1&asset-type=foo").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{
+			"operations": jsonmatch.Array{criticalOpJSON, highOpJSON, lowOpJSON},
+		})
+
+	// check filters
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=domain1&asset=fooasset1&reason=high").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"operations": jsonmatch.Array{highOpJSON}})
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=domain1&outcome=cancelled").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"operations": jsonmatch.Array{lowOpJSON}})
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=domain1&since=40&until=50").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"operations": jsonmatch.Array{highOpJSON}})
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=domain1&asset=barasset1").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"operations": jsonmatch.Array{}})
+
+	// check pagination
+	var page struct {
+		NextCursor string `json:"next_cursor"`
+	}
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=domain1&limit=2").
+		CaptureJSON(&page).
+		ExpectStatus(t, http.StatusOK)
+	if page.NextCursor == "" {
+		t.Fatal("expected next_cursor on first page, but got none")
+	}
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=domain1&limit=2&cursor="+page.NextCursor).
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"operations": jsonmatch.Array{lowOpJSON}})
+
+	// check invalid arguments
+	for _, query := range []string{
+		"reason=unknown",
+		"reason=error-resolved",
+		"outcome=error-resolved",
+		"since=yesterday",
+		"until=-",
+		"cursor=foo",
+		"limit=0",
+		"limit=1001",
+	} {
+		s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=domain1&"+query).
+			ExpectStatus(t, http.StatusBadRequest)
+	}
+
+	// check queries with URL arguments where nothing matches
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=unknown").
+		ExpectStatus(t, http.StatusNotFound)
+	s.Handler.RespondTo(ctx, "GET /v1/operations/history?domain=domain1&asset-type=unknown").
+		ExpectStatus(t, http.StatusNotFound)
+}
@@ -6,13 +6,14 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/sapcc/go-api-declarations/cadf"
 	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/audittools"
-	"github.com/sapcc/go-bits/httpapi"
+	"github.com/sapcc/go-bits/logg"
 	"github.com/sapcc/go-bits/respondwith"
 	"github.com/sapcc/go-bits/sqlext"
 	. "go.xyrillian.de/gg/option"
@@ -24,18 +25,18 @@ import (
 ////////////////////////////////////////////////////////////////////////////////
 // conversion and validation methods
 
-// ResourceFromDB converts a db.Resource into an castellum.Resource.
-func (h handler) ResourceFromDB(res db.Resource) (castellum.Resource, error) {
+// ResourceFromDB converts a db.Resource into a core.ResourceSpec.
+func (h handler) ResourceFromDB(res db.Resource) (core.ResourceSpec, error) {
 	var assetCount int64
 	err := h.DB.QueryRow(`SELECT COUNT(*) FROM assets WHERE resource_id = $1`, res.ID).Scan(&assetCount)
 	if err != nil {
-		return castellum.Resource{}, err
+		return core.ResourceSpec{}, err
 	}
 
-	result := castellum.Resource{
+	result := core.ResourceSpec{Resource: castellum.Resource{
 		AssetCount: assetCount,
 		SizeSteps:  castellum.SizeSteps{Percent: res.SizeStepPercent, Single: res.SingleStep},
-	}
+	}}
 	if res.ConfigJSON != "" {
 		result.ConfigJSON = Some(json.RawMessage(res.ConfigJSON))
 	}
@@ -69,17 +70,45 @@ func (h handler) ResourceFromDB(res db.Resource) (castellum.Resource, error) {
 			MinimumFreeIsCritical: res.MinimumFreeIsCritical,
 		})
 	}
+	if res.LowRequiresApproval || res.HighRequiresApproval || res.CriticalRequiresApproval {
+		result.ApprovalRequired = Some(core.ApprovalRequirements{
+			Low:      res.LowRequiresApproval,
+			High:     res.HighRequiresApproval,
+			Critical: res.CriticalRequiresApproval,
+		})
+	}
+	// a broken maintenance window shall not make the entire resource unreadable
+	// (the observer ignores it as well, see core.GreenlightTimeOf)
+	result.MaintenanceWindow, err = core.MaintenanceWindowOf(res)
+	if err != nil {
+		logg.Error(err.Error())
+	}
+	result.AssetExclusions, err = core.AssetExclusionsOf(res)
+	if err != nil {
+		logg.Error(err.Error())
+	}
+	if res.PredictiveHorizonSeconds > 0 {
+		result.PredictiveScaling = Some(core.PredictiveScaling{HorizonSeconds: res.PredictiveHorizonSeconds})
+	}
+	if core.HasCooldown(res) {
+		result.Cooldown = Some(core.Cooldown{
+			LowSeconds:            res.LowCooldownSeconds,
+			HighSeconds:           res.HighCooldownSeconds,
+			CriticalSeconds:       res.CriticalCooldownSeconds,
+			OppositeDirectionOnly: res.CooldownOppositeDirectionOnly,
+		})
+	}
 	return result, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // HTTP handlers
 
-// GetProject handles GET /v1/projects/:id.
+// GetProject handles GET /v1/(projects|domains)/:id.
 func (h handler) GetProject(w http.ResponseWriter, r *http.Request) {
-	httpapi.IdentifyEndpoint(r, "/v1/projects/:id")
+	identifyScopedEndpoint(r, "")
 	ctx := r.Context()
-	projectUUID, token := h.CheckToken(w, r)
+	scopeUUID, token := h.CheckToken(w, r)
 	if token == nil {
 		return
 	}
@@ -87,16 +116,16 @@ func (h handler) GetProject(w http.ResponseWriter, r *http.Request) {
 	// show only those resources where there is a corresponding asset manager, and
 	// where the user has permission to see the resource
 	var result struct {
-		Resources map[db.AssetType]castellum.Resource `json:"resources"`
+		Resources map[db.AssetType]core.ResourceSpec `json:"resources"`
 	}
-	result.Resources = make(map[db.AssetType]castellum.Resource)
-	err := db.ResourceStore.SelectWhere(ctx, h.DB, `scope_uuid = $1 ORDER BY asset_type`, projectUUID).
+	result.Resources = make(map[db.AssetType]core.ResourceSpec)
+	err := db.ResourceStore.SelectWhere(ctx, h.DB, `scope_uuid = $1 ORDER BY asset_type`, scopeUUID).
 		Foreach(func(res db.Resource) error {
 			manager, _ := h.Team.ForAssetType(res.AssetType)
 			if manager == nil {
 				return nil
 			}
-			if token.Check(res.AssetType.PolicyRuleForRead()) {
+			if token.Check(res.AssetType.PolicyRuleForRead(res.ScopeType())) {
 				var err error
 				result.Resources[res.AssetType], err = h.ResourceFromDB(res)
 				if err != nil {
@@ -112,14 +141,14 @@ func (h handler) GetProject(w http.ResponseWriter, r *http.Request) {
 	respondwith.JSON(w, http.StatusOK, result)
 }
 
-// GetResource handles GET /v1/projects/:id/resources/:type.
+// GetResource handles GET /v1/(projects|domains)/:id/resources/:type.
 func (h handler) GetResource(w http.ResponseWriter, r *http.Request) {
-	httpapi.IdentifyEndpoint(r, "/v1/projects/:id/resources/:type")
-	projectUUID, token := h.CheckToken(w, r)
+	identifyScopedEndpoint(r, "/resources/:type")
+	scopeUUID, token := h.CheckToken(w, r)
 	if token == nil {
 		return
 	}
-	dbResource := h.LoadResource(w, r, projectUUID, token, false)
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
 	if dbResource == nil {
 		return
 	}
@@ -131,27 +160,27 @@ func (h handler) GetResource(w http.ResponseWriter, r *http.Request) {
 	respondwith.JSON(w, http.StatusOK, resource)
 }
 
-// PutResource handles PUT /v1/projects/:id/resources/:type.
+// PutResource handles PUT /v1/(projects|domains)/:id/resources/:type.
 func (h handler) PutResource(w http.ResponseWriter, r *http.Request) {
-	httpapi.IdentifyEndpoint(r, "/v1/projects/:id/resources/:type")
+	identifyScopedEndpoint(r, "/resources/:type")
 	ctx := r.Context()
 	requestTime := time.Now()
-	projectUUID, token := h.CheckToken(w, r)
+	scopeUUID, token := h.CheckToken(w, r)
 	if token == nil {
 		return
 	}
-	dbResource := h.LoadResource(w, r, projectUUID, token, true)
+	dbResource := h.LoadResource(w, r, scopeUUID, token, true)
 	if dbResource == nil {
 		return
 	}
-	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite()) {
+	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite(dbResource.ScopeType())) {
 		return
 	}
 	if h.rejectIfResourceSeeded(w, r, *dbResource) {
 		return
 	}
 
-	var input castellum.Resource
+	var input core.ResourceSpec
 	if !RequireJSON(w, r, &input) {
 		return
 	}
@@ -169,30 +198,20 @@ func (h handler) PutResource(w http.ResponseWriter, r *http.Request) {
 			ReasonCode: statusCode,
 			Action:     cadf.Action(string(action) + "/" + string(dbResource.AssetType)),
 			Target: scalingEventTarget{
-				projectID: projectUUID,
+				scopeType: dbResource.ScopeType(),
+				scopeUUID: scopeUUID,
 				resource:  &input,
 			},
 		})
 	}
 
-	existingResources := make(map[db.AssetType]struct{})
-	err := sqlext.ForeachRow(h.DB,
-		`SELECT asset_type FROM resources WHERE scope_uuid = $1`, []any{projectUUID},
-		func(rows *sql.Rows) error {
-			var assetType db.AssetType
-			err := rows.Scan(&assetType)
-			if err == nil {
-				existingResources[assetType] = struct{}{}
-			}
-			return err
-		},
-	)
+	existingResources, err := h.getExistingResourceTypes(scopeUUID)
 	if respondwith.ObfuscatedErrorText(w, err) {
 		doAudit(http.StatusInternalServerError)
 		return
 	}
 
-	errs := core.ApplyResourceSpecInto(r.Context(), dbResource, input, existingResources, h.Config, h.Team)
+	errs := core.ApplyResourceSpecInto(r.Context(), dbResource, input, existingResources, h.Config.Current(), h.Team)
 	if len(errs) > 0 {
 		doAudit(http.StatusUnprocessableEntity)
 		http.Error(w, errs.Join("\n"), http.StatusUnprocessableEntity)
@@ -212,20 +231,135 @@ func (h handler) PutResource(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
-// DeleteResource handles DELETE /v1/projects/:id/resources/:type.
+type simulatedAsset struct {
+	UUID              string                     `json:"id"`
+	Size              uint64                     `json:"size"`
+	UsagePercent      castellum.UsageValues      `json:"usage_percent"`
+	Operation         Option[simulatedOperation] `json:"operation,omitzero"`
+	NoOperationReason string                     `json:"no_operation_reason,omitempty"`
+}
+
+type simulatedOperation struct {
+	Reason  castellum.OperationReason `json:"reason"`
+	NewSize uint64                    `json:"new_size"`
+}
+
+// PostResourceSimulation handles POST /v1/(projects|domains)/:id/resources/:type/simulate.
+func (h handler) PostResourceSimulation(w http.ResponseWriter, r *http.Request) {
+	identifyScopedEndpoint(r, "/resources/:type/simulate")
+	ctx := r.Context()
+	scopeUUID, token := h.CheckToken(w, r)
+	if token == nil {
+		return
+	}
+	dbResource := h.LoadResource(w, r, scopeUUID, token, true)
+	if dbResource == nil {
+		return
+	}
+	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite(dbResource.ScopeType())) {
+		return
+	}
+
+	var input core.ResourceSpec
+	if !RequireJSON(w, r, &input) {
+		return
+	}
+
+	existingResources, err := h.getExistingResourceTypes(scopeUUID)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		return
+	}
+	errs := core.ApplyResourceSpecInto(ctx, dbResource, input, existingResources, h.Config.Current(), h.Team)
+	if len(errs) > 0 {
+		http.Error(w, errs.Join("\n"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// NOTE: From here on, `dbResource` contains the simulated configuration and must not be written into the DB.
+	_, info := h.Team.ForResource(*dbResource)
+	overrides, err := h.loadAssetOverrides(ctx, *dbResource)
+	if respondwith.ObfuscatedErrorText(w, err) {
+		return
+	}
+	var usageGrowth map[int64]castellum.UsageValues
+	if dbResource.PredictiveHorizonSeconds > 0 {
+		usageGrowth, err = h.loadUsageGrowth(ctx, *dbResource, info)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			return
+		}
+	}
+	var lastResizes map[int64]db.FinishedOperation
+	if core.HasCooldown(*dbResource) {
+		lastResizes, err = h.loadLastResizes(ctx, *dbResource)
+		if respondwith.ObfuscatedErrorText(w, err) {
+			return
+		}
+	}
+	now := h.TimeNow()
+	cfg := h.Config.Current()
+
+	assets := []simulatedAsset{}
+	err = db.AssetStore.SelectWhere(ctx, h.DB, `resource_id = $1 ORDER BY uuid`, dbResource.ID).Foreach(func(dbAsset db.Asset) error {
+		asset := simulatedAsset{
+			UUID:         dbAsset.UUID,
+			Size:         dbAsset.Size,
+			UsagePercent: core.GetMultiUsagePercent(dbAsset.Size, dbAsset.Usage),
+		}
+		switch {
+		case dbAsset.NeverScraped:
+			asset.NoOperationReason = "asset has not been scraped yet"
+		case dbAsset.ExpectedSize.IsSome():
+			asset.NoOperationReason = "asset is currently being resized"
+		case dbAsset.ExclusionReason != "":
+			asset.NoOperationReason = "asset is " + dbAsset.ExclusionReason
+		case overrides[dbAsset.ID].Disabled:
+			asset.NoOperationReason = "autoscaling is disabled for this asset"
+		default:
+			logic := core.LogicOfResource(*dbResource, info, overrideFor(overrides, dbAsset.ID))
+			status := core.StatusOfAsset(dbAsset, cfg, *dbResource)
+			status.UsageGrowthPerSecond = usageGrowth[dbAsset.ID]
+			eligibleFor := core.GetEligibleOperations(logic, status)
+			reason, newSize, ok := core.SelectOperation(eligibleFor)
+			switch {
+			case !ok:
+				asset.NoOperationReason = "no threshold is crossed"
+			case newSize == dbAsset.Size:
+				asset.NoOperationReason = fmt.Sprintf("%s threshold is crossed, but size would not change", reason)
+			case dbAsset.IsSnoozedFor(reason, now):
+				asset.NoOperationReason = fmt.Sprintf("%s operations are snoozed until %s", reason, dbAsset.SnoozedUntil.UnwrapOr(now).Format(time.RFC3339))
+			case core.IsInCooldownFor(*dbResource, reason, lastResizeFor(lastResizes, dbAsset.ID), now):
+				cooldownEnd := core.CooldownEndOf(*dbResource, reason, lastResizes[dbAsset.ID]).UnwrapOr(now)
+				asset.NoOperationReason = fmt.Sprintf("%s operations are in cooldown until %s", reason, cooldownEnd.Format(time.RFC3339))
+			default:
+				asset.Operation = Some(simulatedOperation{Reason: reason, NewSize: newSize})
+			}
+		}
+		assets = append(assets, asset)
+		return nil
+	})
+	if respondwith.ObfuscatedErrorText(w, err) {
+		return
+	}
+
+	respondwith.JSON(w, http.StatusOK, struct {
+		Assets []simulatedAsset `json:"assets"`
+	}{assets})
+}
+
+// DeleteResource handles DELETE /v1/(projects|domains)/:id/resources/:type.
 func (h handler) DeleteResource(w http.ResponseWriter, r *http.Request) {
-	httpapi.IdentifyEndpoint(r, "/v1/projects/:id/resources/:type")
+	identifyScopedEndpoint(r, "/resources/:type")
 	ctx := r.Context()
 	requestTime := time.Now()
-	projectUUID, token := h.CheckToken(w, r)
+	scopeUUID, token := h.CheckToken(w, r)
 	if token == nil {
 		return
 	}
-	dbResource := h.LoadResource(w, r, projectUUID, token, false)
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
 	if dbResource == nil {
 		return
 	}
-	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite()) {
+	if !token.Require(w, dbResource.AssetType.PolicyRuleForWrite(dbResource.ScopeType())) {
 		return
 	}
 	if h.rejectIfResourceSeeded(w, r, *dbResource) {
@@ -241,7 +375,8 @@ func (h handler) DeleteResource(w http.ResponseWriter, r *http.Request) {
 			ReasonCode: statusCode,
 			Action:     cadf.Action("disable/" + string(dbResource.AssetType)),
 			Target: scalingEventTarget{
-				projectID: projectUUID,
+				scopeType: dbResource.ScopeType(),
+				scopeUUID: scopeUUID,
 			},
 		})
 	}
@@ -255,3 +390,20 @@ func (h handler) DeleteResource(w http.ResponseWriter, r *http.Request) {
 	doAudit(http.StatusNoContent)
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// getExistingResourceTypes returns the asset types of all resources that exist in the given project or domain.
+func (h handler) getExistingResourceTypes(scopeUUID string) (map[db.AssetType]struct{}, error) {
+	existingResources := make(map[db.AssetType]struct{})
+	err := sqlext.ForeachRow(h.DB,
+		`SELECT asset_type FROM resources WHERE scope_uuid = $1`, []any{scopeUUID},
+		func(rows *sql.Rows) error {
+			var assetType db.AssetType
+			err := rows.Scan(&assetType)
+			if err == nil {
+				existingResources[assetType] = struct{}{}
+			}
+			return err
+		},
+	)
+	return existingResources, err
+}
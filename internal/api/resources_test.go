@@ -8,11 +8,13 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sapcc/go-api-declarations/cadf"
 	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/easypg"
 	"github.com/sapcc/go-bits/httptest"
+	"github.com/sapcc/go-bits/must"
 	"go.xyrillian.de/gg/assert"
 	"go.xyrillian.de/gg/jsonmatch"
 	. "go.xyrillian.de/gg/option"
@@ -136,6 +138,15 @@ func TestGetResource(t *testing.T) {
 		ExpectJSON(t, http.StatusOK, initialFooResourceJSON)
 	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/resources/bar").
 		ExpectJSON(t, http.StatusOK, initialBarResourceJSON)
+
+	// a maintenance window that does not pass validation (anymore) is not shown,
+	// but does not break the entire response either
+	must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = $1 WHERE id = 1`, `{"start":"25:00","end":"04:00"}`))
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/resources/foo").
+		ExpectJSON(t, http.StatusOK, initialFooResourceJSON)
+	must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = $1 WHERE id = 1`, `{"start":`))
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/resources/foo").
+		ExpectJSON(t, http.StatusOK, initialFooResourceJSON)
 }
 
 func TestPutResource(t *testing.T) {
@@ -291,6 +302,37 @@ func TestPutResource(t *testing.T) {
 	tr.DBChanges().AssertEqualf(`
 		UPDATE resources SET low_threshold_percent = '{"singular":0}', low_delay_seconds = 0, high_threshold_percent = '{"singular":0}', high_delay_seconds = 0, critical_threshold_percent = '{"singular":98}', size_step_percent = 15, max_size = 42000, min_free_size = 200, single_step = FALSE, min_free_is_critical = TRUE WHERE id = 1 AND scope_uuid = 'project1' AND asset_type = 'foo';
 	`)
+
+	// test setting exclusions
+	newFooResourceJSON3["exclusions"] = jsonmatch.Object{
+		"asset_ids": jsonmatch.Array{"fooasset1"},
+		"metadata":  jsonmatch.Object{"castellum": "off"},
+	}
+	s.Handler.RespondTo(ctx, "PUT /v1/projects/project1/resources/foo",
+		httptest.WithJSONBody(newFooResourceJSON3),
+	).ExpectStatus(t, http.StatusAccepted)
+	tr.DBChanges().AssertEqualf(`
+		UPDATE resources SET exclusions_json = '{"asset_ids":["fooasset1"],"metadata":{"castellum":"off"}}' WHERE id = 1 AND scope_uuid = 'project1' AND asset_type = 'foo';
+	`)
+
+	// test setting a cooldown
+	newFooResourceJSON3["cooldown"] = jsonmatch.Object{
+		"low_seconds":             3600,
+		"opposite_direction_only": true,
+	}
+	s.Handler.RespondTo(ctx, "PUT /v1/projects/project1/resources/foo",
+		httptest.WithJSONBody(newFooResourceJSON3),
+	).ExpectStatus(t, http.StatusAccepted)
+	tr.DBChanges().AssertEqualf(`
+		UPDATE resources SET low_cooldown_seconds = 3600, cooldown_opposite_direction_only = TRUE WHERE id = 1 AND scope_uuid = 'project1' AND asset_type = 'foo';
+	`)
+
+	// invalid regexes are rejected
+	newFooResourceJSON3["exclusions"] = jsonmatch.Object{"names": jsonmatch.Array{"("}}
+	s.Handler.RespondTo(ctx, "PUT /v1/projects/project1/resources/foo",
+		httptest.WithJSONBody(newFooResourceJSON3),
+	).ExpectStatus(t, http.StatusUnprocessableEntity)
+	tr.DBChanges().AssertEmpty()
 }
 
 func toJSONVia[T any](in any) string {
@@ -527,10 +569,166 @@ func TestPutResourceValidationErrors(t *testing.T) {
 		"threshold for minimum free space must be configured",
 	)
 
+	expectErrors("foo",
+		jsonmatch.Object{
+			"critical_threshold": jsonmatch.Object{"usage_percent": 95},
+			"size_steps":         jsonmatch.Object{"percent": 10},
+			"size_constraints":   jsonmatch.Object{"maximum": 30},
+			"cooldown":           jsonmatch.Object{"opposite_direction_only": true},
+		},
+		"cooldown must be greater than 0 seconds for at least one reason",
+	)
+
 	// none of this should have touched the DB
 	tr.DBChanges().AssertEmpty()
 }
 
+func TestPostResourceSimulation(t *testing.T) {
+	s := test.NewSetup(t,
+		commonSetupOptionsForAPITest(),
+	)
+	commonSetupFillDB(t, s)
+	ctx := t.Context()
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// like `initialFooResourceJSON`, but without the read-only fields
+	fooResourceJSON := jsonmatch.Object{
+		"low_threshold": jsonmatch.Object{
+			"usage_percent": 20,
+			"delay_seconds": 3600,
+		},
+		"high_threshold": jsonmatch.Object{
+			"usage_percent": 80,
+			"delay_seconds": 1800,
+		},
+		"size_steps": jsonmatch.Object{
+			"percent": 20,
+		},
+	}
+
+	// endpoint requires a token with project access
+	s.Validator.Enforcer.Forbid("project:access")
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/resources/foo/simulate",
+		httptest.WithJSONBody(fooResourceJSON),
+	).ExpectStatus(t, http.StatusForbidden)
+	s.Validator.Enforcer.Allow("project:access")
+
+	// expect error for unknown resource
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/resources/doesnotexist/simulate",
+		httptest.WithJSONBody(fooResourceJSON),
+	).ExpectStatus(t, http.StatusNotFound)
+
+	// simulating a configuration requires the same permissions as applying it
+	s.Validator.Enforcer.Forbid("project:edit:foo")
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/resources/foo/simulate",
+		httptest.WithJSONBody(fooResourceJSON),
+	).ExpectStatus(t, http.StatusForbidden)
+	s.Validator.Enforcer.Allow("project:edit:foo")
+
+	// the configuration is validated just like in PUT
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/resources/foo/simulate",
+		httptest.WithJSONBody(jsonmatch.Object{}),
+	).ExpectText(t, http.StatusUnprocessableEntity, "at least one threshold must be configured\nsize step must be greater than 0%\n")
+
+	// happy path: with the existing configuration, fooasset2 is on the high threshold
+	// (fooasset1 is still being resized, so nothing would happen to it)
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/resources/foo/simulate",
+		httptest.WithJSONBody(fooResourceJSON),
+	).ExpectJSON(t, http.StatusOK, jsonmatch.Object{"assets": []jsonmatch.Object{
+		{
+			"id":                  "fooasset1",
+			"size":                1024,
+			"usage_percent":       50,
+			"no_operation_reason": "asset is currently being resized",
+		},
+		{
+			"id":            "fooasset2",
+			"size":          512,
+			"usage_percent": 80,
+			"operation": jsonmatch.Object{
+				"reason":   "high",
+				"new_size": 614,
+			},
+		},
+	}})
+
+	// with a different configuration, different operations would be created
+	fooResourceJSON["high_threshold"] = jsonmatch.Object{"usage_percent": 70, "delay_seconds": 1800}
+	fooResourceJSON["critical_threshold"] = jsonmatch.Object{"usage_percent": 75}
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/resources/foo/simulate",
+		httptest.WithJSONBody(fooResourceJSON),
+	).ExpectJSON(t, http.StatusOK, jsonmatch.Object{"assets": []jsonmatch.Object{
+		{
+			"id":                  "fooasset1",
+			"size":                1024,
+			"usage_percent":       50,
+			"no_operation_reason": "asset is currently being resized",
+		},
+		{
+			"id":            "fooasset2",
+			"size":          512,
+			"usage_percent": 80,
+			"operation": jsonmatch.Object{
+				"reason":   "critical",
+				"new_size": 614,
+			},
+		},
+	}})
+
+	// simulations do not touch the DB
+	tr.DBChanges().AssertEmpty()
+
+	// snoozed operations would not be created
+	must.SucceedT(t, s.DBExec(`UPDATE assets SET expected_size = NULL WHERE id = 1`))
+	must.SucceedT(t, s.DBExec(`UPDATE assets SET snoozed_reason = 'critical', snoozed_until = $1 WHERE id = 2`, s.Clock.Now().Add(time.Hour)))
+	tr.DBChanges().Ignore()
+	delete(fooResourceJSON, "critical_threshold")
+	fooResourceJSON["high_threshold"] = jsonmatch.Object{"usage_percent": 90, "delay_seconds": 1800}
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/resources/foo/simulate",
+		httptest.WithJSONBody(fooResourceJSON),
+	).ExpectJSON(t, http.StatusOK, jsonmatch.Object{"assets": []jsonmatch.Object{
+		{
+			"id":                  "fooasset1",
+			"size":                1024,
+			"usage_percent":       50,
+			"no_operation_reason": "no threshold is crossed",
+		},
+		{
+			"id":                  "fooasset2",
+			"size":                512,
+			"usage_percent":       80,
+			"no_operation_reason": "no threshold is crossed",
+		},
+	}})
+	fooResourceJSON["high_threshold"] = jsonmatch.Object{"usage_percent": 70, "delay_seconds": 1800}
+	fooResourceJSON["critical_threshold"] = jsonmatch.Object{"usage_percent": 75}
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project1/resources/foo/simulate",
+		httptest.WithJSONBody(fooResourceJSON),
+	).ExpectJSON(t, http.StatusOK, jsonmatch.Object{"assets": []jsonmatch.Object{
+		{
+			"id":                  "fooasset1",
+			"size":                1024,
+			"usage_percent":       50,
+			"no_operation_reason": "no threshold is crossed",
+		},
+		{
+			"id":                  "fooasset2",
+			"size":                512,
+			"usage_percent":       80,
+			"no_operation_reason": "critical operations are snoozed until 1970-01-01T02:00:00Z",
+		},
+	}})
+
+	// simulating a resource that does not exist yet is possible, but there are no assets to simulate on
+	s.Handler.RespondTo(ctx, "POST /v1/projects/project3/resources/foo/simulate",
+		httptest.WithJSONBody(fooResourceJSON),
+	).ExpectJSON(t, http.StatusOK, jsonmatch.Object{"assets": []jsonmatch.Object{}})
+
+	tr.DBChanges().AssertEmpty()
+}
+
 func TestDeleteResource(t *testing.T) {
 	s := test.NewSetup(t,
 		commonSetupOptionsForAPITest(),
@@ -581,10 +779,10 @@ func TestDeleteResource(t *testing.T) {
 	tr.DBChanges().AssertEqualf(`
 		DELETE FROM assets WHERE id = 1 AND resource_id = 1 AND uuid = 'fooasset1';
 		DELETE FROM assets WHERE id = 2 AND resource_id = 1 AND uuid = 'fooasset2';
-		DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'critical' AND outcome = 'error-resolved' AND old_size = 0 AND new_size = 0 AND created_at = 21 AND confirmed_at = 22 AND greenlit_at = 22 AND finished_at = 23 AND greenlit_by_user_uuid = 'user3' AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":0}';
-		DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'critical' AND outcome = 'errored' AND old_size = 1024 AND new_size = 1025 AND created_at = 51 AND confirmed_at = 52 AND greenlit_at = 52 AND finished_at = 53 AND greenlit_by_user_uuid = NULL AND error_message = 'datacenter is on fire' AND errored_attempts = 0 AND usage = '{"singular":983.04}';
-		DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'high' AND outcome = 'succeeded' AND old_size = 1023 AND new_size = 1024 AND created_at = 41 AND confirmed_at = 42 AND greenlit_at = 43 AND finished_at = 44 AND greenlit_by_user_uuid = 'user2' AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":818.4}';
-		DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'low' AND outcome = 'cancelled' AND old_size = 1000 AND new_size = 900 AND created_at = 31 AND confirmed_at = NULL AND greenlit_at = NULL AND finished_at = 32 AND greenlit_by_user_uuid = NULL AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":200}';
+		DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'critical' AND outcome = 'error-resolved' AND old_size = 0 AND new_size = 0 AND created_at = 21 AND confirmed_at = 22 AND greenlit_at = 22 AND finished_at = 23 AND greenlit_by_user_uuid = 'user3' AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":0}' AND cancelled_by_user_uuid = NULL;
+		DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'critical' AND outcome = 'errored' AND old_size = 1024 AND new_size = 1025 AND created_at = 51 AND confirmed_at = 52 AND greenlit_at = 52 AND finished_at = 53 AND greenlit_by_user_uuid = NULL AND error_message = 'datacenter is on fire' AND errored_attempts = 0 AND usage = '{"singular":983.04}' AND cancelled_by_user_uuid = NULL;
+		DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'high' AND outcome = 'succeeded' AND old_size = 1023 AND new_size = 1024 AND created_at = 41 AND confirmed_at = 42 AND greenlit_at = 43 AND finished_at = 44 AND greenlit_by_user_uuid = 'user2' AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":818.4}' AND cancelled_by_user_uuid = NULL;
+		DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'low' AND outcome = 'cancelled' AND old_size = 1000 AND new_size = 900 AND created_at = 31 AND confirmed_at = NULL AND greenlit_at = NULL AND finished_at = 32 AND greenlit_by_user_uuid = NULL AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":200}' AND cancelled_by_user_uuid = NULL;
 		DELETE FROM resources WHERE id = 1 AND scope_uuid = 'project1' AND asset_type = 'foo';
 	`)
 	s.Auditor.ExpectEvents(t, cadf.Event{
@@ -639,3 +837,114 @@ func TestSeedBlocksResourceUpdates(t *testing.T) {
 		httptest.WithJSONBody(initialFooResourceJSON),
 	).ExpectStatus(t, http.StatusConflict)
 }
+
+func TestDomainResources(t *testing.T) {
+	s := test.NewSetup(t,
+		commonSetupOptionsForAPITest(),
+		test.WithConfig(`{
+			"domain_seeds": [
+				{
+					"domain_name": "First Domain",
+					"disabled_resources": [ "qux" ]
+				}
+			]
+		}`),
+	)
+	commonSetupFillDB(t, s)
+	ctx := t.Context()
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// domain endpoints require a token with domain access, and unknown domains are reported as such
+	s.Validator.Enforcer.Forbid("domain:access")
+	s.Handler.RespondTo(ctx, "GET /v1/domains/domain1").
+		ExpectStatus(t, http.StatusForbidden)
+	s.Validator.Enforcer.Allow("domain:access")
+	s.Handler.RespondTo(ctx, "GET /v1/domains/domain2").
+		ExpectStatus(t, http.StatusNotFound)
+
+	// resources of the projects in this domain do not show up on the domain level
+	s.Handler.RespondTo(ctx, "GET /v1/domains/domain1").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{
+			"resources": jsonmatch.Object{},
+		})
+	s.Handler.RespondTo(ctx, "GET /v1/domains/domain1/resources/foo").
+		ExpectStatus(t, http.StatusNotFound)
+
+	// domain resources are governed by their own policy rules
+	s.Validator.Enforcer.Forbid("project:edit:foo") // this should not be an issue
+	s.Validator.Enforcer.Forbid("domain:edit:foo")
+	s.Handler.RespondTo(ctx, "PUT /v1/domains/domain1/resources/foo",
+		httptest.WithJSONBody(initialFooResourceJSON),
+	).ExpectStatus(t, http.StatusForbidden)
+	s.Validator.Enforcer.Allow("domain:edit:foo")
+
+	// seeds for domain resources are respected
+	s.Handler.RespondTo(ctx, "PUT /v1/domains/domain1/resources/qux",
+		httptest.WithJSONBody(initialFooResourceJSON),
+	).ExpectStatus(t, http.StatusConflict)
+	tr.DBChanges().AssertEmpty()
+
+	// happy path: create a domain resource
+	s.Auditor.IgnoreEventsUntilNow()
+	newFooResourceJSON := jsonmatch.Object{
+		"critical_threshold": jsonmatch.Object{
+			"usage_percent": 98,
+		},
+		"size_steps": jsonmatch.Object{
+			"percent": 15,
+		},
+	}
+	s.Handler.RespondTo(ctx, "PUT /v1/domains/domain1/resources/foo",
+		httptest.WithJSONBody(newFooResourceJSON),
+	).ExpectStatus(t, http.StatusAccepted)
+	tr.DBChanges().AssertEqualf(`
+		INSERT INTO resources (id, scope_uuid, asset_type, low_threshold_percent, low_delay_seconds, high_threshold_percent, high_delay_seconds, critical_threshold_percent, size_step_percent, domain_uuid, next_scrape_at) VALUES (5, 'domain1', 'foo', '{"singular":0}', 0, '{"singular":0}', 0, '{"singular":98}', 15, 'domain1', 0);
+	`)
+	s.Auditor.ExpectEvents(t, cadf.Event{
+		Action:      "enable/foo",
+		Outcome:     "success",
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "202"},
+		RequestPath: "/v1/domains/domain1/resources/foo",
+		Target: cadf.Resource{
+			TypeURI:  "data/security/domain",
+			ID:       "domain1",
+			DomainID: "domain1",
+			Attachments: []cadf.Attachment{{
+				Name:    "payload",
+				TypeURI: "mime:application/json",
+				Content: toJSONVia[castellum.Resource](newFooResourceJSON),
+			}},
+		},
+	})
+
+	// the domain resource can be read back...
+	s.Handler.RespondTo(ctx, "GET /v1/domains/domain1/resources/foo").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{
+			"asset_count": 0,
+			"critical_threshold": jsonmatch.Object{
+				"usage_percent": 98,
+			},
+			"size_steps": jsonmatch.Object{
+				"percent": 15,
+			},
+		})
+	s.Validator.Enforcer.Forbid("domain:show:foo")
+	s.Handler.RespondTo(ctx, "GET /v1/domains/domain1").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{
+			"resources": jsonmatch.Object{},
+		})
+	s.Validator.Enforcer.Allow("domain:show:foo")
+
+	// ...but does not show up in the projects of this domain
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/resources/foo").
+		ExpectJSON(t, http.StatusOK, initialFooResourceJSON)
+
+	// ...and can be deleted again
+	s.Handler.RespondTo(ctx, "DELETE /v1/domains/domain1/resources/foo").
+		ExpectStatus(t, http.StatusNoContent)
+	tr.DBChanges().AssertEqualf(`
+		DELETE FROM resources WHERE id = 5 AND scope_uuid = 'domain1' AND asset_type = 'foo';
+	`)
+}
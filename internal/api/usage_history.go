@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+const (
+	defaultUsageHistoryRange = 24 * time.Hour
+	maxUsageHistoryPoints    = 2000
+)
+
+// UsageSample is the API representation of a db.AssetUsageSample.
+type UsageSample struct {
+	Timestamp    int64                 `json:"at"`
+	Size         uint64                `json:"size"`
+	Usage        castellum.UsageValues `json:"usage"`
+	UsagePercent castellum.UsageValues `json:"usage_percent"`
+}
+
+// UsageSampleFromDB converts a db.AssetUsageSample into an api.UsageSample.
+func UsageSampleFromDB(sample db.AssetUsageSample) UsageSample {
+	return UsageSample{
+		Timestamp:    sample.RecordedAt.Unix(),
+		Size:         sample.Size,
+		Usage:        sample.Usage,
+		UsagePercent: core.GetMultiUsagePercent(sample.Size, sample.Usage),
+	}
+}
+
+// GetAssetUsageHistory handles GET /v1/(projects|domains)/:id/assets/:type/:uuid/usage-history.
+func (h handler) GetAssetUsageHistory(w http.ResponseWriter, r *http.Request) {
+	identifyScopedEndpoint(r, "/assets/:type/:uuid/usage-history")
+	ctx := r.Context()
+	scopeUUID, token := h.CheckToken(w, r)
+	if token == nil {
+		return
+	}
+	dbResource := h.LoadResource(w, r, scopeUUID, token, false)
+	if dbResource == nil {
+		return
+	}
+	dbAsset := h.LoadAsset(w, r, *dbResource)
+	if dbAsset == nil {
+		return
+	}
+
+	// parse query parameters
+	query := r.URL.Query()
+	to := h.TimeNow()
+	if value := query.Get("to"); value != "" {
+		timestamp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid to: expected a UNIX timestamp, got %q", value), http.StatusBadRequest)
+			return
+		}
+		to = time.Unix(timestamp, 0).UTC()
+	}
+	from := to.Add(-defaultUsageHistoryRange)
+	if value := query.Get("from"); value != "" {
+		timestamp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid from: expected a UNIX timestamp, got %q", value), http.StatusBadRequest)
+			return
+		}
+		from = time.Unix(timestamp, 0).UTC()
+	}
+	if !from.Before(to) {
+		http.Error(w, "invalid time range: from must be before to", http.StatusBadRequest)
+		return
+	}
+	step := core.UsageHistoryResolution
+	if value := query.Get("step"); value != "" {
+		resolutionSecs := int64(core.UsageHistoryResolution / time.Second)
+		stepSecs, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || stepSecs <= 0 || stepSecs%resolutionSecs != 0 {
+			http.Error(w, fmt.Sprintf("invalid step: expected a multiple of %d seconds, got %q", resolutionSecs, value), http.StatusBadRequest)
+			return
+		}
+		step = time.Duration(stepSecs) * time.Second
+	}
+	if to.Sub(from)/step > maxUsageHistoryPoints {
+		http.Error(w, fmt.Sprintf("too many data points requested (limit is %d): choose a larger step or a shorter time range", maxUsageHistoryPoints), http.StatusBadRequest)
+		return
+	}
+
+	samples, err := db.AssetUsageSampleStore.SelectWhere(ctx, h.DB,
+		`asset_id = $1 AND recorded_at >= $2 AND recorded_at < $3 ORDER BY recorded_at`,
+		dbAsset.ID, from, to).Collect()
+	if respondwith.ObfuscatedErrorText(w, err) {
+		return
+	}
+
+	result := make([]UsageSample, 0, len(samples))
+	for _, sample := range core.DownsampleUsageHistory(samples, step) {
+		result = append(result, UsageSampleFromDB(sample))
+	}
+	respondwith.JSON(w, http.StatusOK, struct {
+		UsageHistory []UsageSample `json:"usage_history"`
+	}{result})
+}
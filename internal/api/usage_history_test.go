@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/must"
+	"go.xyrillian.de/gg/jsonmatch"
+
+	"github.com/sapcc/castellum/internal/db"
+	"github.com/sapcc/castellum/internal/test"
+)
+
+func TestGetAssetUsageHistory(t *testing.T) {
+	s := test.NewSetup(t,
+		commonSetupOptionsForAPITest(),
+	)
+	commonSetupFillDB(t, s)
+	ctx := t.Context()
+
+	// initially, there is no history
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset1/usage-history").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{"usage_history": jsonmatch.Array{}})
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/doesnotexist/usage-history").
+		ExpectStatus(t, http.StatusNotFound)
+
+	for idx := range 6 {
+		must.SucceedT(t, db.AssetUsageSampleStore.Insert(ctx, s.DB, &db.AssetUsageSample{
+			AssetID:    1,
+			RecordedAt: time.Unix(int64(idx*600), 0).UTC(),
+			Size:       1000,
+			Usage:      castellum.UsageValues{castellum.SingularUsageMetric: float64(100 * (idx + 1))},
+		}))
+	}
+
+	// samples are reported in the native resolution by default
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset1/usage-history?from=0&to=1800").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{
+			"usage_history": jsonmatch.Array{
+				jsonmatch.Object{"at": 0, "size": 1000, "usage": 100, "usage_percent": 10},
+				jsonmatch.Object{"at": 600, "size": 1000, "usage": 200, "usage_percent": 20},
+				jsonmatch.Object{"at": 1200, "size": 1000, "usage": 300, "usage_percent": 30},
+			},
+		})
+
+	// with a larger step, the latest sample in each interval is reported
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset1/usage-history?from=0&to=3600&step=1800").
+		ExpectJSON(t, http.StatusOK, jsonmatch.Object{
+			"usage_history": jsonmatch.Array{
+				jsonmatch.Object{"at": 0, "size": 1000, "usage": 300, "usage_percent": 30},
+				jsonmatch.Object{"at": 1800, "size": 1000, "usage": 600, "usage_percent": 60},
+			},
+		})
+
+	// invalid query parameters are rejected
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset1/usage-history?from=yesterday").
+		ExpectText(t, http.StatusBadRequest, "invalid from: expected a UNIX timestamp, got \"yesterday\"\n")
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset1/usage-history?from=3600&to=0").
+		ExpectText(t, http.StatusBadRequest, "invalid time range: from must be before to\n")
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset1/usage-history?from=0&to=3600&step=900").
+		ExpectText(t, http.StatusBadRequest, "invalid step: expected a multiple of 600 seconds, got \"900\"\n")
+	s.Handler.RespondTo(ctx, "GET /v1/projects/project1/assets/foo/fooasset1/usage-history?from=0&to=100000000").
+		ExpectText(t, http.StatusBadRequest, "too many data points requested (limit is 2000): choose a larger step or a shorter time range\n")
+}
@@ -0,0 +1,264 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package checkconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// Options contains the parameters for Run().
+type Options struct {
+	// The asset managers that seeded resources are validated against. If nil,
+	// each seeded resource is validated against a stub asset manager that
+	// accepts its asset type (see LocalAssetManager).
+	Team core.AssetManagerTeam
+	// If not nil, seeded projects and domains are looked up in Keystone. Their
+	// IDs are then used when validating seeded resources, so that
+	// `max_asset_sizes` rules with a `scope_uuid` are taken into account.
+	ProviderClient core.ProviderClient
+	// If true, seeded projects and domains that do not exist in Keystone are
+	// reported as errors instead of warnings. (Requires .ProviderClient.)
+	RequireSeededScopes bool
+}
+
+// Report is the result of Run().
+type Report struct {
+	Errors   []string
+	Warnings []string
+}
+
+func (r *Report) addErrorf(msg string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(msg, args...))
+}
+
+func (r *Report) addWarningf(msg string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(msg, args...))
+}
+
+// WriteTo prints the report in a human-readable form.
+func (r Report) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	write := func(msg string, args ...any) error {
+		n, err := fmt.Fprintf(w, msg, args...)
+		total += int64(n)
+		return err
+	}
+
+	for _, msg := range r.Errors {
+		err := write("ERROR: %s\n", msg)
+		if err != nil {
+			return total, err
+		}
+	}
+	for _, msg := range r.Warnings {
+		err := write("WARNING: %s\n", msg)
+		if err != nil {
+			return total, err
+		}
+	}
+	err := write("found %d errors and %d warnings\n", len(r.Errors), len(r.Warnings))
+	return total, err
+}
+
+// Run validates the given configuration beyond what core.LoadConfig() checks.
+// Every seeded resource is validated in the same way as the observer does
+// when applying the seed, and `max_asset_sizes` rules are checked for overlap.
+// Conflicts between seeded maximum sizes and `max_asset_sizes` are found by
+// the former check.
+func Run(ctx context.Context, cfg core.Config, opts Options) (Report, error) {
+	var r Report
+	seededAssetTypes := make(map[db.AssetType]struct{})
+
+	for idx, seed := range cfg.ProjectSeeds {
+		desc := fmt.Sprintf("project_seeds[%d] (project %s/%s)", idx, seed.DomainName, seed.ProjectName)
+		scopeUUID, domainUUID := "", ""
+		if opts.ProviderClient != nil {
+			var err error
+			scopeUUID, err = opts.ProviderClient.FindProjectID(ctx, seed.ProjectName, seed.DomainName)
+			if err != nil {
+				return Report{}, fmt.Errorf(`cannot find project "%s/%s": %w`, seed.DomainName, seed.ProjectName, err)
+			}
+			if scopeUUID == "" {
+				r.addScopeMissing(desc, "project", opts.RequireSeededScopes)
+			} else {
+				proj, err := opts.ProviderClient.GetProject(ctx, scopeUUID)
+				if err != nil {
+					return Report{}, err
+				}
+				if proj != nil {
+					domainUUID = proj.DomainID
+				}
+			}
+		}
+		r.checkResourceSeed(ctx, cfg, opts, desc, scopeUUID, domainUUID, seed.ResourceSeed)
+		for assetType := range seed.Resources {
+			seededAssetTypes[assetType] = struct{}{}
+		}
+	}
+
+	for idx, seed := range cfg.DomainSeeds {
+		desc := fmt.Sprintf("domain_seeds[%d] (domain %s)", idx, seed.DomainName)
+		scopeUUID := ""
+		if opts.ProviderClient != nil {
+			var err error
+			scopeUUID, err = opts.ProviderClient.FindDomainID(ctx, seed.DomainName)
+			if err != nil {
+				return Report{}, fmt.Errorf(`cannot find domain "%s": %w`, seed.DomainName, err)
+			}
+			if scopeUUID == "" {
+				r.addScopeMissing(desc, "domain", opts.RequireSeededScopes)
+			}
+		}
+		r.checkResourceSeed(ctx, cfg, opts, desc, scopeUUID, scopeUUID, seed.ResourceSeed)
+		for assetType := range seed.Resources {
+			seededAssetTypes[assetType] = struct{}{}
+		}
+	}
+
+	r.checkMaxAssetSizeOverlap(cfg, slices.Sorted(maps.Keys(seededAssetTypes)))
+	return r, nil
+}
+
+func (r *Report) addScopeMissing(desc, scopeType string, isError bool) {
+	if isError {
+		r.addErrorf("%s: %s does not exist", desc, scopeType)
+	} else {
+		r.addWarningf("%s: %s does not exist, so this seed will be skipped", desc, scopeType)
+	}
+}
+
+func (r *Report) checkResourceSeed(ctx context.Context, cfg core.Config, opts Options, desc, scopeUUID, domainUUID string, seed core.ResourceSeed) {
+	// when the seed is fully applied, all seeded resources will exist
+	existingResources := make(map[db.AssetType]struct{}, len(seed.Resources))
+	for assetType := range seed.Resources {
+		existingResources[assetType] = struct{}{}
+	}
+
+	for _, assetType := range slices.Sorted(maps.Keys(seed.Resources)) {
+		spec := seed.Resources[assetType]
+		team := opts.Team
+		if team == nil {
+			team = core.AssetManagerTeam{LocalAssetManager(assetType, spec)}
+		}
+
+		res := db.Resource{
+			ScopeUUID:  scopeUUID,
+			DomainUUID: domainUUID,
+			AssetType:  assetType,
+		}
+		errs := core.ApplyResourceSpecInto(ctx, &res, spec, existingResources, cfg, team)
+		for _, err := range errs {
+			r.addErrorf("%s: resources.%s: %s", desc, assetType, err.Error())
+		}
+
+		for _, rx := range seed.DisabledResourceRegexps {
+			if rx.MatchString(string(assetType)) {
+				r.addWarningf("%s: resources.%s is also matched by disabled_resources entry %q (the positive seed takes precedence)", desc, assetType, rx)
+			}
+		}
+	}
+}
+
+// If multiple `max_asset_sizes` rules for the same scope match the same asset
+// type, only the last one has any effect, which is usually not intended.
+func (r *Report) checkMaxAssetSizeOverlap(cfg core.Config, assetTypes []db.AssetType) {
+	for _, assetType := range assetTypes {
+		lastMatchIdxByScope := make(map[string]int)
+		for idx, rule := range cfg.MaxAssetSizeRules {
+			if !rule.AssetTypeRx.MatchString(string(assetType)) {
+				continue
+			}
+			if prevIdx, exists := lastMatchIdxByScope[rule.ScopeUUID]; exists {
+				r.addWarningf("max_asset_sizes[%d] and max_asset_sizes[%d] both apply to asset type %q; only max_asset_sizes[%d] will have an effect",
+					prevIdx, idx, assetType, idx)
+			}
+			lastMatchIdxByScope[rule.ScopeUUID] = idx
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// asset manager for local validation
+
+// LocalAssetManager returns a core.AssetManager that only knows about the
+// given asset type. Its usage metrics are taken from the thresholds in the
+// given resource spec, and it accepts any type-specific configuration. This
+// allows validating seeds without access to OpenStack, e.g. in CI.
+func LocalAssetManager(assetType db.AssetType, spec core.ResourceSpec) core.AssetManager {
+	metrics := make(map[castellum.UsageMetric]struct{})
+	for _, threshold := range []Option[castellum.Threshold]{spec.LowThreshold, spec.HighThreshold, spec.CriticalThreshold} {
+		if t, ok := threshold.Unpack(); ok {
+			for metric := range t.UsagePercent {
+				metrics[metric] = struct{}{}
+			}
+		}
+	}
+	if len(metrics) == 0 {
+		metrics[castellum.SingularUsageMetric] = struct{}{}
+	}
+
+	return localAssetManager{Info: core.AssetTypeInfo{
+		AssetType:    assetType,
+		UsageMetrics: slices.Sorted(maps.Keys(metrics)),
+	}}
+}
+
+type localAssetManager struct {
+	Info core.AssetTypeInfo
+}
+
+var errNotSupported = errors.New("not supported during local config validation")
+
+// PluginTypeID implements the core.AssetManager interface.
+func (m localAssetManager) PluginTypeID() string { return "check-config" }
+
+// Init implements the core.AssetManager interface.
+func (m localAssetManager) Init(ctx context.Context, provider core.ProviderClient) error {
+	return nil // unused
+}
+
+// InfoForAssetType implements the core.AssetManager interface.
+func (m localAssetManager) InfoForAssetType(assetType db.AssetType) Option[core.AssetTypeInfo] {
+	if assetType == m.Info.AssetType {
+		return Some(m.Info)
+	}
+	return None[core.AssetTypeInfo]()
+}
+
+// CheckResourceAllowed implements the core.AssetManager interface.
+func (m localAssetManager) CheckResourceAllowed(_ context.Context, _ db.AssetType, _, _ string, _ map[db.AssetType]struct{}) error {
+	// the type-specific configuration can only be checked by the actual asset manager
+	return nil
+}
+
+// ListAssets implements the core.AssetManager interface.
+func (m localAssetManager) ListAssets(_ context.Context, _ db.Resource) ([]string, error) {
+	return nil, errNotSupported
+}
+
+// DescribeAssets implements the core.AssetDescriber interface.
+func (m localAssetManager) DescribeAssets(_ context.Context, _ db.Resource) ([]core.AssetDescription, error) {
+	return nil, errNotSupported
+}
+
+// SetAssetSize implements the core.AssetManager interface.
+func (m localAssetManager) SetAssetSize(_ context.Context, _ db.Resource, _ string, _, _ uint64) (castellum.OperationOutcome, error) {
+	return castellum.OperationOutcomeErrored, errNotSupported
+}
+
+// GetAssetStatus implements the core.AssetManager interface.
+func (m localAssetManager) GetAssetStatus(_ context.Context, _ db.Resource, _ string, _ Option[core.AssetStatus]) (core.AssetStatus, error) {
+	return core.AssetStatus{}, errNotSupported
+}
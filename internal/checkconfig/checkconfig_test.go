@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package checkconfig_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/must"
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/castellum/internal/checkconfig"
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/plugins"
+	"github.com/sapcc/castellum/internal/test"
+)
+
+const testConfigJSON = `{
+	"max_asset_sizes": [
+		{ "asset_type": "foo.*", "value": 1000 },
+		{ "asset_type": "foo", "value": 2000 },
+		{ "asset_type": "foo", "scope_uuid": "project1", "value": 500 }
+	],
+	"project_seeds": [
+		{
+			"project_name": "First Project",
+			"domain_name": "First Domain",
+			"resources": {
+				"foo": {
+					"low_threshold": { "usage_percent": { "things": 20 }, "delay_seconds": 3600 },
+					"high_threshold": { "usage_percent": { "things": 80 }, "delay_seconds": 1800 },
+					"size_steps": { "percent": 20 },
+					"size_constraints": { "maximum": 1500 }
+				}
+			},
+			"disabled_resources": [ "f.*" ]
+		},
+		{
+			"project_name": "Unknown Project",
+			"domain_name": "First Domain",
+			"resources": {
+				"bar": {
+					"low_threshold": { "usage_percent": 80, "delay_seconds": 3600 },
+					"high_threshold": { "usage_percent": 20, "delay_seconds": 1800 },
+					"size_steps": { "percent": 20 }
+				}
+			}
+		}
+	]
+}`
+
+func TestCheckConfigLocal(t *testing.T) {
+	var cfg core.Config
+	must.SucceedT(t, json.Unmarshal([]byte(testConfigJSON), &cfg))
+
+	report := must.ReturnT(checkconfig.Run(t.Context(), cfg, checkconfig.Options{}))(t)
+	assert.Equal(t, report.Errors, []string{
+		// the scoped max_asset_sizes rule cannot be considered since project IDs are not known
+		`project_seeds[1] (project First Domain/Unknown Project): resources.bar: low threshold must be below high threshold`,
+	})
+	assert.Equal(t, report.Warnings, []string{
+		`project_seeds[0] (project First Domain/First Project): resources.foo is also matched by disabled_resources entry "f.*" (the positive seed takes precedence)`,
+		`max_asset_sizes[0] and max_asset_sizes[1] both apply to asset type "foo"; only max_asset_sizes[1] will have an effect`,
+	})
+
+	var buf strings.Builder
+	must.ReturnT(report.WriteTo(&buf))(t)
+	assert.Equal(t, strings.Count(buf.String(), "\n"), 4)
+	assert.Equal(t, strings.HasSuffix(buf.String(), "found 1 errors and 2 warnings\n"), true)
+}
+
+func TestCheckConfigWithAssetManagers(t *testing.T) {
+	var cfg core.Config
+	must.SucceedT(t, json.Unmarshal([]byte(testConfigJSON), &cfg))
+
+	opts := checkconfig.Options{
+		Team: core.AssetManagerTeam{
+			&plugins.AssetManagerStatic{AssetType: "foo", UsageMetrics: []castellum.UsageMetric{"things"}},
+		},
+		ProviderClient: test.MockProviderClient{
+			Domains: map[string]core.CachedDomain{
+				"domain1": {Name: "First Domain"},
+			},
+			Projects: map[string]core.CachedProject{
+				"project1": {Name: "First Project", DomainID: "domain1"},
+			},
+		},
+		RequireSeededScopes: true,
+	}
+	report := must.ReturnT(checkconfig.Run(t.Context(), cfg, opts))(t)
+	assert.Equal(t, report.Errors, []string{
+		`project_seeds[0] (project First Domain/First Project): resources.foo: maximum size must be 500 or less`,
+		`project_seeds[1] (project First Domain/Unknown Project): project does not exist`,
+		`project_seeds[1] (project First Domain/Unknown Project): resources.bar: unsupported asset type`,
+	})
+}
@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/sapcc/go-bits/errext"
+	"github.com/sapcc/go-bits/regexpext"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// AssetExclusions appears in type ResourceSpec. Assets matching any of these
+// rules are still tracked, but Castellum will not resize them.
+type AssetExclusions struct {
+	// Assets with these UUIDs are excluded.
+	AssetUUIDs []string `json:"asset_ids,omitempty"`
+	// Assets whose name matches any of these regexes are excluded.
+	NameRegexps []regexpext.BoundedRegexp `json:"names,omitempty"`
+	// Assets are excluded if they have a metadata field with one of these keys,
+	// and its value matches the respective regex.
+	MetadataRegexps map[string]regexpext.BoundedRegexp `json:"metadata,omitempty"`
+}
+
+// AssetDescription contains the name and metadata of an asset, as reported
+// by AssetDescriber.DescribeAssets().
+type AssetDescription struct {
+	UUID     string
+	Name     string
+	Metadata map[string]string
+}
+
+// AssetDescriber is an optional interface that an AssetManager can implement
+// if its backend reports names and metadata for its assets. This is required
+// for resources that exclude assets by name or metadata.
+type AssetDescriber interface {
+	// DescribeAssets is like AssetManager.ListAssets(), but also returns the
+	// name and metadata of each asset.
+	DescribeAssets(ctx context.Context, res db.Resource) ([]AssetDescription, error)
+}
+
+// AssetExclusionsOf deserializes the asset exclusions of the given resource, if any.
+func AssetExclusionsOf(res db.Resource) (Option[AssetExclusions], error) {
+	if res.ExclusionsJSON == "" {
+		return None[AssetExclusions](), nil
+	}
+	var ex AssetExclusions
+	err := json.Unmarshal([]byte(res.ExclusionsJSON), &ex)
+	if err != nil {
+		return None[AssetExclusions](), fmt.Errorf("could not parse asset exclusions of resource %d: %w", res.ID, err)
+	}
+	return Some(ex), nil
+}
+
+// NeedsDescriptions returns whether evaluating these rules requires the names
+// and metadata of assets.
+func (ex AssetExclusions) NeedsDescriptions() bool {
+	return len(ex.NameRegexps) > 0 || len(ex.MetadataRegexps) > 0
+}
+
+// ExclusionReason returns a human-readable explanation if the given asset
+// matches any of these rules, or the empty string otherwise.
+func (ex AssetExclusions) ExclusionReason(asset AssetDescription) string {
+	if slices.Contains(ex.AssetUUIDs, asset.UUID) {
+		return "excluded by asset ID"
+	}
+	for _, rx := range ex.NameRegexps {
+		if rx.MatchString(asset.Name) {
+			return fmt.Sprintf("excluded because name matches %q", string(rx))
+		}
+	}
+
+	keys := make([]string, 0, len(ex.MetadataRegexps))
+	for key := range ex.MetadataRegexps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // for deterministic results when multiple rules match
+	for _, key := range keys {
+		value, exists := asset.Metadata[key]
+		if exists && ex.MetadataRegexps[key].MatchString(value) {
+			return fmt.Sprintf("excluded because metadata %q matches %q", key, string(ex.MetadataRegexps[key]))
+		}
+	}
+	return ""
+}
+
+func applyAssetExclusionsSpecInto(res *db.Resource, spec ResourceSpec, manager AssetManager) (errs errext.ErrorSet) {
+	ex, ok := spec.AssetExclusions.Unpack()
+	if !ok {
+		res.ExclusionsJSON = ""
+		return
+	}
+
+	if ex.NeedsDescriptions() {
+		if _, ok := manager.(AssetDescriber); !ok {
+			errs.Addf("excluding assets by name or metadata is not supported for this asset type")
+		}
+	}
+	if slices.Contains(ex.AssetUUIDs, "") {
+		errs.Addf("asset IDs in exclusions may not be empty")
+	}
+
+	buf, err := json.Marshal(ex)
+	if err != nil {
+		errs.Add(err)
+	}
+	res.ExclusionsJSON = string(buf)
+	return
+}
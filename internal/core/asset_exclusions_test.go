@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"testing"
+
+	"github.com/sapcc/go-bits/regexpext"
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+func TestAssetExclusionReason(t *testing.T) {
+	ex := AssetExclusions{
+		AssetUUIDs:  []string{"asset1"},
+		NameRegexps: []regexpext.BoundedRegexp{"db-.*"},
+		MetadataRegexps: map[string]regexpext.BoundedRegexp{
+			"castellum": "off|disabled",
+			"backup":    "true",
+		},
+	}
+	assert.Equal(t, ex.NeedsDescriptions(), true)
+	assert.Equal(t, AssetExclusions{AssetUUIDs: []string{"asset1"}}.NeedsDescriptions(), false)
+
+	check := func(asset AssetDescription, expected string) {
+		t.Helper()
+		assert.Equal(t, ex.ExclusionReason(asset), expected)
+	}
+	check(AssetDescription{UUID: "asset1"}, "excluded by asset ID")
+	check(AssetDescription{UUID: "asset2"}, "")
+	check(AssetDescription{UUID: "asset2", Name: "db-primary"}, `excluded because name matches "db-.*"`)
+	// regexes must match the entire name
+	check(AssetDescription{UUID: "asset2", Name: "my-db-primary"}, "")
+	check(AssetDescription{UUID: "asset2", Metadata: map[string]string{"castellum": "off"}}, `excluded because metadata "castellum" matches "off|disabled"`)
+	check(AssetDescription{UUID: "asset2", Metadata: map[string]string{"castellum": "on"}}, "")
+	// when multiple rules match, the result is deterministic
+	check(AssetDescription{UUID: "asset2", Metadata: map[string]string{"castellum": "off", "backup": "true"}}, `excluded because metadata "backup" matches "true"`)
+}
+
+func TestAssetExclusionsOf(t *testing.T) {
+	ex, err := AssetExclusionsOf(db.Resource{ID: 1})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, ex.IsNone(), true)
+
+	ex, err = AssetExclusionsOf(db.Resource{ID: 1, ExclusionsJSON: `{"asset_ids":["asset1"]}`})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, ex.IsSome(), true)
+	assert.Equal(t, ex.UnwrapOr(AssetExclusions{}).ExclusionReason(AssetDescription{UUID: "asset1"}), "excluded by asset ID")
+
+	_, err = AssetExclusionsOf(db.Resource{ID: 1, ExclusionsJSON: `{"names":["("]}`})
+	assert.ErrEqual(t, err, `could not parse asset exclusions of resource 1: "(" is not a valid regexp: error parsing regexp: missing closing ): `+"`^(?:()$`")
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"sort"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/errext"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// AssetOverrideSpec is the representation of a db.AssetOverride in the API.
+// All fields are optional. Fields that are not given fall back to the
+// configuration of the resource.
+type AssetOverrideSpec struct {
+	Disabled          bool                              `json:"disabled,omitempty"`
+	LowThreshold      Option[castellum.Threshold]       `json:"low_threshold,omitzero"`
+	HighThreshold     Option[castellum.Threshold]       `json:"high_threshold,omitzero"`
+	CriticalThreshold Option[castellum.Threshold]       `json:"critical_threshold,omitzero"`
+	SizeConstraints   Option[castellum.SizeConstraints] `json:"size_constraints,omitzero"`
+}
+
+// IsEmpty returns whether this spec does not override anything.
+func (spec AssetOverrideSpec) IsEmpty() bool {
+	return !spec.Disabled && spec.LowThreshold.IsNone() && spec.HighThreshold.IsNone() &&
+		spec.CriticalThreshold.IsNone() && spec.SizeConstraints.IsNone()
+}
+
+// ApplyAssetOverrideSpecInto validates the given override specification
+// against the configuration of the asset's resource, and applies it in-place
+// into the given db.AssetOverride record.
+func ApplyAssetOverrideSpecInto(override *db.AssetOverride, spec AssetOverrideSpec, res db.Resource, cfg Config, info AssetTypeInfo) (errs errext.ErrorSet) {
+	override.Disabled = spec.Disabled
+
+	thresholdSpecs := []struct {
+		Name        string
+		Spec        Option[castellum.Threshold]
+		Target      *Option[castellum.UsageValues]
+		IsAvailable bool
+	}{
+		{"low", spec.LowThreshold, &override.LowThresholdPercent, res.LowThresholdPercent.IsNonZero()},
+		{"high", spec.HighThreshold, &override.HighThresholdPercent, res.HighThresholdPercent.IsNonZero()},
+		{"critical", spec.CriticalThreshold, &override.CriticalThresholdPercent, res.CriticalThresholdPercent.IsNonZero()},
+	}
+	for _, t := range thresholdSpecs {
+		threshold, ok := t.Spec.Unpack()
+		if !ok {
+			*t.Target = None[castellum.UsageValues]()
+			continue
+		}
+		*t.Target = Some(threshold.UsagePercent)
+		if threshold.DelaySeconds != 0 {
+			errs.Addf("delay for %s threshold cannot be overridden per asset", t.Name)
+		}
+		// enabling a threshold that the resource does not have is not supported
+		// because the resource does not provide a delay for it
+		if !t.IsAvailable && threshold.UsagePercent.IsNonZero() {
+			errs.Addf("%s threshold cannot be overridden because it is not configured on the resource", t.Name)
+		}
+		errs.Append(checkThresholdOverride(info, t.Name, threshold.UsagePercent))
+	}
+
+	sc := spec.SizeConstraints.UnwrapOr(castellum.SizeConstraints{})
+	override.MinimumSize = sc.Minimum
+	override.MaximumSize = sc.Maximum
+	override.MinimumFreeSize = sc.MinimumFree
+	if sc.MinimumFreeIsCritical {
+		errs.Addf("size_constraints.minimum_free_is_critical cannot be overridden per asset")
+	}
+	if !errs.IsEmpty() {
+		return
+	}
+
+	// check that the merged configuration is consistent
+	logic := LogicOfResource(res, info, Some(*override))
+	for _, metric := range info.UsageMetrics {
+		low := logic.LowThresholdPercent[metric]
+		high := logic.HighThresholdPercent[metric]
+		crit := logic.CriticalThresholdPercent[metric]
+		if low != 0 && high != 0 && low > high {
+			errs.Addf("low threshold%s must be below high threshold", Identifier(metric, " for %s"))
+		}
+		if low != 0 && crit != 0 && low > crit {
+			errs.Addf("low threshold%s must be below critical threshold", Identifier(metric, " for %s"))
+		}
+		if high != 0 && crit != 0 && high > crit {
+			errs.Addf("high threshold%s must be below critical threshold", Identifier(metric, " for %s"))
+		}
+	}
+	if maxSize, ok := logic.MaximumSize.Unpack(); ok {
+		if maxSize <= logic.MinimumSize.UnwrapOr(0) {
+			errs.Addf("maximum size must be greater than minimum size")
+		}
+		if limit, ok := cfg.MaxAssetSizeFor(res.AssetType, res.ScopeUUID).Unpack(); ok && maxSize > limit {
+			errs.Addf("maximum size must be %d or less", limit)
+		}
+	}
+	return
+}
+
+// Like checkThresholdCommon, but 0% is allowed since it disables the threshold for this asset.
+func checkThresholdOverride(info AssetTypeInfo, tType string, vals castellum.UsageValues) (errs errext.ErrorSet) {
+	isMetric := make(map[castellum.UsageMetric]bool)
+	for _, metric := range info.UsageMetrics {
+		isMetric[metric] = true
+		val, exists := vals[metric]
+		if !exists {
+			errs.Addf("missing %s threshold%s", tType, Identifier(metric, " for %s"))
+			continue
+		}
+		if val < 0 || val > 100 {
+			errs.Addf("%s threshold%s must be between 0%% and 100%% of usage", tType, Identifier(metric, " for %s"))
+		}
+	}
+
+	providedMetrics := make([]string, 0, len(vals))
+	for metric := range vals {
+		providedMetrics = append(providedMetrics, string(metric))
+	}
+	sort.Strings(providedMetrics) // for deterministic order of error messages in unit test
+	for _, metric := range providedMetrics {
+		if !isMetric[castellum.UsageMetric(metric)] {
+			errs.Addf("%s threshold specified for metric %q which is not valid for this asset type", tType, metric)
+		}
+	}
+
+	return
+}
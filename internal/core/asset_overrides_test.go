@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+func TestLogicOfResourceWithOverride(t *testing.T) {
+	info := AssetTypeInfo{AssetType: "foo", UsageMetrics: []castellum.UsageMetric{castellum.SingularUsageMetric}}
+	res := db.Resource{
+		AssetType:                "foo",
+		LowThresholdPercent:      singular(20),
+		LowDelaySeconds:          3600,
+		HighThresholdPercent:     singular(80),
+		HighDelaySeconds:         3600,
+		CriticalThresholdPercent: singular(95),
+		SizeStepPercent:          20,
+		MinimumSize:              Some[uint64](100),
+		MaximumSize:              Some[uint64](5000),
+	}
+	asset := mustParseAssetStatus(t, "size=1000, usage=100")
+
+	// without override, the asset would be downsized
+	logic := LogicOfResource(res, info, None[db.AssetOverride]())
+	assert.Equal(t, eligibleOperationsToString(GetEligibleOperations(logic, asset)), "low->800")
+
+	// an override can forbid downsizing by disabling the low threshold...
+	logic = LogicOfResource(res, info, Some(db.AssetOverride{LowThresholdPercent: Some(singular(0))}))
+	assert.Equal(t, eligibleOperationsToString(GetEligibleOperations(logic, asset)), "")
+
+	// ...or by raising the minimum size
+	logic = LogicOfResource(res, info, Some(db.AssetOverride{MinimumSize: Some[uint64](1000)}))
+	assert.Equal(t, eligibleOperationsToString(GetEligibleOperations(logic, asset)), "")
+	assert.Equal(t, logic.MaximumSize, Some[uint64](5000)) // not overridden
+
+	// overridden thresholds apply instead of those of the resource
+	logic = LogicOfResource(res, info, Some(db.AssetOverride{HighThresholdPercent: Some(singular(5))}))
+	assert.Equal(t, eligibleOperationsToString(GetEligibleOperations(logic, asset)), "high->1200")
+
+	// a disabled asset is never eligible for any operation
+	logic = LogicOfResource(res, info, Some(db.AssetOverride{Disabled: true}))
+	assert.Equal(t, eligibleOperationsToString(GetEligibleOperations(logic, asset)), "")
+}
+
+func TestApplyAssetOverrideSpecInto(t *testing.T) {
+	info := AssetTypeInfo{AssetType: "foo", UsageMetrics: []castellum.UsageMetric{castellum.SingularUsageMetric}}
+	cfg := Config{MaxAssetSizeRules: []MaxAssetSizeRule{{AssetTypeRx: "foo", Value: 10000}}}
+	res := db.Resource{
+		AssetType:                "foo",
+		HighThresholdPercent:     singular(80),
+		HighDelaySeconds:         3600,
+		CriticalThresholdPercent: singular(95),
+		SizeStepPercent:          20,
+		MinimumSize:              Some[uint64](100),
+		MaximumSize:              Some[uint64](5000),
+	}
+	threshold := func(percent float64) Option[castellum.Threshold] {
+		return Some(castellum.Threshold{UsagePercent: singular(percent)})
+	}
+	check := func(spec AssetOverrideSpec, expected string) {
+		t.Helper()
+		var override db.AssetOverride
+		assert.Equal(t, ApplyAssetOverrideSpecInto(&override, spec, res, cfg, info).Join(", "), expected)
+	}
+
+	// valid overrides
+	check(AssetOverrideSpec{Disabled: true}, "")
+	check(AssetOverrideSpec{HighThreshold: threshold(60), CriticalThreshold: threshold(0)}, "")
+	check(AssetOverrideSpec{SizeConstraints: Some(castellum.SizeConstraints{Minimum: Some[uint64](2000)})}, "")
+	check(AssetOverrideSpec{LowThreshold: threshold(0)}, "")
+
+	// invalid overrides
+	check(AssetOverrideSpec{LowThreshold: threshold(20)},
+		"low threshold cannot be overridden because it is not configured on the resource")
+	check(AssetOverrideSpec{HighThreshold: Some(castellum.Threshold{UsagePercent: singular(70), DelaySeconds: 60})},
+		"delay for high threshold cannot be overridden per asset")
+	check(AssetOverrideSpec{CriticalThreshold: threshold(120)},
+		"critical threshold must be between 0% and 100% of usage")
+	check(AssetOverrideSpec{HighThreshold: Some(castellum.Threshold{UsagePercent: castellum.UsageValues{"bogus": 50}})},
+		`missing high threshold, high threshold specified for metric "bogus" which is not valid for this asset type`)
+	check(AssetOverrideSpec{HighThreshold: threshold(98)},
+		"high threshold must be below critical threshold")
+	check(AssetOverrideSpec{SizeConstraints: Some(castellum.SizeConstraints{Minimum: Some[uint64](6000)})},
+		"maximum size must be greater than minimum size")
+	check(AssetOverrideSpec{SizeConstraints: Some(castellum.SizeConstraints{Maximum: Some[uint64](20000)})},
+		"maximum size must be 10000 or less")
+	check(AssetOverrideSpec{SizeConstraints: Some(castellum.SizeConstraints{MinimumFree: Some[uint64](10), MinimumFreeIsCritical: true})},
+		"size_constraints.minimum_free_is_critical cannot be overridden per asset")
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/pluggable"
@@ -25,6 +26,23 @@ type AssetStatus struct {
 	Usage             castellum.UsageValues
 	StrictMinimumSize Option[uint64]
 	StrictMaximumSize Option[uint64]
+
+	// (optional) Identifies the backend that hosts this asset, e.g. the storage
+	// host. Assets with the same BackendKey share the rate limits for resizes
+	// that are configured for backends. AssetManagers that do not know the
+	// backend of an asset shall leave this empty.
+	BackendKey string
+
+	// The growth rate of absolute usage per second, as estimated from recent
+	// usage samples. This is never filled by AssetManager.GetAssetStatus(); it
+	// is only filled by Castellum itself for resources with predictive upscaling.
+	UsageGrowthPerSecond castellum.UsageValues
+
+	// (optional) Whether the asset is in a broken state that can be repaired
+	// by AssetRepairer.RepairAsset(). This is only used for deciding whether
+	// to create an operation with reason db.OperationReasonRepair, and is not
+	// persisted.
+	NeedsRepair bool
 }
 
 // StatusOfAsset converts an Asset into just its AssetStatus.
@@ -76,6 +94,21 @@ func (e AssetNotFoundError) Error() string {
 	return e.InnerError.Error()
 }
 
+// ResizeInProgressError is returned by AssetManager.SetAssetSize() (together
+// with outcome "errored") if the resize has been started, but cannot be
+// completed right away, and the asset manager does not want to block until
+// then. The operation stays in the queue, and SetAssetSize() will be called
+// again with the same arguments after the given delay. Unlike other errors,
+// this does not count against the retry budget of the operation.
+type ResizeInProgressError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e ResizeInProgressError) Error() string {
+	return e.Message
+}
+
 var (
 	// ErrNoConfigurationAllowed is returned by AssetManager.CheckResourceAllowed()
 	// when the user has given configuration, but the asset type in question does
@@ -123,12 +156,56 @@ type AssetManager interface {
 	ListAssets(ctx context.Context, res db.Resource) ([]string, error)
 	// The returned Outcome should be either Succeeded, Failed or Errored, but not Cancelled.
 	// The returned error should be nil if and only if the outcome is Succeeded.
+	// Long-running resizes can be split across multiple calls by returning
+	// ResizeInProgressError.
 	SetAssetSize(ctx context.Context, res db.Resource, assetUUID string, oldSize, newSize uint64) (castellum.OperationOutcome, error)
 	// previousStatus will be nil when this function is called for the first time
 	// for the given asset.
 	GetAssetStatus(ctx context.Context, res db.Resource, assetUUID string, previousStatus Option[AssetStatus]) (AssetStatus, error)
 }
 
+// AssetMaintainer is an optional interface that an AssetManager can implement
+// if its assets may require maintenance that does not change their size, e.g.
+// replacing outdated servers in a server group.
+type AssetMaintainer interface {
+	// MaintainAsset is called after each successful GetAssetStatus() while
+	// there is no pending operation on the asset. It performs the next step of
+	// any maintenance that the asset requires, and returns a human-readable
+	// description of the maintenance in progress, or "" if no maintenance is
+	// required. While maintenance is in progress, no new operations are created
+	// on the asset.
+	//
+	// Since this runs within the asset scraping job, it must not block for a
+	// long time. Maintenance that takes longer must be split into steps, and
+	// the progress must be tracked in the backend since MaintainAsset() will be
+	// called again only at the next scrape.
+	MaintainAsset(ctx context.Context, res db.Resource, assetUUID string) (string, error)
+}
+
+// ResourceInfoProvider is an optional interface that an AssetManager can
+// implement if the usage metrics of an asset type depend on the type-specific
+// configuration of the respective resource.
+type ResourceInfoProvider interface {
+	// InfoForResource refines the result of InfoForAssetType() for a specific
+	// resource. It is only called for asset types that the AssetManager
+	// supports, with `configJSON` having been accepted by
+	// CheckResourceAllowed(). If `configJSON` cannot be parsed, the result of
+	// InfoForAssetType() shall be returned unchanged.
+	InfoForResource(info AssetTypeInfo, configJSON string) AssetTypeInfo
+}
+
+// AssetRepairer is an optional interface that an AssetManager can implement
+// if it can repair broken assets, e.g. by replacing broken servers in a
+// server group. When GetAssetStatus() reports AssetStatus.NeedsRepair,
+// Castellum creates an operation with reason db.OperationReasonRepair, and
+// executes it by calling RepairAsset() instead of SetAssetSize().
+type AssetRepairer interface {
+	// RepairAsset has the same semantics as AssetManager.SetAssetSize(),
+	// except that it shall not change the size of the asset. If the asset does
+	// not need to be repaired anymore, it shall return outcome "succeeded".
+	RepairAsset(ctx context.Context, res db.Resource, assetUUID string) (castellum.OperationOutcome, error)
+}
+
 // AssetManagerRegistry is a pluggable.Registry for AssetManager implementations.
 var AssetManagerRegistry pluggable.Registry[AssetManager]
 
@@ -156,6 +233,9 @@ func CreateAssetManagers(ctx context.Context, pluginTypeIDs []string, provider P
 
 // ForAssetType returns the asset manager for the given asset type, or nil if
 // the asset type is not supported.
+//
+// When a resource is at hand, ForResource() should be used instead since its
+// AssetTypeInfo takes the configuration of the resource into account.
 func (team AssetManagerTeam) ForAssetType(assetType db.AssetType) (AssetManager, AssetTypeInfo) {
 	for _, manager := range team {
 		info, ok := manager.InfoForAssetType(assetType).Unpack()
@@ -169,3 +249,13 @@ func (team AssetManagerTeam) ForAssetType(assetType db.AssetType) (AssetManager,
 		UsageMetrics: []castellum.UsageMetric{castellum.SingularUsageMetric},
 	}
 }
+
+// ForResource is like ForAssetType, but returns the AssetTypeInfo that
+// applies to the given resource (see ResourceInfoProvider).
+func (team AssetManagerTeam) ForResource(res db.Resource) (AssetManager, AssetTypeInfo) {
+	manager, info := team.ForAssetType(res.AssetType)
+	if provider, ok := manager.(ResourceInfoProvider); ok {
+		info = provider.InfoForResource(info, res.ConfigJSON)
+	}
+	return manager, info
+}
@@ -7,8 +7,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"time"
 
-	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/regexpext"
 	. "go.xyrillian.de/gg/option"
 
@@ -18,7 +19,11 @@ import (
 // Config contains everything that we found in the configuration file.
 type Config struct {
 	MaxAssetSizeRules []MaxAssetSizeRule `json:"max_asset_sizes"`
+	RetentionRules    []RetentionRule    `json:"operation_retention"`
+	UsageHistoryRules []RetentionRule    `json:"usage_history_retention"`
+	ResizeRateLimits  []ResizeRateLimit  `json:"resize_rate_limits"`
 	ProjectSeeds      []ProjectSeed      `json:"project_seeds"`
+	DomainSeeds       []DomainSeed       `json:"domain_seeds"`
 }
 
 // LoadConfig loads the configuration file from the given path.
@@ -33,6 +38,24 @@ func LoadConfig(configPath string) (Config, error) {
 	if err != nil {
 		return Config{}, fmt.Errorf("could not parse %s: %w", configPath, err)
 	}
+	for idx, rule := range cfg.RetentionRules {
+		if rule.Days == 0 {
+			return Config{}, fmt.Errorf("invalid value for operation_retention[%d].days: must be greater than 0", idx)
+		}
+	}
+	for idx, rule := range cfg.UsageHistoryRules {
+		if rule.Days == 0 {
+			return Config{}, fmt.Errorf("invalid value for usage_history_retention[%d].days: must be greater than 0", idx)
+		}
+	}
+	for idx, limit := range cfg.ResizeRateLimits {
+		if !slices.Contains(allRateLimitScopes, limit.Per) {
+			return Config{}, fmt.Errorf("invalid value for resize_rate_limits[%d].per: %q", idx, limit.Per)
+		}
+		if limit.MaxConcurrent == 0 && limit.MaxPerHour == 0 {
+			return Config{}, fmt.Errorf("invalid value for resize_rate_limits[%d]: at least one of max_concurrent and max_per_hour must be greater than 0", idx)
+		}
+	}
 
 	return cfg, nil
 }
@@ -56,12 +79,85 @@ func (c Config) MaxAssetSizeFor(assetType db.AssetType, scopeUUID string) (resul
 	return
 }
 
-// ProjectSeed appears in type Seed.
+// RetentionRule appears in type Config.
+type RetentionRule struct {
+	AssetTypeRx regexpext.BoundedRegexp `json:"asset_type"`
+	Days        uint32                  `json:"days"`
+}
+
+// DefaultOperationRetention is how long finished operations are kept if no
+// RetentionRule applies.
+const DefaultOperationRetention = 14 * 24 * time.Hour
+
+// OperationRetentionFor computes how long finished operations for this asset
+// type shall be kept before being garbage-collected. If multiple rules match,
+// the last one wins.
+func (c Config) OperationRetentionFor(assetType db.AssetType) time.Duration {
+	result := DefaultOperationRetention
+	for _, rule := range c.RetentionRules {
+		if rule.AssetTypeRx.MatchString(string(assetType)) {
+			result = time.Duration(rule.Days) * 24 * time.Hour
+		}
+	}
+	return result
+}
+
+// UsageHistoryRetentionFor computes how long the usage history of assets of
+// this asset type shall be kept before being garbage-collected. If no rule
+// matches, None is returned and no usage history is recorded for this asset
+// type. If multiple rules match, the last one wins.
+func (c Config) UsageHistoryRetentionFor(assetType db.AssetType) (result Option[time.Duration]) {
+	for _, rule := range c.UsageHistoryRules {
+		if rule.AssetTypeRx.MatchString(string(assetType)) {
+			result = Some(time.Duration(rule.Days) * 24 * time.Hour)
+		}
+	}
+	return result
+}
+
+// ResizeRateLimit appears in type Config.
+type ResizeRateLimit struct {
+	AssetTypeRx   regexpext.BoundedRegexp `json:"asset_type"`
+	Per           RateLimitScope          `json:"per"`
+	MaxConcurrent uint32                  `json:"max_concurrent"`
+	MaxPerHour    uint32                  `json:"max_per_hour"`
+}
+
+// RateLimitScope appears in type ResizeRateLimit. It declares which resizes
+// are counted together when enforcing the limit.
+type RateLimitScope string
+
+const (
+	// RateLimitPerProject counts resizes per project (or per domain, for
+	// domain resources).
+	RateLimitPerProject RateLimitScope = "project"
+	// RateLimitPerAssetType counts resizes per asset type.
+	RateLimitPerAssetType RateLimitScope = "asset_type"
+	// RateLimitPerBackend counts resizes per AssetStatus.BackendKey. Assets
+	// without a BackendKey are not subject to such limits.
+	RateLimitPerBackend RateLimitScope = "backend"
+)
+
+var allRateLimitScopes = []RateLimitScope{RateLimitPerProject, RateLimitPerAssetType, RateLimitPerBackend}
+
+// ProjectSeed appears in type Config.
 type ProjectSeed struct {
-	ProjectName             string                              `json:"project_name"`
-	DomainName              string                              `json:"domain_name"`
-	Resources               map[db.AssetType]castellum.Resource `json:"resources"`
-	DisabledResourceRegexps []regexpext.BoundedRegexp           `json:"disabled_resources"`
+	ProjectName string `json:"project_name"`
+	DomainName  string `json:"domain_name"`
+	ResourceSeed
+}
+
+// DomainSeed appears in type Config.
+type DomainSeed struct {
+	DomainName string `json:"domain_name"`
+	ResourceSeed
+}
+
+// ResourceSeed contains the parts of ProjectSeed and DomainSeed that describe
+// which resources shall (or shall not) exist in the respective scope.
+type ResourceSeed struct {
+	Resources               map[db.AssetType]ResourceSpec `json:"resources"`
+	DisabledResourceRegexps []regexpext.BoundedRegexp     `json:"disabled_resources"`
 }
 
 // IsSeededResource returns true if the config contains a seed for this
@@ -76,13 +172,23 @@ func (c Config) IsSeededResource(project CachedProject, domain CachedDomain, ass
 	return false
 }
 
-func (s ProjectSeed) isSeededResource(assetType db.AssetType) bool {
+// IsSeededDomainResource is like IsSeededResource, but for domain resources.
+func (c Config) IsSeededDomainResource(domain CachedDomain, assetType db.AssetType) bool {
+	for _, s := range c.DomainSeeds {
+		if domain.Name == s.DomainName {
+			return s.isSeededResource(assetType)
+		}
+	}
+	return false
+}
+
+func (s ResourceSeed) isSeededResource(assetType db.AssetType) bool {
 	_, exists := s.Resources[assetType]
 	return exists || s.ForbidsResource(assetType)
 }
 
 // ForbidsResource returns whether any DisabledResourceRegexps match the given asset type.
-func (s ProjectSeed) ForbidsResource(assetType db.AssetType) bool {
+func (s ResourceSeed) ForbidsResource(assetType db.AssetType) bool {
 	for _, rx := range s.DisabledResourceRegexps {
 		if rx.MatchString(string(assetType)) {
 			return true
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-bits/logg"
+)
+
+var configLastReloadTimestampGauge = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "castellum_config_last_reload_timestamp_seconds",
+		Help: "UNIX timestamp of the last attempt to load the configuration file.",
+	},
+)
+
+var configLastReloadSuccessGauge = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "castellum_config_last_reload_successful",
+		Help: "Whether the last attempt to load the configuration file was successful (1) or not (0).",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(configLastReloadTimestampGauge, configLastReloadSuccessGauge)
+}
+
+// ConfigSource provides access to the current Config. When backed by a
+// configuration file, the Config can be replaced at runtime by reloading that
+// file. It is safe to use from multiple goroutines.
+type ConfigSource struct {
+	path    string
+	current atomic.Pointer[Config]
+	// modification time and size of the configuration file when it was last loaded
+	lastStat atomic.Pointer[os.FileInfo]
+}
+
+// NewConfigSource loads the configuration file from the given path.
+func NewConfigSource(configPath string) (*ConfigSource, error) {
+	s := &ConfigSource{path: configPath}
+	err := s.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// StaticConfigSource returns a ConfigSource that always provides the given
+// Config. This is used for tests and for one-shot tasks.
+func StaticConfigSource(cfg Config) *ConfigSource {
+	s := &ConfigSource{}
+	s.current.Store(&cfg)
+	return s
+}
+
+// Current returns the current Config. Callers that use the Config multiple
+// times within the same operation should call this once and keep the result
+// to work with a consistent view of the configuration.
+func (s *ConfigSource) Current() Config {
+	return *s.current.Load()
+}
+
+// Reload loads the configuration file again. If the file cannot be loaded or
+// contains an invalid configuration, an error is returned and the previous
+// Config stays in effect.
+func (s *ConfigSource) Reload() error {
+	if s.path == "" {
+		return nil // nothing to do for a StaticConfigSource
+	}
+
+	configLastReloadTimestampGauge.Set(float64(time.Now().Unix()))
+	fi, err := os.Stat(s.path)
+	if err == nil {
+		s.lastStat.Store(&fi)
+	}
+	cfg, err := LoadConfig(s.path)
+	if err != nil {
+		configLastReloadSuccessGauge.Set(0)
+		return err
+	}
+	s.current.Store(&cfg)
+	configLastReloadSuccessGauge.Set(1)
+	return nil
+}
+
+// WatchForChanges reloads the configuration file whenever SIGHUP is received
+// or when the file is found to have changed, until the given context expires.
+// The file is checked for changes at the given interval.
+func (s *ConfigSource) WatchForChanges(ctx context.Context, interval time.Duration) {
+	if s.path == "" {
+		return
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			s.reloadAndLog("received SIGHUP")
+		case <-ticker.C:
+			if s.hasChanged() {
+				s.reloadAndLog("configuration file has changed")
+			}
+		}
+	}
+}
+
+func (s *ConfigSource) reloadAndLog(reason string) {
+	err := s.Reload()
+	if err == nil {
+		logg.Info("reloaded configuration from %s (%s)", s.path, reason)
+	} else {
+		logg.Error("could not reload configuration from %s (%s), keeping previous configuration: %s", s.path, reason, err.Error())
+	}
+}
+
+// Checks whether the configuration file has changed since it was last loaded.
+// We compare the modification time and size instead of watching the file,
+// because configuration files that are mounted from a Kubernetes ConfigMap are
+// replaced by swapping symlinks, which does not generate events on the file
+// itself.
+func (s *ConfigSource) hasChanged() bool {
+	fi, err := os.Stat(s.path)
+	if err != nil {
+		return false // if the file went away, keep using what we have
+	}
+	lastStat := s.lastStat.Load()
+	if lastStat == nil {
+		return true
+	}
+	return !fi.ModTime().Equal((*lastStat).ModTime()) || fi.Size() != (*lastStat).Size()
+}
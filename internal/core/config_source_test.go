@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-bits/must"
+	"go.xyrillian.de/gg/assert"
+)
+
+func TestConfigSourceReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig := func(content string, mtime time.Time) {
+		t.Helper()
+		must.SucceedT(t, os.WriteFile(path, []byte(content), 0o644))
+		must.SucceedT(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	writeConfig(`{"operation_retention":[{"asset_type":"foo","days":1}]}`, time.Unix(1000, 0))
+	s := must.ReturnT(NewConfigSource(path))(t)
+	assert.Equal(t, s.Current().OperationRetentionFor("foo"), 24*time.Hour)
+	assert.Equal(t, s.hasChanged(), false)
+
+	// a valid change is picked up on reload
+	writeConfig(`{"operation_retention":[{"asset_type":"foo","days":2}]}`, time.Unix(2000, 0))
+	assert.Equal(t, s.hasChanged(), true)
+	must.SucceedT(t, s.Reload())
+	assert.Equal(t, s.Current().OperationRetentionFor("foo"), 48*time.Hour)
+	assert.Equal(t, s.hasChanged(), false)
+
+	// an invalid change is rejected, and the previous config stays in effect
+	writeConfig(`{"operation_retention":[{"asset_type":"foo","days":0}]}`, time.Unix(3000, 0))
+	assert.ErrEqual(t, s.Reload(), "invalid value for operation_retention[0].days: must be greater than 0")
+	assert.Equal(t, s.Current().OperationRetentionFor("foo"), 48*time.Hour)
+	// ...and is not retried until the file changes again
+	assert.Equal(t, s.hasChanged(), false)
+
+	// a StaticConfigSource never changes
+	s = StaticConfigSource(Config{})
+	must.SucceedT(t, s.Reload())
+	assert.Equal(t, s.Current().OperationRetentionFor("foo"), DefaultOperationRetention)
+}
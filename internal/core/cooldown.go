@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/errext"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// Cooldown appears in type ResourceSpec. Each field declares for how long
+// after a successful resize no new operation with the respective reason will
+// be created on the same asset.
+type Cooldown struct {
+	LowSeconds      uint32 `json:"low_seconds,omitempty"`
+	HighSeconds     uint32 `json:"high_seconds,omitempty"`
+	CriticalSeconds uint32 `json:"critical_seconds,omitempty"`
+	// If true, the cooldown only inhibits operations that resize in the
+	// opposite direction of the previous resize.
+	OppositeDirectionOnly bool `json:"opposite_direction_only,omitempty"`
+}
+
+// CooldownReasons lists all operation reasons that can have a cooldown.
+var CooldownReasons = []castellum.OperationReason{
+	castellum.OperationReasonLow,
+	castellum.OperationReasonHigh,
+	castellum.OperationReasonCritical,
+}
+
+// HasCooldown returns whether a cooldown is configured for any reason.
+func HasCooldown(res db.Resource) bool {
+	return res.LowCooldownSeconds > 0 || res.HighCooldownSeconds > 0 || res.CriticalCooldownSeconds > 0
+}
+
+// CooldownEndOf returns the time until which no new operation with the given
+// reason may be created on an asset, given the last operation that succeeded
+// on it. If no cooldown applies, None is returned.
+func CooldownEndOf(res db.Resource, reason castellum.OperationReason, lastResize db.FinishedOperation) Option[time.Time] {
+	var cooldownSeconds uint32
+	switch reason {
+	case castellum.OperationReasonLow:
+		cooldownSeconds = res.LowCooldownSeconds
+	case castellum.OperationReasonHigh:
+		cooldownSeconds = res.HighCooldownSeconds
+	case castellum.OperationReasonCritical:
+		cooldownSeconds = res.CriticalCooldownSeconds
+	}
+	if cooldownSeconds == 0 {
+		return None[time.Time]()
+	}
+
+	if res.CooldownOppositeDirectionOnly {
+		wasUpsize := lastResize.NewSize > lastResize.OldSize
+		isUpsize := reason != castellum.OperationReasonLow
+		if wasUpsize == isUpsize {
+			return None[time.Time]()
+		}
+	}
+	return Some(lastResize.FinishedAt.Add(time.Duration(cooldownSeconds) * time.Second))
+}
+
+// IsInCooldownFor returns whether CooldownEndOf() is after the given time.
+func IsInCooldownFor(res db.Resource, reason castellum.OperationReason, lastResize Option[db.FinishedOperation], now time.Time) bool {
+	op, ok := lastResize.Unpack()
+	if !ok {
+		return false
+	}
+	end, ok := CooldownEndOf(res, reason, op).Unpack()
+	return ok && end.After(now)
+}
+
+func applyCooldownSpecInto(res *db.Resource, spec ResourceSpec) (errs errext.ErrorSet) {
+	c, ok := spec.Cooldown.Unpack()
+	res.LowCooldownSeconds = c.LowSeconds
+	res.HighCooldownSeconds = c.HighSeconds
+	res.CriticalCooldownSeconds = c.CriticalSeconds
+	res.CooldownOppositeDirectionOnly = c.OppositeDirectionOnly
+	if ok && !HasCooldown(*res) {
+		errs.Addf("cooldown must be greater than 0 seconds for at least one reason")
+	}
+	return
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+func TestCooldown(t *testing.T) {
+	res := db.Resource{
+		LowCooldownSeconds:  3600,
+		HighCooldownSeconds: 600,
+	}
+	upsize := db.FinishedOperation{
+		Reason:     castellum.OperationReasonHigh,
+		Outcome:    castellum.OperationOutcomeSucceeded,
+		OldSize:    1000,
+		NewSize:    1200,
+		FinishedAt: time.Unix(10000, 0).UTC(),
+	}
+
+	assert.Equal(t, HasCooldown(res), true)
+	assert.Equal(t, HasCooldown(db.Resource{}), false)
+	assert.Equal(t, CooldownEndOf(res, castellum.OperationReasonLow, upsize), Some(time.Unix(13600, 0).UTC()))
+	assert.Equal(t, CooldownEndOf(res, castellum.OperationReasonHigh, upsize), Some(time.Unix(10600, 0).UTC()))
+	assert.Equal(t, CooldownEndOf(res, castellum.OperationReasonCritical, upsize), None[time.Time]())
+
+	assert.Equal(t, IsInCooldownFor(res, castellum.OperationReasonLow, Some(upsize), time.Unix(13599, 0)), true)
+	assert.Equal(t, IsInCooldownFor(res, castellum.OperationReasonLow, Some(upsize), time.Unix(13600, 0)), false)
+	assert.Equal(t, IsInCooldownFor(res, castellum.OperationReasonLow, None[db.FinishedOperation](), time.Unix(10000, 0)), false)
+
+	// when restricted to the opposite direction, the cooldown after an upsize
+	// only applies to downsizes
+	res.CooldownOppositeDirectionOnly = true
+	assert.Equal(t, CooldownEndOf(res, castellum.OperationReasonLow, upsize), Some(time.Unix(13600, 0).UTC()))
+	assert.Equal(t, CooldownEndOf(res, castellum.OperationReasonHigh, upsize), None[time.Time]())
+
+	downsize := upsize
+	downsize.Reason = castellum.OperationReasonLow
+	downsize.OldSize, downsize.NewSize = 1200, 1000
+	assert.Equal(t, CooldownEndOf(res, castellum.OperationReasonLow, downsize), None[time.Time]())
+	assert.Equal(t, CooldownEndOf(res, castellum.OperationReasonHigh, downsize), Some(time.Unix(10600, 0).UTC()))
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"math"
 	"slices"
+	"time"
 
 	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/errext"
 	"go.xyrillian.de/gg/is"
 	. "go.xyrillian.de/gg/option"
 	"go.xyrillian.de/gg/options"
@@ -63,17 +65,24 @@ type ResourceLogic struct {
 	MaximumSize           Option[uint64] `json:"max,omitzero"`
 	MinimumFreeSize       Option[uint64] `json:"minf,omitzero"`
 	MinimumFreeIsCritical bool           `json:"minfc,omitempty"`
+
+	PredictiveHorizonSeconds uint32 `json:"pred,omitempty"`
+
+	// Set by a db.AssetOverride to disable autoscaling for a single asset.
+	Disabled bool `json:"off,omitempty"`
 }
 
-// LogicOfResource converts a Resource into just its ResourceLogic.
-func LogicOfResource(res db.Resource, info AssetTypeInfo) ResourceLogic {
+// LogicOfResource converts a Resource into just its ResourceLogic. If an
+// AssetOverride is given, its values take precedence over those of the
+// Resource.
+func LogicOfResource(res db.Resource, info AssetTypeInfo, override Option[db.AssetOverride]) ResourceLogic {
 	if res.AssetType != info.AssetType {
 		panic(fmt.Sprintf(
 			"LogicOfResource called with mismatching arguments: res.AssetType = %q, but info.AssetType = %q",
 			res.AssetType, info.AssetType,
 		))
 	}
-	return ResourceLogic{
+	logic := ResourceLogic{
 		UsageMetrics:             info.UsageMetrics,
 		LowThresholdPercent:      res.LowThresholdPercent,
 		HighThresholdPercent:     res.HighThresholdPercent,
@@ -84,7 +93,18 @@ func LogicOfResource(res db.Resource, info AssetTypeInfo) ResourceLogic {
 		MaximumSize:              res.MaximumSize,
 		MinimumFreeSize:          res.MinimumFreeSize,
 		MinimumFreeIsCritical:    res.MinimumFreeIsCritical,
+		PredictiveHorizonSeconds: res.PredictiveHorizonSeconds,
 	}
+	if o, ok := override.Unpack(); ok {
+		logic.Disabled = o.Disabled
+		logic.LowThresholdPercent = o.LowThresholdPercent.UnwrapOr(logic.LowThresholdPercent)
+		logic.HighThresholdPercent = o.HighThresholdPercent.UnwrapOr(logic.HighThresholdPercent)
+		logic.CriticalThresholdPercent = o.CriticalThresholdPercent.UnwrapOr(logic.CriticalThresholdPercent)
+		logic.MinimumSize = o.MinimumSize.Or(logic.MinimumSize)
+		logic.MaximumSize = o.MaximumSize.Or(logic.MaximumSize)
+		logic.MinimumFreeSize = o.MinimumFreeSize.Or(logic.MinimumFreeSize)
+	}
+	return logic
 }
 
 // GetEligibleOperations calculates which resizing operations the given asset
@@ -92,6 +112,10 @@ func LogicOfResource(res db.Resource, info AssetTypeInfo) ResourceLogic {
 // pair means that the asset has crossed the threshold `key` and thus should be
 // resized to `value`.
 func GetEligibleOperations(res ResourceLogic, asset AssetStatus) map[castellum.OperationReason]uint64 {
+	if res.Disabled {
+		return nil
+	}
+
 	// never touch a zero-sized asset unless it has non-zero usage
 	if asset.Size == 0 && !asset.Usage.IsNonZero() {
 		// UNLESS we need to force it larger because of configuration
@@ -112,6 +136,35 @@ func GetEligibleOperations(res ResourceLogic, asset AssetStatus) map[castellum.O
 	return result
 }
 
+// SelectOperation chooses which of the operations returned by
+// GetEligibleOperations() shall be created. Critical operations take precedence
+// over high operations, which take precedence over low operations. If the asset
+// is not eligible for any operation, false is returned.
+func SelectOperation(eligibleFor map[castellum.OperationReason]uint64) (reason castellum.OperationReason, newSize uint64, ok bool) {
+	for _, reason := range []castellum.OperationReason{castellum.OperationReasonCritical, castellum.OperationReasonHigh, castellum.OperationReasonLow} {
+		if newSize, exists := eligibleFor[reason]; exists {
+			return reason, newSize, true
+		}
+	}
+	return "", 0, false
+}
+
+// EarliestConfirmationOf returns the earliest time at which an operation with
+// the given reason, created at the given time, can be confirmed. This is
+// controlled by the delay configured for the respective threshold.
+func EarliestConfirmationOf(res db.Resource, reason castellum.OperationReason, createdAt time.Time) time.Time {
+	switch reason {
+	case castellum.OperationReasonLow:
+		return createdAt.Add(time.Duration(res.LowDelaySeconds) * time.Second)
+	case castellum.OperationReasonHigh:
+		return createdAt.Add(time.Duration(res.HighDelaySeconds) * time.Second)
+	default:
+		// critical operations are confirmed immediately, and manual operations
+		// do not need to be confirmed at all
+		return createdAt
+	}
+}
+
 func checkReason(res ResourceLogic, asset AssetStatus, reason castellum.OperationReason) Option[uint64] {
 	// phase 1: generate global constraints
 	//
@@ -271,7 +324,10 @@ func checkReason(res ResourceLogic, asset AssetStatus, reason castellum.Operatio
 			}
 		}
 	}
-	if takeActionBecauseThreshold || takeActionBecauseEnforceableConstraint {
+	// with predictive upscaling, the high threshold is treated as crossed if
+	// usage is projected to reach the asset size soon
+	takeActionBecausePrediction := reason == castellum.OperationReasonHigh && isFullWithinHorizon(res, asset)
+	if takeActionBecauseThreshold || takeActionBecauseEnforceableConstraint || takeActionBecausePrediction {
 		if res.SingleStep {
 			for _, metric := range res.UsageMetrics {
 				a.addAction(getActionSingleStep(res, asset, metric, reason), *c)
@@ -279,6 +335,13 @@ func checkReason(res ResourceLogic, asset AssetStatus, reason castellum.Operatio
 		} else {
 			a.addAction(getActionPercentageStep(res, asset, reason), *c)
 		}
+		// leave room for the projected growth, so that the asset does not cross
+		// the high threshold again right away
+		if reason == castellum.OperationReasonHigh && res.PredictiveHorizonSeconds > 0 {
+			for _, metric := range res.UsageMetrics {
+				a.addAction(getActionPredictiveHeadroom(res, asset, metric), *c)
+			}
+		}
 	}
 
 	// phase 3: take the boldest action that satisfies the constraints,
@@ -442,3 +505,31 @@ func (as actions) max() Option[uint64] {
 	slices.Sort(as)
 	return Some(as[len(as)-1])
 }
+
+// CheckManualResize validates the target size of an operation with reason
+// db.OperationReasonManual against the constraints of the resource and asset.
+func CheckManualResize(res db.Resource, asset db.Asset, cfg Config, newSize uint64) (errs errext.ErrorSet) {
+	if newSize == 0 {
+		errs.Addf("new size must be greater than 0")
+	}
+	if newSize == asset.Size {
+		errs.Addf("new size must be different from the current size")
+	}
+
+	if minSize, ok := res.MinimumSize.Unpack(); ok && newSize < minSize {
+		errs.Addf("new size must be at least %d (minimum size of resource)", minSize)
+	}
+	if maxSize, ok := res.MaximumSize.Unpack(); ok && newSize > maxSize {
+		errs.Addf("new size must be at most %d (maximum size of resource)", maxSize)
+	}
+
+	// (this also covers the max_asset_sizes from the config)
+	status := StatusOfAsset(asset, cfg, res)
+	if minSize, ok := status.StrictMinimumSize.Unpack(); ok && newSize < minSize {
+		errs.Addf("new size must be at least %d (minimum size of asset)", minSize)
+	}
+	if maxSize, ok := status.StrictMaximumSize.Unpack(); ok && newSize > maxSize {
+		errs.Addf("new size must be at most %d (maximum size of asset)", maxSize)
+	}
+	return
+}
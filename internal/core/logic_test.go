@@ -14,6 +14,8 @@ import (
 	"github.com/sapcc/go-bits/logg"
 	"go.xyrillian.de/gg/assert"
 	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/db"
 )
 
 func TestGetEligibleOperations(t *testing.T) {
@@ -438,6 +440,36 @@ func TestGetEligibleOperations(t *testing.T) {
 	assert.Equal(t, eligibleOperationsToString(GetEligibleOperations(resLogic, assetStatus)), "critical->5")
 }
 
+func TestCheckManualResize(t *testing.T) {
+	cfg := Config{MaxAssetSizeRules: []MaxAssetSizeRule{{
+		AssetTypeRx: "foo",
+		Value:       5000,
+	}}}
+	res := db.Resource{
+		AssetType:   "foo",
+		MinimumSize: Some[uint64](200),
+		MaximumSize: Some[uint64](8000),
+	}
+	asset := db.Asset{
+		Size:              1000,
+		Usage:             singular(500),
+		StrictMinimumSize: Some[uint64](400),
+	}
+	check := func(newSize uint64, expected string) {
+		t.Helper()
+		assert.Equal(t, CheckManualResize(res, asset, cfg, newSize).Join(", "), expected)
+	}
+
+	check(2000, "")
+	check(400, "")
+	check(5000, "")
+	check(0, "new size must be greater than 0, new size must be at least 200 (minimum size of resource), new size must be at least 400 (minimum size of asset)")
+	check(1000, "new size must be different from the current size")
+	check(300, "new size must be at least 400 (minimum size of asset)")
+	check(6000, "new size must be at most 5000 (maximum size of asset)")
+	check(9000, "new size must be at most 8000 (maximum size of resource), new size must be at most 5000 (maximum size of asset)")
+}
+
 // Builds a ResourceLogic from a compact string representation like "low=20%, high=80%, step=single, min=200".
 func mustParseResourceLogic(t *testing.T, input string) (result ResourceLogic) {
 	t.Helper()
@@ -463,6 +495,8 @@ func mustParseResourceLogic(t *testing.T, input string) (result ResourceLogic) {
 			result.MinimumFreeSize = Some(mustParseUint64(t, parts[1]))
 		case "min_free_is_critical":
 			result.MinimumFreeIsCritical = mustParseBool(t, parts[1])
+		case "pred":
+			result.PredictiveHorizonSeconds = uint32(mustParseUint64(t, parts[1]))
 		default:
 			panic("unknown field in ResourceLogic string: " + parts[0])
 		}
@@ -485,6 +519,8 @@ func mustParseAssetStatus(t *testing.T, input string) (result AssetStatus) {
 			result.StrictMinimumSize = Some(mustParseUint64(t, parts[1]))
 		case "smax":
 			result.StrictMaximumSize = Some(mustParseUint64(t, parts[1]))
+		case "growth":
+			result.UsageGrowthPerSecond = singular(mustParseFloat(t, parts[1]))
 		default:
 			panic("unknown field in AssetStatus string: " + parts[0])
 		}
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/errext"
+	"github.com/sapcc/go-bits/logg"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// MaintenanceWindow appears in type ResourceSpec. If configured, confirmed
+// operations on assets of this resource will only be greenlit when the
+// maintenance window begins (or immediately, if they get confirmed during a
+// maintenance window).
+type MaintenanceWindow struct {
+	// Lowercase English names of the weekdays on which the window begins, e.g.
+	// "monday". If empty, the window begins on every day.
+	Weekdays []string `json:"weekdays,omitempty"`
+	// Begin and end of the window in the format "HH:MM". If End is before Start,
+	// the window extends over midnight into the following day.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Name of a timezone from the IANA time zone database, e.g. "Europe/Berlin".
+	// If empty, UTC is assumed.
+	Timezone string `json:"timezone,omitempty"`
+	// When true, operations with reason "critical" are greenlit immediately
+	// instead of waiting for the next maintenance window.
+	CriticalBypass bool `json:"critical_bypass,omitempty"`
+}
+
+// MaintenanceWindowOf deserializes the maintenance window of the given
+// resource, if any. Since the value in the DB could have been written by an
+// older version with laxer validation rules, it is validated again.
+func MaintenanceWindowOf(res db.Resource) (Option[MaintenanceWindow], error) {
+	if res.MaintenanceWindowJSON == "" {
+		return None[MaintenanceWindow](), nil
+	}
+	var mw MaintenanceWindow
+	err := json.Unmarshal([]byte(res.MaintenanceWindowJSON), &mw)
+	if err != nil {
+		return None[MaintenanceWindow](), fmt.Errorf("could not parse maintenance window of resource %d: %w", res.ID, err)
+	}
+	errs := mw.Validate()
+	if !errs.IsEmpty() {
+		return None[MaintenanceWindow](), fmt.Errorf("invalid maintenance window on resource %d: %s", res.ID, errs.Join(", "))
+	}
+	return Some(mw), nil
+}
+
+// GreenlightTimeOf computes the GreenlitAt value for an operation with the
+// given reason on an asset of the given resource, assuming that the operation
+// got confirmed (or is being reconsidered after its confirmation) at the given
+// time. None is returned when the operation needs to be greenlit by a user.
+func GreenlightTimeOf(res db.Resource, reason castellum.OperationReason, confirmedAt time.Time) Option[time.Time] {
+	// manual operations do not need approval since the user who requested them
+	// has the same permissions as the user who would greenlight them
+	if reason != db.OperationReasonManual && res.RequiresApproval(reason) {
+		// the operation stays in state "confirmed" until a user greenlights it
+		return None[time.Time]()
+	}
+
+	// a broken maintenance window is ignored instead of blocking all operations
+	// on this resource (it can only have been written by an older version of
+	// Castellum with laxer validation, so the user can just fix it)
+	mwOpt, err := MaintenanceWindowOf(res)
+	if err != nil {
+		logg.Error("ignoring maintenance window: %s", err.Error())
+	}
+	if mw, ok := mwOpt.Unpack(); ok {
+		// the operation may only be executed during the next maintenance window
+		return Some(mw.GreenlightTime(confirmedAt, reason))
+	}
+	return Some(confirmedAt)
+}
+
+// Validate returns errors for all fields in this MaintenanceWindow that have
+// invalid values.
+func (mw MaintenanceWindow) Validate() (errs errext.ErrorSet) {
+	for _, weekday := range mw.Weekdays {
+		if _, ok := parseWeekday(weekday); !ok {
+			errs.Addf("invalid weekday in maintenance window: %q", weekday)
+		}
+	}
+
+	start, startErr := parseTimeOfDay(mw.Start)
+	if startErr != nil {
+		errs.Addf("invalid start time for maintenance window: %s", startErr.Error())
+	}
+	end, endErr := parseTimeOfDay(mw.End)
+	if endErr != nil {
+		errs.Addf("invalid end time for maintenance window: %s", endErr.Error())
+	}
+	if startErr == nil && endErr == nil && start == end {
+		errs.Addf("maintenance window must have a nonzero duration")
+	}
+
+	_, err := time.LoadLocation(mw.Timezone)
+	if err != nil {
+		errs.Addf("invalid timezone for maintenance window: %s", err.Error())
+	}
+	return
+}
+
+// GreenlightTime computes when an operation with the given reason that was
+// confirmed at the given time shall be greenlit: either immediately if
+// confirmed during a maintenance window, or else at the start of the next
+// maintenance window.
+//
+// This assumes that Validate() has succeeded on this MaintenanceWindow.
+func (mw MaintenanceWindow) GreenlightTime(confirmedAt time.Time, reason castellum.OperationReason) time.Time {
+	if mw.CriticalBypass && reason == castellum.OperationReasonCritical {
+		return confirmedAt
+	}
+
+	loc, err := time.LoadLocation(mw.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, _ := parseTimeOfDay(mw.Start)
+	end, _ := parseTimeOfDay(mw.End)
+	endDayOffset := 0
+	if end <= start {
+		endDayOffset = 1
+	}
+
+	// check all windows that start between yesterday (since that window might
+	// extend past midnight into today) and one week from now; since windows are
+	// checked in chronological order, the first window that has not ended yet is
+	// the one we are looking for
+	t := confirmedAt.In(loc)
+	for dayOffset := -1; dayOffset <= 7; dayOffset++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+dayOffset, 0, 0, 0, 0, loc)
+		if !mw.beginsOn(day.Weekday()) {
+			continue
+		}
+		// (time.Date() normalizes the minute value, so this yields the correct
+		// wall clock time even on days with a DST transition; for the same reason,
+		// the end of the window is not computed by adding a fixed duration to its
+		// start since the night in between might be shorter or longer than usual)
+		windowStart := time.Date(day.Year(), day.Month(), day.Day(), 0, int(start/time.Minute), 0, 0, loc)
+		windowEnd := time.Date(day.Year(), day.Month(), day.Day()+endDayOffset, 0, int(end/time.Minute), 0, 0, loc)
+		if !t.Before(windowEnd) {
+			continue
+		}
+		if t.Before(windowStart) {
+			return windowStart.In(confirmedAt.Location())
+		}
+		return confirmedAt
+	}
+
+	// unreachable unless Weekdays only contains invalid values
+	return confirmedAt
+}
+
+func (mw MaintenanceWindow) beginsOn(weekday time.Weekday) bool {
+	if len(mw.Weekdays) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(mw.Weekdays, func(s string) bool {
+		wd, ok := parseWeekday(s)
+		return ok && wd == weekday
+	})
+}
+
+func parseWeekday(input string) (time.Weekday, bool) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if input == strings.ToLower(wd.String()) {
+			return wd, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// Parses a time of day in the format "HH:MM" into the offset since midnight.
+func parseTimeOfDay(input string) (time.Duration, error) {
+	t, err := time.Parse("15:04", input)
+	if err != nil {
+		return 0, fmt.Errorf("expected time in format HH:MM, but got %q", input)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+func TestMaintenanceWindowGreenlightTime(t *testing.T) {
+	check := func(mw MaintenanceWindow, confirmedAt, expected string, reason castellum.OperationReason) {
+		t.Helper()
+		actual := mw.GreenlightTime(mustParseRFC3339(t, confirmedAt), reason)
+		assert.Equal(t, actual.UTC().Format(time.RFC3339), expected)
+	}
+
+	// nightly window in UTC, extending over midnight
+	nightly := MaintenanceWindow{Start: "22:00", End: "04:00"}
+	assert.Equal(t, nightly.Validate().Join(", "), "")
+	// before the window -> wait for start of window
+	check(nightly, "2026-03-04T12:00:00Z", "2026-03-04T22:00:00Z", castellum.OperationReasonLow)
+	// during the window (on either side of midnight) -> greenlight immediately
+	check(nightly, "2026-03-04T23:00:00Z", "2026-03-04T23:00:00Z", castellum.OperationReasonLow)
+	check(nightly, "2026-03-05T03:59:00Z", "2026-03-05T03:59:00Z", castellum.OperationReasonLow)
+	// right when the window ends -> wait for the next night
+	check(nightly, "2026-03-05T04:00:00Z", "2026-03-05T22:00:00Z", castellum.OperationReasonLow)
+
+	// critical operations only bypass the window when configured
+	check(nightly, "2026-03-04T12:00:00Z", "2026-03-04T22:00:00Z", castellum.OperationReasonCritical)
+	nightly.CriticalBypass = true
+	check(nightly, "2026-03-04T12:00:00Z", "2026-03-04T12:00:00Z", castellum.OperationReasonCritical)
+	check(nightly, "2026-03-04T12:00:00Z", "2026-03-04T22:00:00Z", castellum.OperationReasonHigh)
+
+	// weekend window in a different timezone (2026-03-04 is a Wednesday)
+	weekend := MaintenanceWindow{
+		Weekdays: []string{"saturday", "sunday"},
+		Start:    "02:00",
+		End:      "06:00",
+		Timezone: "Europe/Berlin",
+	}
+	assert.Equal(t, weekend.Validate().Join(", "), "")
+	check(weekend, "2026-03-04T12:00:00Z", "2026-03-07T01:00:00Z", castellum.OperationReasonLow)
+	check(weekend, "2026-03-07T04:00:00Z", "2026-03-07T04:00:00Z", castellum.OperationReasonLow)
+	check(weekend, "2026-03-07T05:00:00Z", "2026-03-08T01:00:00Z", castellum.OperationReasonLow)
+	check(weekend, "2026-03-08T05:00:00Z", "2026-03-14T01:00:00Z", castellum.OperationReasonLow)
+	// after DST begins, the window starts at 02:00 local time = 00:00 UTC
+	check(weekend, "2026-04-01T12:00:00Z", "2026-04-04T00:00:00Z", castellum.OperationReasonLow)
+
+	// nightly window across a DST transition: on the night from 2026-10-24 to
+	// 2026-10-25, the window from 22:00 CEST to 04:00 CET lasts seven hours
+	// instead of six, so it ends at 03:00 UTC instead of 02:00 UTC
+	nightlyBerlin := MaintenanceWindow{Start: "22:00", End: "04:00", Timezone: "Europe/Berlin"}
+	assert.Equal(t, nightlyBerlin.Validate().Join(", "), "")
+	check(nightlyBerlin, "2026-10-24T12:00:00Z", "2026-10-24T20:00:00Z", castellum.OperationReasonLow)
+	check(nightlyBerlin, "2026-10-25T02:30:00Z", "2026-10-25T02:30:00Z", castellum.OperationReasonLow)
+	check(nightlyBerlin, "2026-10-25T03:00:00Z", "2026-10-25T21:00:00Z", castellum.OperationReasonLow)
+	// conversely, the night when DST begins is one hour shorter
+	check(nightlyBerlin, "2026-03-29T01:30:00Z", "2026-03-29T01:30:00Z", castellum.OperationReasonLow)
+	check(nightlyBerlin, "2026-03-29T02:00:00Z", "2026-03-29T20:00:00Z", castellum.OperationReasonLow)
+}
+
+func TestMaintenanceWindowValidation(t *testing.T) {
+	mw := MaintenanceWindow{
+		Weekdays: []string{"Monday", "funday"},
+		Start:    "25:00",
+		End:      "10",
+		Timezone: "Mars/Olympus_Mons",
+	}
+	errs := mw.Validate()
+	assert.Equal(t, len(errs), 5)
+
+	mw = MaintenanceWindow{Start: "10:00", End: "10:00"}
+	assert.Equal(t, mw.Validate().Join(", "), "maintenance window must have a nonzero duration")
+}
+
+func TestMaintenanceWindowOf(t *testing.T) {
+	mw, err := MaintenanceWindowOf(db.Resource{ID: 1})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, mw.IsNone(), true)
+
+	mw, err = MaintenanceWindowOf(db.Resource{ID: 1, MaintenanceWindowJSON: `{"start":"22:00","end":"04:00"}`})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, mw, Some(MaintenanceWindow{Start: "22:00", End: "04:00"}))
+
+	// values in the DB are validated again
+	_, err = MaintenanceWindowOf(db.Resource{ID: 1, MaintenanceWindowJSON: `{"start":"22:00","end":"22:00"}`})
+	assert.ErrEqual(t, err, "invalid maintenance window on resource 1: maintenance window must have a nonzero duration")
+}
+
+func mustParseRFC3339(t *testing.T, input string) time.Time {
+	t.Helper()
+	result, err := time.Parse(time.RFC3339, input)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return result
+}
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/pluggable"
+	"go.xyrillian.de/gg/gsql"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// NotificationPayload describes a state transition of an operation. This is
+// what notifiers deliver to their respective recipients. Until delivery, it
+// is stored in serialized form in the DB (see type db.Notification).
+type NotificationPayload struct {
+	// For domain resources, .ProjectUUID is empty.
+	ProjectUUID  string                    `json:"project_id,omitempty"`
+	DomainUUID   string                    `json:"domain_id"`
+	AssetType    db.AssetType              `json:"asset_type"`
+	AssetUUID    string                    `json:"asset_id"`
+	Reason       castellum.OperationReason `json:"reason"`
+	OldSize      uint64                    `json:"old_size"`
+	NewSize      uint64                    `json:"new_size"`
+	FromState    castellum.OperationState  `json:"from_state"`
+	ToState      castellum.OperationState  `json:"to_state"`
+	ErrorMessage string                    `json:"error_message,omitempty"`
+	// UNIX timestamp, for consistency with the API.
+	OccurredAt int64 `json:"occurred_at"`
+}
+
+// StateTransition describes an operation changing from one state into
+// another. It is given to NotifierTeam.RecordStateTransition().
+type StateTransition struct {
+	Resource     db.Resource
+	AssetUUID    string
+	Reason       castellum.OperationReason
+	OldSize      uint64
+	NewSize      uint64
+	ErrorMessage string
+	From         castellum.OperationState
+	To           castellum.OperationState
+}
+
+// TransitionOfPendingOperation builds a StateTransition for an operation that
+// is still pending after the transition.
+func TransitionOfPendingOperation(res db.Resource, assetUUID string, op db.PendingOperation, from, to castellum.OperationState) StateTransition {
+	return StateTransition{
+		Resource:  res,
+		AssetUUID: assetUUID,
+		Reason:    op.Reason,
+		OldSize:   op.OldSize,
+		NewSize:   op.NewSize,
+		From:      from,
+		To:        to,
+	}
+}
+
+// TransitionOfFinishedOperation builds a StateTransition for an operation that
+// has finished as a result of the transition.
+func TransitionOfFinishedOperation(res db.Resource, assetUUID string, op db.FinishedOperation, from castellum.OperationState) StateTransition {
+	return StateTransition{
+		Resource:     res,
+		AssetUUID:    assetUUID,
+		Reason:       op.Reason,
+		OldSize:      op.OldSize,
+		NewSize:      op.NewSize,
+		ErrorMessage: op.ErrorMessage,
+		From:         from,
+		To:           op.State(),
+	}
+}
+
+// Notifier is the modularization interface for delivering notifications about
+// state transitions of operations. It is created by CreateNotifiers() using
+// NotifierRegistry.
+type Notifier interface {
+	pluggable.Plugin
+
+	// Init is called before all other interface methods, and can be used by the
+	// Notifier to read its configuration and perform any first-time
+	// initialization.
+	Init(ctx context.Context, provider ProviderClient) error
+
+	// Whether this notifier wants to be notified about operations moving into
+	// the given state. Notifications are only enqueued if this returns true.
+	IsInterestedIn(state castellum.OperationState) bool
+
+	// Delivers the notification to its recipients. If an error is returned,
+	// delivery will be retried later (with backoff) by the worker.
+	SendNotification(ctx context.Context, payload NotificationPayload) error
+}
+
+// NotifierRegistry is a pluggable.Registry for Notifier implementations.
+var NotifierRegistry pluggable.Registry[Notifier]
+
+// NotifierTeam is the set of Notifier instances that Castellum is using.
+type NotifierTeam []Notifier
+
+// CreateNotifiers prepares a set of Notifier instances for a single run of
+// Castellum. The first argument is the list of IDs of all factories that shall
+// be used to create notifiers.
+func CreateNotifiers(ctx context.Context, pluginTypeIDs []string, provider ProviderClient) (NotifierTeam, error) {
+	team := make(NotifierTeam, len(pluginTypeIDs))
+	for idx, pluginTypeID := range pluginTypeIDs {
+		notifier := NotifierRegistry.Instantiate(pluginTypeID)
+		if notifier == nil {
+			return nil, fmt.Errorf("unknown notifier: %q", pluginTypeID)
+		}
+		err := notifier.Init(ctx, provider)
+		if err != nil {
+			return nil, fmt.Errorf("cannot initialize notifier %q: %s", pluginTypeID, err.Error())
+		}
+		team[idx] = notifier
+	}
+	return team, nil
+}
+
+// ForPluginTypeID returns the notifier with the given type, or nil if no such
+// notifier is in use.
+func (team NotifierTeam) ForPluginTypeID(pluginTypeID string) Notifier {
+	for _, notifier := range team {
+		if notifier.PluginTypeID() == pluginTypeID {
+			return notifier
+		}
+	}
+	return nil
+}
+
+// RecordStateTransition must be called whenever an operation changes to a
+// different state. It must be called within the same transaction that
+// persists the new state of the operation: Besides counting the transition
+// and logging it, it enqueues notifications for all interested notifiers.
+func (team NotifierTeam) RecordStateTransition(ctx context.Context, tx gsql.Handle, t StateTransition, now time.Time) error {
+	countStateTransition(t.Resource, t.AssetUUID, t.From, t.To)
+
+	var payloadJSON []byte
+	for _, notifier := range team {
+		if !notifier.IsInterestedIn(t.To) {
+			continue
+		}
+		if payloadJSON == nil {
+			var err error
+			payloadJSON, err = json.Marshal(t.payload(now))
+			if err != nil {
+				return err
+			}
+		}
+
+		n := db.Notification{
+			NotifierType:   notifier.PluginTypeID(),
+			PayloadJSON:    string(payloadJSON),
+			CreatedAt:      now,
+			NextDeliveryAt: now,
+		}
+		err := db.NotificationStore.Insert(ctx, tx, &n)
+		if err != nil {
+			return fmt.Errorf("while enqueuing notification for %s: %w", n.NotifierType, err)
+		}
+	}
+	return nil
+}
+
+func (t StateTransition) payload(now time.Time) NotificationPayload {
+	payload := NotificationPayload{
+		DomainUUID:   t.Resource.DomainUUID,
+		AssetType:    t.Resource.AssetType,
+		AssetUUID:    t.AssetUUID,
+		Reason:       t.Reason,
+		OldSize:      t.OldSize,
+		NewSize:      t.NewSize,
+		FromState:    t.From,
+		ToState:      t.To,
+		ErrorMessage: t.ErrorMessage,
+		OccurredAt:   now.Unix(),
+	}
+	if t.Resource.ScopeType() == db.ProjectScope {
+		payload.ProjectUUID = t.Resource.ScopeUUID
+	}
+	return payload
+}
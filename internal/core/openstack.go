@@ -40,6 +40,9 @@ type ProviderClient interface {
 	// FindProjectID searches for a project with the given name and domain name.
 	// When the project does not exist, "" is returned instead of an error.
 	FindProjectID(ctx context.Context, projectName, projectDomainName string) (string, error)
+	// FindDomainID searches for a domain with the given name.
+	// When the domain does not exist, "" is returned instead of an error.
+	FindDomainID(ctx context.Context, domainName string) (string, error)
 }
 
 // providerClientImpl is the implementation for the ProviderClient interface.
@@ -312,6 +315,15 @@ func (p *providerClientImpl) FindProjectID(ctx context.Context, projectName, pro
 	}
 }
 
+// FindDomainID implements the ProviderClient interface.
+func (p *providerClientImpl) FindDomainID(ctx context.Context, domainName string) (string, error) {
+	identityV3, err := p.CloudAdminClient(openstack.NewIdentityV3)
+	if err != nil {
+		return "", err
+	}
+	return p.findDomainID(ctx, identityV3, domainName)
+}
+
 func (p *providerClientImpl) findDomainID(ctx context.Context, identityV3 *gophercloud.ServiceClient, domainName string) (string, error) {
 	allPages, err := domains.List(identityV3, domains.ListOpts{Name: domainName}).AllPages(ctx)
 	if err != nil {
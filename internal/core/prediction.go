@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/errext"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// PredictiveScaling appears in type ResourceSpec. If configured, "high"
+// operations are created ahead of time when the recent usage trend projects an
+// asset to fill up within the configured horizon.
+type PredictiveScaling struct {
+	HorizonSeconds uint32 `json:"horizon_seconds"`
+}
+
+const (
+	// UsageTrendLookback is how far back usage samples are considered when
+	// estimating the usage trend of an asset. Older samples can be discarded.
+	UsageTrendLookback = 2 * time.Hour
+	// The minimum number of usage samples required to estimate a usage trend.
+	minUsageTrendSamples = 3
+)
+
+// EstimateUsageGrowth fits a linear trend through the given usage samples (by
+// least squares) and returns the growth rate of absolute usage per second for
+// each metric. If there are not enough samples for a meaningful estimate, nil
+// is returned.
+func EstimateUsageGrowth(samples []db.AssetUsageSample, metrics []castellum.UsageMetric) castellum.UsageValues {
+	if len(samples) < minUsageTrendSamples {
+		return nil
+	}
+
+	// use seconds relative to the first sample to keep the numbers small
+	n := float64(len(samples))
+	ts := make([]float64, len(samples))
+	var tMean float64
+	for idx, sample := range samples {
+		ts[idx] = sample.RecordedAt.Sub(samples[0].RecordedAt).Seconds()
+		tMean += ts[idx] / n
+	}
+	var tVariance float64
+	for _, t := range ts {
+		tVariance += (t - tMean) * (t - tMean)
+	}
+	if tVariance == 0 {
+		return nil
+	}
+
+	result := make(castellum.UsageValues, len(metrics))
+	for _, metric := range metrics {
+		var uMean float64
+		for _, sample := range samples {
+			uMean += sample.Usage[metric] / n
+		}
+		var covariance float64
+		for idx, sample := range samples {
+			covariance += (ts[idx] - tMean) * (sample.Usage[metric] - uMean)
+		}
+		result[metric] = covariance / tVariance
+	}
+	return result
+}
+
+// Checks whether a "high" operation shall be created ahead of time because
+// the usage of the asset is projected to reach its size within the horizon.
+func isFullWithinHorizon(res ResourceLogic, asset AssetStatus) bool {
+	if res.PredictiveHorizonSeconds == 0 {
+		return false
+	}
+	for _, metric := range res.UsageMetrics {
+		growth := asset.UsageGrowthPerSecond[metric]
+		if growth <= 0 || res.HighThresholdPercent[metric] == 0 {
+			continue
+		}
+		secondsUntilFull := (float64(asset.Size) - asset.Usage[metric]) / growth
+		if secondsUntilFull < float64(res.PredictiveHorizonSeconds) {
+			return true
+		}
+	}
+	return false
+}
+
+// Computes a target size for a "high" operation that leaves enough headroom
+// for the usage growth that is projected within the horizon, such that the
+// projected usage stays below the high threshold.
+func getActionPredictiveHeadroom(res ResourceLogic, asset AssetStatus, metric castellum.UsageMetric) action {
+	growth := asset.UsageGrowthPerSecond[metric]
+	thresholdPerc := res.HighThresholdPercent[metric]
+	if growth <= 0 || thresholdPerc == 0 {
+		return action{Desired: asset.Size, Min: Some(asset.Size)}
+	}
+	projectedUsage := asset.Usage[metric] + growth*float64(res.PredictiveHorizonSeconds)
+	newSize := uint64(math.Ceil(100 * projectedUsage / (thresholdPerc - 0.0001)))
+	return action{Desired: newSize, Min: Some(asset.Size)}
+}
+
+func applyPredictiveScalingSpecInto(res *db.Resource, spec ResourceSpec) (errs errext.ErrorSet) {
+	ps, ok := spec.PredictiveScaling.Unpack()
+	if !ok {
+		res.PredictiveHorizonSeconds = 0
+		return
+	}
+
+	res.PredictiveHorizonSeconds = ps.HorizonSeconds
+	if res.PredictiveHorizonSeconds == 0 {
+		errs.Addf("horizon for predictive upscaling must be greater than 0 seconds")
+	}
+	if spec.HighThreshold.IsNone() {
+		errs.Addf("predictive upscaling requires a high threshold")
+	}
+	return
+}
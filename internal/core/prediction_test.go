@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+func TestEstimateUsageGrowth(t *testing.T) {
+	metrics := []castellum.UsageMetric{castellum.SingularUsageMetric}
+	start := time.Unix(3600, 0).UTC()
+	sample := func(offsetSecs int, usage float64) db.AssetUsageSample {
+		return db.AssetUsageSample{
+			RecordedAt: start.Add(time.Duration(offsetSecs) * time.Second),
+			Size:       1000,
+			Usage:      singular(usage),
+		}
+	}
+
+	// not enough samples for an estimate
+	assert.Equal(t, EstimateUsageGrowth(nil, metrics), nil)
+	assert.Equal(t, EstimateUsageGrowth([]db.AssetUsageSample{sample(0, 100), sample(300, 130)}, metrics), nil)
+	assert.Equal(t, EstimateUsageGrowth([]db.AssetUsageSample{sample(0, 100), sample(0, 130), sample(0, 160)}, metrics), nil)
+
+	// perfectly linear growth
+	samples := []db.AssetUsageSample{sample(0, 100), sample(300, 130), sample(600, 160)}
+	assert.Equal(t, EstimateUsageGrowth(samples, metrics), singular(0.1))
+
+	// noisy growth is smoothed out, and shrinking usage yields a negative trend
+	samples = []db.AssetUsageSample{sample(0, 100), sample(100, 90), sample(200, 90), sample(300, 70)}
+	assert.Equal(t, EstimateUsageGrowth(samples, metrics), singular(-0.09))
+}
+
+func TestPredictiveUpscaling(t *testing.T) {
+	check := func(resourceStr, assetStr, expected string) {
+		t.Helper()
+		res := mustParseResourceLogic(t, resourceStr)
+		asset := mustParseAssetStatus(t, assetStr)
+		assert.Equal(t, eligibleOperationsToString(GetEligibleOperations(res, asset)), expected)
+	}
+
+	// without predictive upscaling, the usage trend is ignored
+	check("high=80%, step=20%", "size=1000, usage=500, growth=0.2", "")
+
+	// usage is projected to reach the size after 5000 seconds, which is outside the horizon
+	check("high=80%, step=20%, pred=3600", "size=1000, usage=500, growth=0.1", "")
+	// usage is projected to reach the size after 2500 seconds, which is inside the horizon;
+	// the target size leaves room for the projected usage of 500 + 0.2 * 3600 = 1220
+	check("high=80%, step=20%, pred=3600", "size=1000, usage=500, growth=0.2", "high->1526")
+	// when the growth is slow, the regular step size wins
+	check("high=80%, step=20%, pred=3600", "size=1000, usage=850, growth=0.01", "high->1200")
+	// when the high threshold is crossed anyway, headroom is still taken into account
+	check("high=80%, step=20%, pred=3600", "size=1000, usage=850, growth=0.2", "high->1963")
+	// shrinking usage does not trigger anything
+	check("high=80%, step=20%, pred=3600", "size=1000, usage=500, growth=-0.2", "")
+	// predictive upscaling is bound by the usual constraints
+	check("high=80%, step=20%, pred=3600, max=1300", "size=1000, usage=500, growth=0.2", "high->1300")
+	// critical operations are unaffected
+	check("high=80%, crit=95%, step=20%, pred=3600", "size=1000, usage=960, growth=0.2", "critical->1200")
+}
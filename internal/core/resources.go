@@ -15,6 +15,26 @@ import (
 	"github.com/sapcc/castellum/internal/db"
 )
 
+// ResourceSpec is the representation of a resource in the API and in project
+// seeds. It extends castellum.Resource with attributes that are not covered by
+// the shared API declarations.
+type ResourceSpec struct {
+	castellum.Resource
+	ApprovalRequired  Option[ApprovalRequirements] `json:"approval_required,omitzero"`
+	MaintenanceWindow Option[MaintenanceWindow]    `json:"maintenance_window,omitzero"`
+	AssetExclusions   Option[AssetExclusions]      `json:"exclusions,omitzero"`
+	PredictiveScaling Option[PredictiveScaling]    `json:"predictive,omitzero"`
+	Cooldown          Option[Cooldown]             `json:"cooldown,omitzero"`
+}
+
+// ApprovalRequirements appears in type ResourceSpec. Each field declares
+// whether operations with the respective reason need to be greenlit by a user.
+type ApprovalRequirements struct {
+	Low      bool `json:"low,omitempty"`
+	High     bool `json:"high,omitempty"`
+	Critical bool `json:"critical,omitempty"`
+}
+
 // ApplyResourceSpecInto validates the configuration in the given resource
 // specification (which can either come from the API or from a seed file) and
 // applies it in-place into the given db.Resource record.
@@ -25,14 +45,13 @@ import (
 //
 // For new resources, a fresh `res` shall be given that shall only be filled
 // with an AssetType and ScopeUUID.
-func ApplyResourceSpecInto(ctx context.Context, res *db.Resource, spec castellum.Resource, existingResources map[db.AssetType]struct{}, cfg Config, team AssetManagerTeam) (errs errext.ErrorSet) {
-	manager, info := team.ForAssetType(res.AssetType)
+func ApplyResourceSpecInto(ctx context.Context, res *db.Resource, spec ResourceSpec, existingResources map[db.AssetType]struct{}, cfg Config, team AssetManagerTeam) (errs errext.ErrorSet) {
+	res.ConfigJSON = string(spec.ConfigJSON.UnwrapOr(json.RawMessage("")))
+	manager, info := team.ForResource(*res)
 	if manager == nil {
 		errs.Addf("unsupported asset type")
 		return
 	}
-
-	res.ConfigJSON = string(spec.ConfigJSON.UnwrapOr(json.RawMessage("")))
 	errs.Add(manager.CheckResourceAllowed(ctx, res.AssetType, res.ScopeUUID, res.ConfigJSON, existingResources))
 
 	if spec.Checked.IsSome() {
@@ -42,10 +61,15 @@ func ApplyResourceSpecInto(ctx context.Context, res *db.Resource, spec castellum
 		errs.Addf("resource.asset_count cannot be set via the API")
 	}
 
-	errs.Append(applyThresholdSpecsInto(res, spec, info))
-	errs.Append(checkIntraThresholdConsistency(res, spec, info))
-	errs.Append(applySteppingSpecInto(res, spec))
-	errs.Append(applySizeConstraintsSpecInto(res, spec, cfg.MaxAssetSizeFor(res.AssetType, res.ScopeUUID)))
+	errs.Append(applyThresholdSpecsInto(res, spec.Resource, info))
+	errs.Append(checkIntraThresholdConsistency(res, spec.Resource, info))
+	errs.Append(applySteppingSpecInto(res, spec.Resource))
+	errs.Append(applySizeConstraintsSpecInto(res, spec.Resource, cfg.MaxAssetSizeFor(res.AssetType, res.ScopeUUID)))
+	applyApprovalSpecInto(res, spec)
+	errs.Append(applyMaintenanceWindowSpecInto(res, spec))
+	errs.Append(applyAssetExclusionsSpecInto(res, spec, manager))
+	errs.Append(applyPredictiveScalingSpecInto(res, spec))
+	errs.Append(applyCooldownSpecInto(res, spec))
 	return
 }
 
@@ -195,3 +219,26 @@ func applySizeConstraintsSpecInto(res *db.Resource, spec castellum.Resource, max
 
 	return
 }
+
+func applyApprovalSpecInto(res *db.Resource, spec ResourceSpec) {
+	ar := spec.ApprovalRequired.UnwrapOr(ApprovalRequirements{})
+	res.LowRequiresApproval = ar.Low
+	res.HighRequiresApproval = ar.High
+	res.CriticalRequiresApproval = ar.Critical
+}
+
+func applyMaintenanceWindowSpecInto(res *db.Resource, spec ResourceSpec) (errs errext.ErrorSet) {
+	mw, ok := spec.MaintenanceWindow.Unpack()
+	if !ok {
+		res.MaintenanceWindowJSON = ""
+		return
+	}
+
+	errs.Append(mw.Validate())
+	buf, err := json.Marshal(mw)
+	if err != nil {
+		errs.Add(err)
+	}
+	res.MaintenanceWindowJSON = string(buf)
+	return
+}
@@ -23,9 +23,8 @@ func init() {
 	prometheus.MustRegister(opStateTransitionCounter)
 }
 
-// CountStateTransition must be called whenever an operation changes to a
-// different state.
-func CountStateTransition(res db.Resource, assetUUID string, from, to castellum.OperationState) {
+// countStateTransition is called by NotifierTeam.RecordStateTransition().
+func countStateTransition(res db.Resource, assetUUID string, from, to castellum.OperationState) {
 	labels := prometheus.Labels{
 		"project_id": res.ScopeUUID,
 		"asset":      string(res.AssetType),
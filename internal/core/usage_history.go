@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"time"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// UsageHistoryResolution is the granularity of the usage history of assets.
+// At most one usage sample is kept per asset and interval of this length.
+const UsageHistoryResolution = 10 * time.Minute
+
+// UsageSampleTimeFor returns the timestamp under which an observation of
+// size and usage at the given time is stored in the usage history. All
+// observations within the same interval of length UsageHistoryResolution map
+// to the same timestamp, such that the latest observation wins.
+func UsageSampleTimeFor(t time.Time) time.Time {
+	return truncateToStep(t, UsageHistoryResolution)
+}
+
+// DownsampleUsageHistory reduces the given usage samples (which must be
+// ordered by RecordedAt) to at most one sample per interval of the given
+// length. Within each interval, the latest sample is retained, but its
+// timestamp is moved to the start of the interval.
+func DownsampleUsageHistory(samples []db.AssetUsageSample, step time.Duration) []db.AssetUsageSample {
+	var result []db.AssetUsageSample
+	for _, sample := range samples {
+		sample.RecordedAt = truncateToStep(sample.RecordedAt, step)
+		if len(result) > 0 && result[len(result)-1].RecordedAt.Equal(sample.RecordedAt) {
+			result[len(result)-1] = sample
+		} else {
+			result = append(result, sample)
+		}
+	}
+	return result
+}
+
+// Like time.Time.Truncate, but aligned to the UNIX epoch instead of to the
+// zero time, so that the resulting timestamps look reasonable for any step.
+func truncateToStep(t time.Time, step time.Duration) time.Time {
+	stepSecs := int64(step / time.Second)
+	unix := t.Unix()
+	return time.Unix(unix-unix%stepSecs, 0).UTC()
+}
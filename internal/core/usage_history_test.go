@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+func TestUsageSampleTimeFor(t *testing.T) {
+	assert.Equal(t, UsageSampleTimeFor(time.Unix(3600, 0)), time.Unix(3600, 0).UTC())
+	assert.Equal(t, UsageSampleTimeFor(time.Unix(4199, 0)), time.Unix(3600, 0).UTC())
+	assert.Equal(t, UsageSampleTimeFor(time.Unix(4200, 0)), time.Unix(4200, 0).UTC())
+}
+
+func TestDownsampleUsageHistory(t *testing.T) {
+	sample := func(timestamp int64, usage float64) db.AssetUsageSample {
+		return db.AssetUsageSample{
+			AssetID:    1,
+			RecordedAt: time.Unix(timestamp, 0).UTC(),
+			Size:       1000,
+			Usage:      castellum.UsageValues{castellum.SingularUsageMetric: usage},
+		}
+	}
+	samples := []db.AssetUsageSample{
+		sample(0, 100),
+		sample(600, 200),
+		sample(1200, 300),
+		sample(1800, 400),
+		// a gap in the history does not produce any samples
+		sample(6000, 500),
+	}
+
+	// with the native resolution, nothing changes
+	assert.Equal(t, DownsampleUsageHistory(samples, UsageHistoryResolution), samples)
+
+	// with a larger step, the latest sample in each interval wins
+	assert.Equal(t, DownsampleUsageHistory(samples, time.Hour), []db.AssetUsageSample{
+		sample(0, 400),
+		sample(3600, 500),
+	})
+	assert.Equal(t, DownsampleUsageHistory(samples, 20*time.Minute), []db.AssetUsageSample{
+		sample(0, 200),
+		sample(1200, 400),
+		sample(6000, 500),
+	})
+
+	assert.Equal(t, len(DownsampleUsageHistory(nil, time.Hour)), 0)
+}
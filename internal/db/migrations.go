@@ -97,4 +97,87 @@ var sqlMigrations = map[int64]string{
 		ALTER TABLE assets RENAME min_size TO strict_min_size;
 		ALTER TABLE assets RENAME max_size TO strict_max_size;
 	`,
+	26: `
+		ALTER TABLE resources
+			ADD COLUMN low_requires_approval BOOLEAN NOT NULL DEFAULT FALSE,
+			ADD COLUMN high_requires_approval BOOLEAN NOT NULL DEFAULT FALSE,
+			ADD COLUMN critical_requires_approval BOOLEAN NOT NULL DEFAULT FALSE;
+	`,
+	27: `
+		ALTER TABLE resources
+			ADD COLUMN maintenance_window_json TEXT NOT NULL DEFAULT '';
+	`,
+	28: `
+		-- this value is deliberately sorted last: the resize worker processes
+		-- operations in "ORDER BY reason" order, and manual operations (which are
+		-- requested ahead of time by a user) shall not delay automatic operations
+		-- that were triggered by an asset crossing a threshold
+		ALTER TYPE op_reason ADD VALUE 'manual';
+	`,
+	29: `
+		ALTER TABLE finished_operations
+			ADD COLUMN cancelled_by_user_uuid TEXT DEFAULT NULL;
+		ALTER TABLE assets
+			ADD COLUMN snoozed_reason op_reason DEFAULT NULL,
+			ADD COLUMN snoozed_until TIMESTAMP DEFAULT NULL;
+	`,
+	30: `
+		CREATE TABLE notifications (
+			id                BIGSERIAL  NOT NULL PRIMARY KEY,
+			notifier_type     TEXT       NOT NULL,
+			payload_json      TEXT       NOT NULL,
+			created_at        TIMESTAMP  NOT NULL,
+			next_delivery_at  TIMESTAMP  NOT NULL,
+			failed_attempts   INT        NOT NULL DEFAULT 0,
+			error_message     TEXT       NOT NULL DEFAULT ''
+		);
+		CREATE INDEX ON notifications (next_delivery_at);
+	`,
+	31: `
+		CREATE TABLE asset_overrides (
+			asset_id                    BIGINT   NOT NULL PRIMARY KEY REFERENCES assets ON DELETE CASCADE,
+			disabled                    BOOLEAN  NOT NULL DEFAULT FALSE,
+			low_threshold_percent       TEXT     DEFAULT NULL,
+			high_threshold_percent      TEXT     DEFAULT NULL,
+			critical_threshold_percent  TEXT     DEFAULT NULL,
+			min_size                    BIGINT   DEFAULT NULL,
+			max_size                    BIGINT   DEFAULT NULL,
+			min_free_size               BIGINT   DEFAULT NULL
+		);
+	`,
+	32: `
+		ALTER TABLE resources
+			ADD COLUMN exclusions_json TEXT NOT NULL DEFAULT '';
+		ALTER TABLE assets
+			ADD COLUMN exclusion_reason TEXT NOT NULL DEFAULT '';
+	`,
+	33: `
+		ALTER TABLE resources
+			ADD COLUMN predictive_horizon_secs INT NOT NULL DEFAULT 0;
+		CREATE TABLE asset_usage_samples (
+			asset_id     BIGINT     NOT NULL REFERENCES assets ON DELETE CASCADE,
+			recorded_at  TIMESTAMP  NOT NULL,
+			size         BIGINT     NOT NULL,
+			usage        TEXT       NOT NULL,
+			PRIMARY KEY (asset_id, recorded_at)
+		);
+	`,
+	34: `
+		ALTER TABLE resources
+			ADD COLUMN low_cooldown_seconds INT NOT NULL DEFAULT 0,
+			ADD COLUMN high_cooldown_seconds INT NOT NULL DEFAULT 0,
+			ADD COLUMN critical_cooldown_seconds INT NOT NULL DEFAULT 0,
+			ADD COLUMN cooldown_opposite_direction_only BOOLEAN NOT NULL DEFAULT FALSE;
+	`,
+	35: `
+		ALTER TABLE assets
+			ADD COLUMN backend_key TEXT NOT NULL DEFAULT '';
+	`,
+	36: `
+		ALTER TABLE assets
+			ADD COLUMN maintenance_status TEXT NOT NULL DEFAULT '';
+	`,
+	37: `
+		ALTER TYPE op_reason ADD VALUE 'repair';
+	`,
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/sapcc/go-api-declarations/castellum"
+	"go.xyrillian.de/gg/is"
 	. "go.xyrillian.de/gg/option"
 	"go.xyrillian.de/gg/pgruntime"
 	"go.xyrillian.de/oblast"
@@ -49,6 +50,34 @@ type Resource struct {
 	HighDelaySeconds         uint32                `db:"high_delay_seconds"`
 	CriticalThresholdPercent castellum.UsageValues `db:"critical_threshold_percent"`
 
+	// When true, operations with the respective reason will remain in state
+	// "confirmed" until a user greenlights them.
+	LowRequiresApproval      bool `db:"low_requires_approval"`
+	HighRequiresApproval     bool `db:"high_requires_approval"`
+	CriticalRequiresApproval bool `db:"critical_requires_approval"`
+
+	// After an operation on an asset has succeeded, no new operations with the
+	// respective reason will be created on it for this many seconds. If
+	// CooldownOppositeDirectionOnly is true, this only applies to operations
+	// that resize in the opposite direction of the previous operation.
+	LowCooldownSeconds            uint32 `db:"low_cooldown_seconds"`
+	HighCooldownSeconds           uint32 `db:"high_cooldown_seconds"`
+	CriticalCooldownSeconds       uint32 `db:"critical_cooldown_seconds"`
+	CooldownOppositeDirectionOnly bool   `db:"cooldown_opposite_direction_only"`
+
+	// (optional) If set, confirmed operations will only be greenlit during the
+	// maintenance window described herein (a serialized core.MaintenanceWindow).
+	MaintenanceWindowJSON string `db:"maintenance_window_json"`
+
+	// (optional) Rules for excluding assets of this resource from autoscaling
+	// (a serialized core.AssetExclusions).
+	ExclusionsJSON string `db:"exclusions_json"`
+
+	// If non-zero, usage samples are recorded for each asset, and "high"
+	// operations are created ahead of time when the usage trend projects the
+	// asset to fill up within this many seconds.
+	PredictiveHorizonSeconds uint32 `db:"predictive_horizon_secs"`
+
 	// This defines how much the asset's size changes per
 	// downscaling/upscaling operation (in % of previous size).
 	SizeStepPercent float64 `db:"size_step_percent"`
@@ -75,25 +104,58 @@ type Resource struct {
 	ScrapeDurationSecs float64 `db:"scrape_duration_secs"`
 }
 
+// RequiresApproval returns whether operations with the given reason must be
+// greenlit by a user before they can be executed.
+func (r Resource) RequiresApproval(reason castellum.OperationReason) bool {
+	switch reason {
+	case castellum.OperationReasonLow:
+		return r.LowRequiresApproval
+	case castellum.OperationReasonHigh:
+		return r.HighRequiresApproval
+	case castellum.OperationReasonCritical:
+		return r.CriticalRequiresApproval
+	default:
+		return false
+	}
+}
+
 // AssetType is the type of Resource.AssetType. It extends type string with some
 // convenience methods.
 type AssetType string
 
 // PolicyRuleForRead returns the name of the policy rule that allows read access
 // to this resource.
-func (a AssetType) PolicyRuleForRead() string {
+func (a AssetType) PolicyRuleForRead(scopeType ScopeType) string {
 	// only consider the asset type up to the first colon, e.g.
 	//  assetType = "quota:compute:instances"
 	//  -> result = "project:show:quota"
 	assetTypeFields := strings.SplitN(string(a), ":", 2)
-	return "project:show:" + assetTypeFields[0]
+	return string(scopeType) + ":show:" + assetTypeFields[0]
 }
 
 // PolicyRuleForWrite returns the name of the policy rule that allows write
 // access to this resource.
-func (a AssetType) PolicyRuleForWrite() string {
+func (a AssetType) PolicyRuleForWrite(scopeType ScopeType) string {
 	assetTypeFields := strings.SplitN(string(a), ":", 2)
-	return "project:edit:" + assetTypeFields[0]
+	return string(scopeType) + ":edit:" + assetTypeFields[0]
+}
+
+// ScopeType is the type of Resource.ScopeType().
+type ScopeType string
+
+const (
+	// ProjectScope is the ScopeType of resources belonging to a project.
+	ProjectScope ScopeType = "project"
+	// DomainScope is the ScopeType of resources belonging to a domain.
+	DomainScope ScopeType = "domain"
+)
+
+// ScopeType returns whether this resource belongs to a project or a domain.
+func (r Resource) ScopeType() ScopeType {
+	if r.ScopeUUID == r.DomainUUID {
+		return DomainScope
+	}
+	return ProjectScope
 }
 
 // AssetStore provides structured access to the database table "assets".
@@ -139,6 +201,10 @@ type Asset struct {
 	StrictMinimumSize Option[uint64] `db:"strict_min_size"`
 	StrictMaximumSize Option[uint64] `db:"strict_max_size"`
 
+	// (optional) Identifies the backend that hosts this asset, as reported by
+	// GetAssetStatus(). This is used for enforcing rate limits on resizes.
+	BackendKey string `db:"backend_key"`
+
 	// This flag is set by a Castellum worker after a resize operation to indicate
 	// that the .Size attribute is outdated. The value is the new_size of the
 	// resize operation. We should expect this size to show in the next
@@ -166,6 +232,74 @@ type Asset struct {
 	// Castellum. Its intention is to allow operators to inspect the DB and alert
 	// on assets that remain on critical usage levels for too long.
 	CriticalUsages string `db:"critical_usages"`
+
+	// When a user cancels a pending operation through the API, they can ask for
+	// operations with the same reason to not be created on this asset again
+	// until the given time.
+	SnoozedReason Option[castellum.OperationReason] `db:"snoozed_reason"`
+	SnoozedUntil  Option[time.Time]                 `db:"snoozed_until"`
+
+	// If the asset matches the exclusion rules of its resource, contains a
+	// human-readable explanation. Castellum does not resize excluded assets.
+	// Contains the empty string otherwise.
+	ExclusionReason string `db:"exclusion_reason"`
+
+	// If the asset manager reports that maintenance is in progress on this
+	// asset (see core.AssetMaintainer), contains a human-readable description
+	// of its progress. Contains the empty string otherwise.
+	MaintenanceStatus string `db:"maintenance_status"`
+}
+
+// IsSnoozedFor returns whether operations with the given reason shall not be
+// created on this asset at the given time.
+func (a Asset) IsSnoozedFor(reason castellum.OperationReason, now time.Time) bool {
+	return a.SnoozedReason == Some(reason) && a.SnoozedUntil.IsSomeAnd(is.After(now))
+}
+
+// AssetUsageSampleStore provides structured access to the database table "asset_usage_samples".
+var AssetUsageSampleStore = oblast.MustNewStore[AssetUsageSample](
+	oblast.PostgresDialect(),
+	oblast.TableNameIs("asset_usage_samples"),
+	oblast.PrimaryKeyIs("asset_id", "recorded_at"),
+)
+
+// AssetUsageSample is a historical observation of the size and usage of an
+// Asset. These are recorded for assets in resources with predictive upscaling,
+// and for asset types that have a usage history retention configured.
+type AssetUsageSample struct {
+	AssetID    int64                 `db:"asset_id"`
+	RecordedAt time.Time             `db:"recorded_at"`
+	Size       uint64                `db:"size"`
+	Usage      castellum.UsageValues `db:"usage"`
+}
+
+// AssetOverrideStore provides structured access to the database table "asset_overrides".
+var AssetOverrideStore = oblast.MustNewStore[AssetOverride](
+	oblast.PostgresDialect(),
+	oblast.TableNameIs("asset_overrides"),
+	oblast.PrimaryKeyIs("asset_id"),
+)
+
+// AssetOverride contains configuration for a single asset that takes
+// precedence over the configuration of its Resource. Each field that is None
+// falls back to the respective value in the Resource.
+type AssetOverride struct {
+	AssetID int64 `db:"asset_id"`
+
+	// When true, no operations will be created for this asset (except for
+	// manual resizes requested by a user).
+	Disabled bool `db:"disabled"`
+
+	// Same meaning as the respective fields in type Resource. The delays are
+	// not overridden. A threshold of 0% for a metric disables that threshold.
+	LowThresholdPercent      Option[castellum.UsageValues] `db:"low_threshold_percent"`
+	HighThresholdPercent     Option[castellum.UsageValues] `db:"high_threshold_percent"`
+	CriticalThresholdPercent Option[castellum.UsageValues] `db:"critical_threshold_percent"`
+
+	// Same meaning as the respective fields in type Resource.
+	MinimumSize     Option[uint64] `db:"min_size"`
+	MaximumSize     Option[uint64] `db:"max_size"`
+	MinimumFreeSize Option[uint64] `db:"min_free_size"`
 }
 
 // PendingOperationStore provides structured access to the database table "pending_operations".
@@ -175,6 +309,16 @@ var PendingOperationStore = oblast.MustNewStore[PendingOperation](
 	oblast.PrimaryKeyIs("id"),
 )
 
+// OperationReasonManual is used as the reason for operations that were
+// requested by a user through the API instead of being triggered by one of the
+// thresholds of a resource. (This extends the castellum.OperationReason enum.)
+const OperationReasonManual castellum.OperationReason = "manual"
+
+// OperationReasonRepair is used as the reason for operations that repair a
+// broken asset without changing its size (see core.AssetRepairer). (This
+// extends the castellum.OperationReason enum.)
+const OperationReasonRepair castellum.OperationReason = "repair"
+
 // PendingOperation describes an ongoing resize operation for an asset.
 type PendingOperation struct {
 	ID      int64                     `db:"id,auto"`
@@ -216,6 +360,13 @@ type PendingOperation struct {
 
 // IntoFinishedOperation creates the FinishedOperation for this PendingOperation.
 func (o PendingOperation) IntoFinishedOperation(outcome castellum.OperationOutcome, finishedAt time.Time) FinishedOperation {
+	// a greenlight that was scheduled for after the end of the operation never
+	// took effect, so it shall not be reported as such
+	if o.GreenlitAt.IsSomeAnd(is.After(finishedAt)) {
+		o.GreenlitAt = None[time.Time]()
+		o.GreenlitByUserUUID = None[string]()
+	}
+
 	return FinishedOperation{
 		AssetID:            o.AssetID,
 		Reason:             o.Reason,
@@ -259,14 +410,42 @@ type FinishedOperation struct {
 	GreenlitByUserUUID Option[string] `db:"greenlit_by_user_uuid"`
 	ErrorMessage       string         `db:"error_message"`
 	ErroredAttempts    uint32         `db:"errored_attempts"`
+
+	// For operations that were cancelled by a user (rather than by Castellum
+	// itself), the UUID of that user.
+	CancelledByUserUUID Option[string] `db:"cancelled_by_user_uuid"`
 }
 
-// State returns the operation's state as a word.
-func (o PendingOperation) State() castellum.OperationState {
+// NotificationStore provides structured access to the database table "notifications".
+var NotificationStore = oblast.MustNewStore[Notification](
+	oblast.PostgresDialect(),
+	oblast.TableNameIs("notifications"),
+	oblast.PrimaryKeyIs("id"),
+)
+
+// Notification describes a notification about an operation state transition
+// that still needs to be delivered by one particular notifier.
+type Notification struct {
+	ID           int64  `db:"id,auto"`
+	NotifierType string `db:"notifier_type"` // the PluginTypeID of the core.Notifier that shall deliver this
+	PayloadJSON  string `db:"payload_json"`  // a serialized core.NotificationPayload
+
+	CreatedAt time.Time `db:"created_at"`
+	// When the next delivery attempt shall be made. After failed delivery
+	// attempts, this is moved into the future with exponential backoff.
+	NextDeliveryAt time.Time `db:"next_delivery_at"`
+	FailedAttempts uint32    `db:"failed_attempts"`
+	// The error message from the last failed delivery attempt, if any.
+	ErrorMessage string `db:"error_message"`
+}
+
+// StateAt returns the operation's state at the given point in time as a word.
+// (An operation with a .GreenlitAt in the future is still "confirmed".)
+func (o PendingOperation) StateAt(now time.Time) castellum.OperationState {
 	switch {
 	case o.ConfirmedAt.IsNone():
 		return castellum.OperationStateCreated
-	case o.GreenlitAt.IsNone():
+	case o.GreenlitAt.IsNoneOr(is.After(now)):
 		return castellum.OperationStateConfirmed
 	default:
 		return castellum.OperationStateGreenlit
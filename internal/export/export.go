@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sapcc/go-bits/sqlext"
+	"go.xyrillian.de/gg/gsql"
+
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// Row is one line of the output of `castellum export-history`. It contains
+// aggregated statistics about the finished operations on all assets of a
+// single resource.
+type Row struct {
+	// For domain resources, .ProjectUUID is empty.
+	DomainUUID  string       `json:"domain_id"`
+	ProjectUUID string       `json:"project_id,omitempty"`
+	AssetType   db.AssetType `json:"asset_type"`
+	// Sizes are in the unit of the respective asset type (e.g. GiB for NFS shares).
+	SizeAdded   uint64 `json:"size_added"`
+	SizeRemoved uint64 `json:"size_removed"`
+	// Numbers of operations by reason.
+	LowOperations      uint64 `json:"operations_low"`
+	HighOperations     uint64 `json:"operations_high"`
+	CriticalOperations uint64 `json:"operations_critical"`
+	ManualOperations   uint64 `json:"operations_manual"`
+	RepairOperations   uint64 `json:"operations_repair"`
+	// Numbers of operations by outcome.
+	SucceededOperations uint64 `json:"operations_succeeded"`
+	FailedOperations    uint64 `json:"operations_failed"`
+	ErroredOperations   uint64 `json:"operations_errored"`
+	CancelledOperations uint64 `json:"operations_cancelled"`
+}
+
+// Only succeeded operations count towards the size totals, since all other
+// operations did not change the size of their asset.
+var aggregateQuery = sqlext.SimplifyWhitespace(`
+	SELECT r.scope_uuid, r.domain_uuid, r.asset_type,
+	       COALESCE(SUM(o.new_size - o.old_size) FILTER (WHERE o.outcome = 'succeeded' AND o.new_size > o.old_size), 0),
+	       COALESCE(SUM(o.old_size - o.new_size) FILTER (WHERE o.outcome = 'succeeded' AND o.new_size < o.old_size), 0),
+	       COUNT(*) FILTER (WHERE o.reason = 'low'),
+	       COUNT(*) FILTER (WHERE o.reason = 'high'),
+	       COUNT(*) FILTER (WHERE o.reason = 'critical'),
+	       COUNT(*) FILTER (WHERE o.reason = 'manual'),
+	       COUNT(*) FILTER (WHERE o.reason = 'repair'),
+	       COUNT(*) FILTER (WHERE o.outcome = 'succeeded'),
+	       COUNT(*) FILTER (WHERE o.outcome = 'failed'),
+	       COUNT(*) FILTER (WHERE o.outcome = 'errored'),
+	       COUNT(*) FILTER (WHERE o.outcome = 'cancelled')
+	  FROM finished_operations o
+	  JOIN assets a ON a.id = o.asset_id
+	  JOIN resources r ON r.id = a.resource_id
+	 WHERE o.finished_at >= $1 AND o.finished_at < $2 AND o.outcome != 'error-resolved'
+	 GROUP BY r.scope_uuid, r.domain_uuid, r.asset_type
+	 ORDER BY r.domain_uuid, r.scope_uuid, r.asset_type
+`)
+
+// Aggregate computes one Row for each resource that had operations finishing
+// within the given time window. The start of the window is inclusive, the end
+// is exclusive.
+func Aggregate(dbi *gsql.DB, start, end time.Time) ([]Row, error) {
+	var result []Row
+	err := sqlext.ForeachRow(dbi, aggregateQuery, []any{start, end}, func(rows *sql.Rows) error {
+		var (
+			res db.Resource
+			row Row
+		)
+		err := rows.Scan(&res.ScopeUUID, &res.DomainUUID, &res.AssetType,
+			&row.SizeAdded, &row.SizeRemoved,
+			&row.LowOperations, &row.HighOperations, &row.CriticalOperations, &row.ManualOperations, &row.RepairOperations,
+			&row.SucceededOperations, &row.FailedOperations, &row.ErroredOperations, &row.CancelledOperations,
+		)
+		if err != nil {
+			return err
+		}
+		row.DomainUUID = res.DomainUUID
+		if res.ScopeType() == db.ProjectScope {
+			row.ProjectUUID = res.ScopeUUID
+		}
+		row.AssetType = res.AssetType
+		result = append(result, row)
+		return nil
+	})
+	return result, err
+}
+
+// Format is an output format for type Row.
+type Format string
+
+const (
+	// FormatCSV writes a header line followed by one line per Row.
+	FormatCSV Format = "csv"
+	// FormatJSON writes a JSON document with a single field "resources".
+	FormatJSON Format = "json"
+)
+
+// Write renders the given rows in the given format.
+func Write(w io.Writer, rows []Row, format Format) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, rows)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if rows == nil {
+			rows = []Row{}
+		}
+		return enc.Encode(struct {
+			Resources []Row `json:"resources"`
+		}{rows})
+	default:
+		return fmt.Errorf("unknown output format: %q", format)
+	}
+}
+
+var csvHeader = []string{
+	"domain_id", "project_id", "asset_type", "size_added", "size_removed",
+	"operations_low", "operations_high", "operations_critical", "operations_manual", "operations_repair",
+	"operations_succeeded", "operations_failed", "operations_errored", "operations_cancelled",
+}
+
+func writeCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+
+	str := func(value uint64) string { return strconv.FormatUint(value, 10) }
+	for _, row := range rows {
+		err := cw.Write([]string{
+			row.DomainUUID, row.ProjectUUID, string(row.AssetType), str(row.SizeAdded), str(row.SizeRemoved),
+			str(row.LowOperations), str(row.HighOperations), str(row.CriticalOperations), str(row.ManualOperations), str(row.RepairOperations),
+			str(row.SucceededOperations), str(row.FailedOperations), str(row.ErroredOperations), str(row.CancelledOperations),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package export_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/must"
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/castellum/internal/db"
+	"github.com/sapcc/castellum/internal/export"
+	"github.com/sapcc/castellum/internal/test"
+)
+
+func TestExportHistory(t *testing.T) {
+	s := test.NewSetup(t)
+	ctx := t.Context()
+	unix := func(timestamp int64) time.Time { return time.Unix(timestamp, 0).UTC() }
+
+	must.SucceedT(t, db.ResourceStore.Insert(ctx, s.DB,
+		&db.Resource{ScopeUUID: "project1", DomainUUID: "domain1", AssetType: "foo"},
+		&db.Resource{ScopeUUID: "domain1", DomainUUID: "domain1", AssetType: "foo"},
+		&db.Resource{ScopeUUID: "project2", DomainUUID: "domain1", AssetType: "foo"},
+	))
+	must.SucceedT(t, db.AssetStore.Insert(ctx, s.DB,
+		&db.Asset{ResourceID: 1, UUID: "asset1"},
+		&db.Asset{ResourceID: 1, UUID: "asset2"},
+		&db.Asset{ResourceID: 2, UUID: "asset3"},
+		&db.Asset{ResourceID: 3, UUID: "asset4"},
+	))
+
+	op := func(assetID int64, reason castellum.OperationReason, outcome castellum.OperationOutcome, oldSize, newSize uint64, finishedAt int64) *db.FinishedOperation {
+		return &db.FinishedOperation{
+			AssetID:    assetID,
+			Reason:     reason,
+			Outcome:    outcome,
+			OldSize:    oldSize,
+			NewSize:    newSize,
+			Usage:      castellum.UsageValues{castellum.SingularUsageMetric: 0},
+			CreatedAt:  unix(finishedAt - 1),
+			FinishedAt: unix(finishedAt),
+		}
+	}
+	must.SucceedT(t, db.FinishedOperationStore.Insert(ctx, s.DB,
+		op(1, castellum.OperationReasonHigh, castellum.OperationOutcomeSucceeded, 100, 120, 110),
+		op(1, castellum.OperationReasonCritical, castellum.OperationOutcomeSucceeded, 120, 150, 120),
+		op(2, castellum.OperationReasonLow, castellum.OperationOutcomeSucceeded, 100, 90, 130),
+		op(2, castellum.OperationReasonHigh, castellum.OperationOutcomeErrored, 90, 100, 140),
+		op(2, castellum.OperationReasonHigh, castellum.OperationOutcomeErrorResolved, 0, 0, 141),
+		op(3, db.OperationReasonManual, castellum.OperationOutcomeFailed, 10, 20, 150),
+		op(3, castellum.OperationReasonLow, castellum.OperationOutcomeCancelled, 10, 5, 160),
+		// this one is outside of the time window
+		op(4, castellum.OperationReasonHigh, castellum.OperationOutcomeSucceeded, 100, 200, 200),
+	))
+
+	rows, err := export.Aggregate(s.DB, unix(100), unix(200))
+	must.SucceedT(t, err)
+	assert.Equal(t, rows, []export.Row{
+		{
+			DomainUUID:          "domain1",
+			AssetType:           "foo",
+			ManualOperations:    1,
+			LowOperations:       1,
+			FailedOperations:    1,
+			CancelledOperations: 1,
+		},
+		{
+			DomainUUID:          "domain1",
+			ProjectUUID:         "project1",
+			AssetType:           "foo",
+			SizeAdded:           50,
+			SizeRemoved:         10,
+			LowOperations:       1,
+			HighOperations:      2,
+			CriticalOperations:  1,
+			SucceededOperations: 3,
+			ErroredOperations:   1,
+		},
+	})
+
+	var buf bytes.Buffer
+	must.SucceedT(t, export.Write(&buf, rows, export.FormatCSV))
+	assert.Equal(t, buf.String(), ""+
+		"domain_id,project_id,asset_type,size_added,size_removed,operations_low,operations_high,operations_critical,operations_manual,operations_repair,operations_succeeded,operations_failed,operations_errored,operations_cancelled\n"+
+		"domain1,,foo,0,0,1,0,0,1,0,0,1,0,1\n"+
+		"domain1,project1,foo,50,10,1,2,1,0,0,3,0,1,0\n",
+	)
+
+	// an empty time window yields an empty result
+	rows, err = export.Aggregate(s.DB, unix(300), unix(400))
+	must.SucceedT(t, err)
+	buf.Reset()
+	must.SucceedT(t, export.Write(&buf, rows, export.FormatJSON))
+	assert.Equal(t, buf.String(), "{\n  \"resources\": []\n}\n")
+
+	// unknown formats are rejected
+	assert.ErrEqual(t, export.Write(&buf, rows, "xml"), `unknown output format: "xml"`)
+}
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/osext"
+
+	"github.com/sapcc/castellum/internal/core"
+)
+
+type notifierEmail struct {
+	ServerAddress string
+	Auth          smtp.Auth // may be nil
+	From          mail.Address
+	Recipients    []mail.Address
+	States        map[castellum.OperationState]bool
+
+	// dependency injection slot (filled with smtp.SendMail by Init(), but filled with a double in tests)
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func init() {
+	core.NotifierRegistry.Add(func() core.Notifier { return &notifierEmail{} })
+}
+
+// PluginTypeID implements the core.Notifier interface.
+func (n *notifierEmail) PluginTypeID() string { return "email" }
+
+// Init implements the core.Notifier interface.
+func (n *notifierEmail) Init(ctx context.Context, provider core.ProviderClient) error {
+	host, err := osext.NeedGetenv("CASTELLUM_EMAIL_SMTP_HOST")
+	if err != nil {
+		return err
+	}
+	n.ServerAddress = net.JoinHostPort(host, osext.GetenvOrDefault("CASTELLUM_EMAIL_SMTP_PORT", "587"))
+	if username := osext.GetenvOrDefault("CASTELLUM_EMAIL_SMTP_USERNAME", ""); username != "" {
+		password, err := osext.NeedGetenv("CASTELLUM_EMAIL_SMTP_PASSWORD")
+		if err != nil {
+			return err
+		}
+		n.Auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	fromStr, err := osext.NeedGetenv("CASTELLUM_EMAIL_FROM")
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(fromStr)
+	if err != nil {
+		return fmt.Errorf("cannot parse CASTELLUM_EMAIL_FROM: %w", err)
+	}
+	n.From = *from
+
+	recipientsStr, err := osext.NeedGetenv("CASTELLUM_EMAIL_RECIPIENTS")
+	if err != nil {
+		return err
+	}
+	recipients, err := mail.ParseAddressList(recipientsStr)
+	if err != nil {
+		return fmt.Errorf("cannot parse CASTELLUM_EMAIL_RECIPIENTS: %w", err)
+	}
+	for _, r := range recipients {
+		n.Recipients = append(n.Recipients, *r)
+	}
+
+	n.States, err = parseOperationStates(osext.GetenvOrDefault("CASTELLUM_EMAIL_STATES", "succeeded,failed,errored"))
+	if err != nil {
+		return fmt.Errorf("cannot parse CASTELLUM_EMAIL_STATES: %w", err)
+	}
+	n.SendMail = smtp.SendMail
+	return nil
+}
+
+// IsInterestedIn implements the core.Notifier interface.
+func (n *notifierEmail) IsInterestedIn(state castellum.OperationState) bool {
+	return n.States[state]
+}
+
+// SendNotification implements the core.Notifier interface.
+func (n *notifierEmail) SendNotification(_ context.Context, payload core.NotificationPayload) error {
+	recipientAddresses := make([]string, len(n.Recipients))
+	for idx, r := range n.Recipients {
+		recipientAddresses[idx] = r.Address
+	}
+	return n.SendMail(n.ServerAddress, n.Auth, n.From.Address, recipientAddresses, n.buildMessage(payload))
+}
+
+func (n *notifierEmail) buildMessage(payload core.NotificationPayload) []byte {
+	recipients := make([]string, len(n.Recipients))
+	for idx, r := range n.Recipients {
+		recipients[idx] = r.String()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.From.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&buf, "Subject: [Castellum] Operation on %s %s is %s\r\n", payload.AssetType, payload.AssetUUID, payload.ToState)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Unix(payload.OccurredAt, 0).UTC().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+
+	fmt.Fprintf(&buf, "An operation on %s %s moved from state %q into state %q.\r\n\r\n",
+		payload.AssetType, payload.AssetUUID, payload.FromState, payload.ToState)
+	if payload.ProjectUUID == "" {
+		fmt.Fprintf(&buf, "Domain:   %s\r\n", payload.DomainUUID)
+	} else {
+		fmt.Fprintf(&buf, "Project:  %s (in domain %s)\r\n", payload.ProjectUUID, payload.DomainUUID)
+	}
+	fmt.Fprintf(&buf, "Reason:   %s\r\n", payload.Reason)
+	fmt.Fprintf(&buf, "Old size: %d\r\n", payload.OldSize)
+	fmt.Fprintf(&buf, "New size: %d\r\n", payload.NewSize)
+	if payload.ErrorMessage != "" {
+		fmt.Fprintf(&buf, "Error:    %s\r\n", payload.ErrorMessage)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/must"
+	"go.xyrillian.de/gg/assert"
+)
+
+func TestEmailNotifier(t *testing.T) {
+	t.Setenv("CASTELLUM_EMAIL_SMTP_HOST", "smtp.example.com")
+	t.Setenv("CASTELLUM_EMAIL_FROM", "Castellum <castellum@example.com>")
+	t.Setenv("CASTELLUM_EMAIL_RECIPIENTS", "alice@example.com, Bob <bob@example.com>")
+	n := &notifierEmail{}
+	must.SucceedT(t, n.Init(t.Context(), nil))
+	assert.Equal(t, n.ServerAddress, "smtp.example.com:587")
+	assert.Equal(t, n.IsInterestedIn(castellum.OperationStateFailed), true)
+	assert.Equal(t, n.IsInterestedIn(castellum.OperationStateConfirmed), false)
+
+	// a password is required when a username is given
+	t.Setenv("CASTELLUM_EMAIL_SMTP_USERNAME", "castellum")
+	assert.ErrEqual(t, (&notifierEmail{}).Init(t.Context(), nil), `environment variable "CASTELLUM_EMAIL_SMTP_PASSWORD" is not set`)
+
+	var sentMessages []string
+	n.SendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, addr, "smtp.example.com:587")
+		assert.Equal(t, from, "castellum@example.com")
+		assert.Equal(t, to, []string{"alice@example.com", "bob@example.com"})
+		sentMessages = append(sentMessages, string(msg))
+		return nil
+	}
+	must.SucceedT(t, n.SendNotification(t.Context(), testNotificationPayload))
+
+	expectedMessage := strings.Join([]string{
+		`From: "Castellum" <castellum@example.com>`,
+		`To: <alice@example.com>, "Bob" <bob@example.com>`,
+		`Subject: [Castellum] Operation on nfs-shares share1 is failed`,
+		`Date: Mon, 06 May 2019 06:00:00 +0000`,
+		`MIME-Version: 1.0`,
+		`Content-Type: text/plain; charset=utf-8`,
+		``,
+		`An operation on nfs-shares share1 moved from state "greenlit" into state "failed".`,
+		``,
+		`Project:  project1 (in domain domain1)`,
+		`Reason:   high`,
+		`Old size: 1000`,
+		`New size: 1200`,
+		`Error:    quota exceeded`,
+		``,
+	}, "\r\n")
+	assert.Equal(t, sentMessages, []string{expectedMessage})
+}
@@ -279,16 +279,20 @@ func (m *assetManagerNFS) GetAssetStatus(ctx context.Context, res db.Resource, a
 		Size:              *metrics.SizeGiB,
 		StrictMinimumSize: Some(metrics.MinSizeGiB),
 		Usage:             castellum.UsageValues{castellum.SingularUsageMetric: *metrics.UsedGiB},
+		BackendKey:        previousStatus.UnwrapOr(core.AssetStatus{}).BackendKey,
 	}
 
 	// when size has changed compared to last time, double-check with the Manila
-	// API (this call is expensive, so we only do it when really necessary)
-	if previousStatus.IsSomeAnd(func(prev core.AssetStatus) bool { return prev.Size != status.Size }) {
+	// API (this call is expensive, so we only do it when really necessary); we
+	// also take this opportunity to find the backend that hosts the share
+	sizeChanged := previousStatus.IsSomeAnd(func(prev core.AssetStatus) bool { return prev.Size != status.Size })
+	if sizeChanged || status.BackendKey == "" {
 		share, err := shares.Get(ctx, m.Manila, assetUUID).Extract()
 		if err != nil {
 			return core.AssetStatus{}, fmt.Errorf("cannot get status of share %s from Manila API: %w", assetUUID, err)
 		}
-		if share.Size < 0 || uint64(share.Size) != status.Size {
+		status.BackendKey = backendKeyFromHost(share.Host)
+		if sizeChanged && (share.Size < 0 || uint64(share.Size) != status.Size) {
 			return core.AssetStatus{}, fmt.Errorf(
 				"inconsistent size reports for share %s: Prometheus says %d GiB, Manila says %d GiB",
 				assetUUID, status.Size, share.Size)
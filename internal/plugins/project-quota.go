@@ -0,0 +1,282 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-api-declarations/limes"
+	limesresources "github.com/sapcc/go-api-declarations/limes/resources"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+type assetTypeProjectQuota struct {
+	ServiceType  limes.ServiceType
+	ResourceName limesresources.ResourceName
+}
+
+func parseProjectQuotaAssetType(assetType db.AssetType) Option[assetTypeProjectQuota] {
+	fields := strings.SplitN(string(assetType), ":", 3)
+	if len(fields) != 3 || fields[0] != "project-quota" || fields[1] == "" || fields[2] == "" {
+		return None[assetTypeProjectQuota]()
+	}
+	return Some(assetTypeProjectQuota{
+		ServiceType:  limes.ServiceType(fields[1]),
+		ResourceName: limesresources.ResourceName(fields[2]),
+	})
+}
+
+// configForProjectQuota is the type-specific configuration for "project-quota" resources.
+type configForProjectQuota struct {
+	// Either "usage" (the default) or "physical_usage".
+	UsageSource string `json:"usage_source"`
+}
+
+type assetManagerProjectQuota struct {
+	Provider core.ProviderClient
+	Limes    *gophercloud.ServiceClient
+}
+
+func init() {
+	core.AssetManagerRegistry.Add(func() core.AssetManager { return &assetManagerProjectQuota{} })
+}
+
+// PluginTypeID implements the core.AssetManager interface.
+func (m *assetManagerProjectQuota) PluginTypeID() string { return "project-quota" }
+
+// Init implements the core.AssetManager interface.
+func (m *assetManagerProjectQuota) Init(ctx context.Context, provider core.ProviderClient) (err error) {
+	m.Provider = provider
+	m.Limes, err = provider.CloudAdminClient(newLimesV1)
+	return err
+}
+
+// newLimesV1 is a core.ServiceClientFactory for Limes (or any other service
+// implementing the Limes v1 API under the service type "resources").
+func newLimesV1(client *gophercloud.ProviderClient, eo gophercloud.EndpointOpts) (*gophercloud.ServiceClient, error) {
+	eo.ApplyDefaults("resources")
+	endpointURL, err := client.EndpointLocator(eo)
+	if err != nil {
+		return nil, err
+	}
+	return &gophercloud.ServiceClient{
+		ProviderClient: client,
+		Endpoint:       endpointURL,
+		Type:           "resources",
+	}, nil
+}
+
+// InfoForAssetType implements the core.AssetManager interface.
+func (m *assetManagerProjectQuota) InfoForAssetType(assetType db.AssetType) Option[core.AssetTypeInfo] {
+	if parseProjectQuotaAssetType(assetType).IsSome() {
+		return Some(core.AssetTypeInfo{
+			AssetType:    assetType,
+			UsageMetrics: []castellum.UsageMetric{castellum.SingularUsageMetric},
+		})
+	}
+	return None[core.AssetTypeInfo]()
+}
+
+// CheckResourceAllowed implements the core.AssetManager interface.
+func (m *assetManagerProjectQuota) CheckResourceAllowed(ctx context.Context, assetType db.AssetType, scopeUUID, configJSON string, existingResources map[db.AssetType]struct{}) error {
+	_, err := parseProjectQuotaConfig(configJSON)
+	if err != nil {
+		return err
+	}
+
+	parsed, ok := parseProjectQuotaAssetType(assetType).Unpack()
+	if !ok {
+		return fmt.Errorf("could not parse asset type %s", assetType)
+	}
+	report, err := m.getResourceReport(ctx, scopeUUID, parsed)
+	if err != nil {
+		return err
+	}
+	if report.Quota == nil {
+		return fmt.Errorf("resource %s/%s does not track quota", parsed.ServiceType, parsed.ResourceName)
+	}
+	if report.QuotaDistributionModel == limesresources.AutogrowQuotaDistribution {
+		return fmt.Errorf("quota for resource %s/%s is managed automatically by Limes", parsed.ServiceType, parsed.ResourceName)
+	}
+	return nil
+}
+
+func parseProjectQuotaConfig(configJSON string) (configForProjectQuota, error) {
+	cfg := configForProjectQuota{UsageSource: "usage"}
+	if configJSON == "" {
+		return cfg, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(configJSON))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&cfg)
+	if err != nil {
+		return configForProjectQuota{}, fmt.Errorf("cannot parse configuration: %w", err)
+	}
+	switch cfg.UsageSource {
+	case "usage", "physical_usage":
+		return cfg, nil
+	case "":
+		cfg.UsageSource = "usage"
+		return cfg, nil
+	default:
+		return configForProjectQuota{}, fmt.Errorf(`value for usage_source must be either "usage" or "physical_usage", but got %q`, cfg.UsageSource)
+	}
+}
+
+// ListAssets implements the core.AssetManager interface.
+func (m *assetManagerProjectQuota) ListAssets(_ context.Context, res db.Resource) ([]string, error) {
+	// each resource has exactly one asset: the project's quota for this resource
+	return []string{res.ScopeUUID}, nil
+}
+
+// SetAssetSize implements the core.AssetManager interface.
+func (m *assetManagerProjectQuota) SetAssetSize(ctx context.Context, res db.Resource, projectUUID string, oldSize, newSize uint64) (castellum.OperationOutcome, error) {
+	assetType, ok := parseProjectQuotaAssetType(res.AssetType).Unpack()
+	if !ok {
+		return castellum.OperationOutcomeErrored, fmt.Errorf("could not parse asset type %s", res.AssetType)
+	}
+	projectURL, err := m.projectURL(ctx, projectUUID)
+	if err != nil {
+		return castellum.OperationOutcomeErrored, err
+	}
+
+	body := limesProjectQuotaRequest{}
+	body.Project.Services = []limesServiceQuotaRequest{{
+		Type: assetType.ServiceType,
+		Resources: []limesResourceQuotaRequest{{
+			Name:  assetType.ResourceName,
+			Quota: newSize,
+		}},
+	}}
+	_, err = m.Limes.Put(ctx, projectURL, body, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{http.StatusOK, http.StatusAccepted},
+	})
+	if err != nil {
+		// If Limes refuses the new quota (e.g. because it exceeds the domain
+		// quota or is below the current usage), it's the user's fault, not ours.
+		if gophercloud.ResponseCodeIs(err, http.StatusConflict) || gophercloud.ResponseCodeIs(err, http.StatusUnprocessableEntity) {
+			return castellum.OperationOutcomeFailed, err
+		}
+		return castellum.OperationOutcomeErrored, err
+	}
+	return castellum.OperationOutcomeSucceeded, nil
+}
+
+// GetAssetStatus implements the core.AssetManager interface.
+func (m *assetManagerProjectQuota) GetAssetStatus(ctx context.Context, res db.Resource, projectUUID string, previousStatus Option[core.AssetStatus]) (core.AssetStatus, error) {
+	assetType, ok := parseProjectQuotaAssetType(res.AssetType).Unpack()
+	if !ok {
+		return core.AssetStatus{}, fmt.Errorf("could not parse asset type %s", res.AssetType)
+	}
+	cfg, err := parseProjectQuotaConfig(res.ConfigJSON)
+	if err != nil {
+		return core.AssetStatus{}, err
+	}
+
+	report, err := m.getResourceReport(ctx, projectUUID, assetType)
+	if err != nil {
+		return core.AssetStatus{}, err
+	}
+	if report.Quota == nil {
+		return core.AssetStatus{}, fmt.Errorf("resource %s/%s does not track quota", assetType.ServiceType, assetType.ResourceName)
+	}
+
+	usage := report.Usage
+	if cfg.UsageSource == "physical_usage" {
+		if report.PhysicalUsage == nil {
+			return core.AssetStatus{}, fmt.Errorf("resource %s/%s does not report physical_usage", assetType.ServiceType, assetType.ResourceName)
+		}
+		usage = *report.PhysicalUsage
+	}
+
+	status := core.AssetStatus{
+		Size:  *report.Quota,
+		Usage: castellum.UsageValues{castellum.SingularUsageMetric: float64(usage)},
+		// Limes does not accept quotas below the (logical) usage, regardless of which usage we track
+		StrictMinimumSize: Some(report.Usage),
+	}
+	if report.MaxQuota != nil {
+		status.StrictMaximumSize = Some(*report.MaxQuota)
+	}
+	return status, nil
+}
+
+func (m *assetManagerProjectQuota) projectURL(ctx context.Context, projectUUID string) (string, error) {
+	project, err := m.Provider.GetProject(ctx, projectUUID)
+	if err != nil {
+		return "", err
+	}
+	if project == nil {
+		return "", core.AssetNotFoundError{InnerError: fmt.Errorf("project not found in Keystone: %s", projectUUID)}
+	}
+	return m.Limes.ServiceURL("v1", "domains", project.DomainID, "projects", projectUUID), nil
+}
+
+func (m *assetManagerProjectQuota) getResourceReport(ctx context.Context, projectUUID string, assetType assetTypeProjectQuota) (*limesresources.ProjectResourceReport, error) {
+	projectURL, err := m.projectURL(ctx, projectUUID)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{
+		"service":  {string(assetType.ServiceType)},
+		"resource": {string(assetType.ResourceName)},
+	}
+
+	var data struct {
+		Project limesresources.ProjectReport `json:"project"`
+	}
+	_, err = m.Limes.Get(ctx, projectURL+"?"+query.Encode(), &data, nil)
+	if err != nil {
+		if gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+			return nil, core.AssetNotFoundError{InnerError: fmt.Errorf("project not found in Limes: %w", err)}
+		}
+		return nil, fmt.Errorf("cannot get quota report for project %s from Limes: %w", projectUUID, err)
+	}
+
+	srvReport, ok := data.Project.Services[assetType.ServiceType]
+	if !ok {
+		return nil, fmt.Errorf("no such service in Limes: %q", assetType.ServiceType)
+	}
+	resReport, ok := srvReport.Resources[assetType.ResourceName]
+	if !ok {
+		return nil, fmt.Errorf("no such resource in Limes: %q", string(assetType.ServiceType)+"/"+string(assetType.ResourceName))
+	}
+	if resReport == nil {
+		return nil, errors.New("got null resource report from Limes")
+	}
+	return resReport, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// request body types for Limes
+
+// The Limes API declarations only cover the read side of the v1 API, so the
+// request body for setting quotas is declared here.
+type limesProjectQuotaRequest struct {
+	Project struct {
+		Services []limesServiceQuotaRequest `json:"services"`
+	} `json:"project"`
+}
+
+type limesServiceQuotaRequest struct {
+	Type      limes.ServiceType           `json:"type"`
+	Resources []limesResourceQuotaRequest `json:"resources"`
+}
+
+type limesResourceQuotaRequest struct {
+	Name  limesresources.ResourceName `json:"name"`
+	Quota uint64                      `json:"quota"`
+}
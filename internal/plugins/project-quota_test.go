@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/errext"
+	"github.com/sapcc/go-bits/must"
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// stubProviderClient only knows about projects. All other methods panic.
+type stubProviderClient struct {
+	core.ProviderClient
+	Projects map[string]core.CachedProject
+}
+
+func (c stubProviderClient) GetProject(_ context.Context, projectID string) (*core.CachedProject, error) {
+	result, exists := c.Projects[projectID]
+	if exists {
+		return &result, nil
+	}
+	return nil, nil
+}
+
+// stubLimes is a very small subset of the Limes v1 API, with a single project.
+type stubLimes struct {
+	ResourcesJSON string
+	PutCalls      []string
+}
+
+func (s *stubLimes) Handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/domains/domain1/projects/project1", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("service"), "compute")
+		w.Header().Set("Content-Type", "application/json")
+		_, err := io.WriteString(w, `{"project":{"id":"project1","services":[{"type":"compute","area":"compute","resources":`+s.ResourcesJSON+`}]}}`)
+		must.SucceedT(t, err)
+	})
+	mux.HandleFunc("PUT /v1/domains/domain1/projects/project1", func(w http.ResponseWriter, r *http.Request) {
+		body := string(must.ReturnT(io.ReadAll(r.Body))(t))
+		s.PutCalls = append(s.PutCalls, body)
+		if len(s.PutCalls) > 1 {
+			http.Error(w, "cannot change compute/cores quota: domain quota exceeded", http.StatusUnprocessableEntity)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return mux
+}
+
+func setupProjectQuotaTest(t *testing.T, resourcesJSON string) (*assetManagerProjectQuota, *stubLimes) {
+	limesAPI := &stubLimes{ResourcesJSON: resourcesJSON}
+	limesServer := httptest.NewServer(limesAPI.Handler(t))
+	t.Cleanup(limesServer.Close)
+
+	m := &assetManagerProjectQuota{
+		Provider: stubProviderClient{Projects: map[string]core.CachedProject{
+			"project1": {Name: "First Project", DomainID: "domain1"},
+		}},
+		Limes: &gophercloud.ServiceClient{
+			ProviderClient: &gophercloud.ProviderClient{},
+			Endpoint:       limesServer.URL + "/",
+			Type:           "resources",
+		},
+	}
+	return m, limesAPI
+}
+
+const testLimesResourcesJSON = `[
+	{"name":"cores","quota":100,"usage":40,"physical_usage":25},
+	{"name":"instances","quota":10,"usage":4,"max_quota":20},
+	{"name":"server_groups","usage":2},
+	{"name":"ram","unit":"MiB","quota":1024,"usage":512,"quota_distribution_model":"autogrow"}
+]`
+
+func TestProjectQuotaCheckResourceAllowed(t *testing.T) {
+	m, _ := setupProjectQuotaTest(t, testLimesResourcesJSON)
+	ctx := t.Context()
+
+	assert.Equal(t, m.InfoForAssetType("project-quota:compute:cores").IsSome(), true)
+	assert.Equal(t, m.InfoForAssetType("project-quota:compute").IsSome(), false)
+	assert.Equal(t, m.InfoForAssetType("project-quota::cores").IsSome(), false)
+
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "project-quota:compute:cores", "project1", "", nil), nil)
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "project-quota:compute:cores", "project1", `{"usage_source":"physical_usage"}`, nil), nil)
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "project-quota:compute:cores", "project1", `{"usage_source":"logical_usage"}`, nil),
+		`value for usage_source must be either "usage" or "physical_usage", but got "logical_usage"`)
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "project-quota:compute:cores", "project1", `{"foo":"bar"}`, nil),
+		`cannot parse configuration: json: unknown field "foo"`)
+
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "project-quota:compute:floating_ips", "project1", "", nil),
+		`no such resource in Limes: "compute/floating_ips"`)
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "project-quota:compute:server_groups", "project1", "", nil),
+		`resource compute/server_groups does not track quota`)
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "project-quota:compute:ram", "project1", "", nil),
+		`quota for resource compute/ram is managed automatically by Limes`)
+}
+
+func TestProjectQuotaGetAssetStatus(t *testing.T) {
+	m, _ := setupProjectQuotaTest(t, testLimesResourcesJSON)
+	ctx := t.Context()
+
+	res := db.Resource{ScopeUUID: "project1", AssetType: "project-quota:compute:cores"}
+	assets, err := m.ListAssets(ctx, res)
+	must.SucceedT(t, err)
+	assert.Equal(t, assets, []string{"project1"})
+
+	status, err := m.GetAssetStatus(ctx, res, "project1", None[core.AssetStatus]())
+	must.SucceedT(t, err)
+	assert.Equal(t, status, core.AssetStatus{
+		Size:              100,
+		Usage:             castellum.UsageValues{castellum.SingularUsageMetric: 40},
+		StrictMinimumSize: Some[uint64](40),
+	})
+
+	// physical usage can be tracked instead, but quota can still not go below the logical usage
+	res.ConfigJSON = `{"usage_source":"physical_usage"}`
+	status, err = m.GetAssetStatus(ctx, res, "project1", None[core.AssetStatus]())
+	must.SucceedT(t, err)
+	assert.Equal(t, status, core.AssetStatus{
+		Size:              100,
+		Usage:             castellum.UsageValues{castellum.SingularUsageMetric: 25},
+		StrictMinimumSize: Some[uint64](40),
+	})
+
+	res = db.Resource{ScopeUUID: "project1", AssetType: "project-quota:compute:instances", ConfigJSON: `{"usage_source":"physical_usage"}`}
+	_, err = m.GetAssetStatus(ctx, res, "project1", None[core.AssetStatus]())
+	assert.ErrEqual(t, err, "resource compute/instances does not report physical_usage")
+
+	// max_quota is reported as the maximum size
+	res.ConfigJSON = ""
+	status, err = m.GetAssetStatus(ctx, res, "project1", None[core.AssetStatus]())
+	must.SucceedT(t, err)
+	assert.Equal(t, status.StrictMaximumSize, Some[uint64](20))
+
+	// projects that were deleted in Keystone are reported as such
+	_, err = m.GetAssetStatus(ctx, db.Resource{ScopeUUID: "project2", AssetType: "project-quota:compute:cores"}, "project2", None[core.AssetStatus]())
+	assert.Equal(t, errext.IsOfType[core.AssetNotFoundError](err), true)
+}
+
+func TestProjectQuotaSetAssetSize(t *testing.T) {
+	m, limesAPI := setupProjectQuotaTest(t, testLimesResourcesJSON)
+	ctx := t.Context()
+	res := db.Resource{ScopeUUID: "project1", AssetType: "project-quota:compute:cores"}
+
+	outcome, err := m.SetAssetSize(ctx, res, "project1", 100, 120)
+	must.SucceedT(t, err)
+	assert.Equal(t, outcome, castellum.OperationOutcomeSucceeded)
+
+	// when Limes rejects the quota, that's the user's fault (the stub rejects all further requests)
+	outcome, err = m.SetAssetSize(ctx, res, "project1", 120, 150)
+	assert.Equal(t, err != nil, true)
+	assert.Equal(t, outcome, castellum.OperationOutcomeFailed)
+
+	assert.Equal(t, limesAPI.PutCalls, []string{
+		`{"project":{"services":[{"type":"compute","resources":[{"name":"cores","quota":120}]}]}}`,
+		`{"project":{"services":[{"type":"compute","resources":[{"name":"cores","quota":150}]}]}}`,
+	})
+}
@@ -4,15 +4,21 @@
 package plugins
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/base32"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"net/url"
+	"regexp"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -46,17 +52,32 @@ const (
 	// ServerPollInterval is how often servers are polled during state transitions
 	// when the desired state has not been reached yet.
 	ServerPollInterval = 10 * time.Second
+	// ServerDrainPollInterval is how often the "server-groups" asset manager
+	// checks whether servers have been drained before deleting them.
+	ServerDrainPollInterval = 1 * time.Minute
 )
 
 // NOTE 1: The `virtualmachine` labels look like `$NAME ($ID)` or just `$ID`, the
 // latter without parentheses around the ID.
 //
 // NOTE 2: These queries return fractional values in the range 0..1, NOT percentages in the range 0..100.
+//
+// NOTE 3: Additional usage metrics can be configured per resource (see type configForUsageMetric).
 var serverUsageQueries = map[castellum.UsageMetric]string{
 	"cpu": `vrops_virtualmachine_cpu_usage_ratio{virtualmachine=~".*${ID}.*"} / 100`,
 	"ram": `vrops_virtualmachine_memory_consumed_kilobytes{virtualmachine=~".*${ID}.*"} / vrops_virtualmachine_memory_kilobytes{virtualmachine=~".*${ID}.*"}`,
 }
 
+// Values for configForUsageMetric.Normalization.
+const (
+	// The query returns the usage of a single server as a fraction in the range 0..1.
+	usageNormalizationRatio = "ratio"
+	// The query returns the absolute usage of a single server, which is divided by the configured capacity per server.
+	usageNormalizationAbsolute = "absolute"
+)
+
+var usageMetricNameRx = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
 type assetManagerServerGroups struct {
 	Provider       core.ProviderClient
 	Prometheus     promquery.Client
@@ -95,12 +116,22 @@ func (m *assetManagerServerGroups) InfoForAssetType(assetType db.AssetType) Opti
 	if strings.HasPrefix(string(assetType), "server-group:") {
 		return Some(core.AssetTypeInfo{
 			AssetType:    assetType,
-			UsageMetrics: []castellum.UsageMetric{"cpu", "ram"},
+			UsageMetrics: slices.Sorted(maps.Keys(serverUsageQueries)),
 		})
 	}
 	return None[core.AssetTypeInfo]()
 }
 
+// InfoForResource implements the core.ResourceInfoProvider interface.
+func (m *assetManagerServerGroups) InfoForResource(info core.AssetTypeInfo, configJSON string) core.AssetTypeInfo {
+	cfg, err := m.parseAndValidateConfig(configJSON)
+	if err != nil {
+		return info
+	}
+	info.UsageMetrics = slices.Sorted(maps.Keys(cfg.allUsageMetrics()))
+	return info
+}
+
 // CheckResourceAllowed implements the core.AssetManager interface.
 func (m *assetManagerServerGroups) CheckResourceAllowed(ctx context.Context, assetType db.AssetType, scopeUUID, configJSON string, existingResources map[db.AssetType]struct{}) error {
 	// check that the server group exists and is in the right project
@@ -131,6 +162,10 @@ func (m *assetManagerServerGroups) GetAssetStatus(ctx context.Context, res db.Re
 		return core.AssetStatus{}, err
 	}
 
+	cfg, err := m.parseAndValidateConfig(res.ConfigJSON)
+	if err != nil {
+		return core.AssetStatus{}, err
+	}
 	groupID := strings.TrimPrefix(string(res.AssetType), "server-group:")
 	group, err := m.getServerGroup(ctx, groupID)
 	if err != nil {
@@ -139,15 +174,19 @@ func (m *assetManagerServerGroups) GetAssetStatus(ctx context.Context, res db.Re
 
 	// check instance status
 	isNewServer := make(map[string]bool)
-	var serversToIgnore []string
+	var (
+		allServers      []*servers.Server
+		serversToIgnore []string
+	)
 	for _, serverID := range group.Members {
 		server, err := servers.Get(ctx, computeV2, serverID).Extract()
 		if err != nil {
 			return core.AssetStatus{}, fmt.Errorf("cannot inspect server %s: %w", serverID, err)
 		}
+		allServers = append(allServers, server)
 		// if any instance is not in error state, that's a huge red flag and we
-		// should not attempt any autoscaling
-		if server.Status == "ERROR" {
+		// should not attempt any autoscaling (unless we can replace it)
+		if server.Status == "ERROR" && cfg.SelfHealing.IsNone() {
 			return core.AssetStatus{}, fmt.Errorf("server %s is in status %s", serverID, server.Status)
 		}
 		// if an instance is in shutoff state, we handle this server as being non-existent.
@@ -157,7 +196,7 @@ func (m *assetManagerServerGroups) GetAssetStatus(ctx context.Context, res db.Re
 			serversToIgnore = append(serversToIgnore, serverID)
 		}
 		// for new servers, we will be more lenient wrt metric availability
-		if time.Since(server.Created) < 10*time.Minute {
+		if time.Since(server.Created) < serverStartupGracePeriod {
 			isNewServer[server.ID] = true
 		}
 	}
@@ -167,30 +206,53 @@ func (m *assetManagerServerGroups) GetAssetStatus(ctx context.Context, res db.Re
 		Size:  uint64(len(group.Members)),
 		Usage: make(castellum.UsageValues),
 	}
-	for metric := range serverUsageQueries {
+
+	// broken servers will be replaced by RepairAsset(); until then, we handle
+	// them as being non-existent, just like servers in shutoff state
+	if cfg.SelfHealing.IsSome() {
+		brokenServers, err := m.findBrokenServers(ctx, res, cfg, allServers)
+		if err != nil {
+			return core.AssetStatus{}, err
+		}
+		for _, server := range brokenServers {
+			serversToIgnore = append(serversToIgnore, server.ID)
+		}
+		result.NeedsRepair = len(brokenServers) > 0
+	}
+	usageMetrics := cfg.allUsageMetrics()
+	for metric := range usageMetrics {
 		result.Usage[metric] = 0
 	}
 	for _, serverID := range group.Members {
 		if slices.Contains(serversToIgnore, serverID) {
 			continue
 		}
-		for metric, queryTemplate := range serverUsageQueries {
-			queryStr := strings.ReplaceAll(queryTemplate, "${ID}", serverID)
+		replacer := strings.NewReplacer("${ID}", serverID, "${GROUP_ID}", group.ID, "${PROJECT_ID}", res.ScopeUUID)
+		for metric, metricCfg := range usageMetrics {
+			queryStr := replacer.Replace(metricCfg.Query)
 			value, err := m.Prometheus.GetSingleValue(ctx, queryStr, nil)
 			if promquery.IsErrNoRows(err) && isNewServer[serverID] {
 				// within a few minutes of instance creation, it's not a hard error if
-				// the vrops metric has not showed up in Prometheus yet; we'll just
+				// the metric has not showed up in Prometheus yet; we'll just
 				// assume zero usage for now, which should be okay since downscaling
 				// usually has a delay of way more than those few minutes
 				value = 0
 			} else if err != nil {
 				return core.AssetStatus{}, err
 			}
-			if value < 0 {
-				return core.AssetStatus{}, fmt.Errorf("expected value between 0..1, but got negative value from Prometheus query: %s", queryStr)
-			}
-			if value > 1 {
-				return core.AssetStatus{}, fmt.Errorf("expected value between 0..1, but got larger value from Prometheus query: %s", queryStr)
+			switch metricCfg.Normalization {
+			case usageNormalizationAbsolute:
+				if value < 0 {
+					return core.AssetStatus{}, fmt.Errorf("expected non-negative value, but got negative value from Prometheus query: %s", queryStr)
+				}
+				value /= metricCfg.CapacityPerServer
+			default:
+				if value < 0 {
+					return core.AssetStatus{}, fmt.Errorf("expected value between 0..1, but got negative value from Prometheus query: %s", queryStr)
+				}
+				if value > 1 {
+					return core.AssetStatus{}, fmt.Errorf("expected value between 0..1, but got larger value from Prometheus query: %s", queryStr)
+				}
 			}
 			result.Usage[metric] += value
 		}
@@ -222,7 +284,15 @@ func (m *assetManagerServerGroups) SetAssetSize(ctx context.Context, res db.Reso
 		return m.terminateServers(ctx, res, cfg, group, oldSize-newSize)
 	}
 	if newSize > oldSize {
-		return m.createServers(ctx, res, cfg, group, newSize-oldSize)
+		// if an earlier downscaling was interrupted while draining servers, put
+		// those servers back into service since we need the capacity after all
+		if cfg.Drain.IsSome() {
+			err := m.undrainServers(ctx, res, cfg, group)
+			if err != nil {
+				return castellum.OperationOutcomeErrored, err
+			}
+		}
+		return m.createServers(ctx, res, cfg, group, make([]string, newSize-oldSize))
 	}
 
 	// nothing to do (should be unreachable in practice since we would not get called at all when `oldSize == newSize`)
@@ -247,13 +317,9 @@ func (m *assetManagerServerGroups) terminateServers(ctx context.Context, res db.
 	}
 
 	// get creation timestamps for all servers in this group
-	var allServers []*servers.Server
-	for _, serverID := range group.Members {
-		server, err := servers.Get(ctx, computeV2, serverID).Extract()
-		if err != nil {
-			return castellum.OperationOutcomeErrored, fmt.Errorf("cannot inspect server %s in %s: %w", serverID, res.AssetType, err)
-		}
-		allServers = append(allServers, server)
+	allServers, err := m.listGroupServers(ctx, res, group, computeV2)
+	if err != nil {
+		return castellum.OperationOutcomeErrored, err
 	}
 
 	// sort servers such that those that we want to delete are in front
@@ -272,11 +338,38 @@ func (m *assetManagerServerGroups) terminateServers(ctx context.Context, res db.
 			return allServers[i].Created.Before(allServers[j].Created)
 		})
 	}
+	// servers that are already being drained always go first, so that an
+	// interrupted drain is resumed instead of starting to drain other servers
+	sort.SliceStable(allServers, func(i, j int) bool {
+		return isServerDraining(allServers[i]) && !isServerDraining(allServers[j])
+	})
+	// if configured, prefer servers in the most-populated AZs
+	allServers = newAZPlacement(cfg, allServers).sortForTermination(allServers)
+	serversToDelete := allServers[:min(countToDelete, uint64(len(allServers)))]
+
+	// if requested, drain servers before deleting them (this usually takes
+	// longer than we want to block, so we return and get called again later)
+	if drain, ok := cfg.Drain.Unpack(); ok {
+		drainingCount, err := m.drainServers(ctx, res, cfg, drain, serversToDelete, computeV2, loadbalancerV2)
+		if err != nil {
+			return castellum.OperationOutcomeErrored, err
+		}
+		if drainingCount > 0 {
+			return castellum.OperationOutcomeErrored, core.ResizeInProgressError{
+				Message:    fmt.Sprintf("waiting for %d servers in %s to be drained", drainingCount, res.AssetType),
+				RetryAfter: ServerDrainPollInterval,
+			}
+		}
+	}
+
+	return m.deleteServers(ctx, res, cfg, serversToDelete, computeV2, loadbalancerV2)
+}
 
-	// delete oldest servers
+// Removes the given servers from their LB pools, deletes them, and waits for
+// the deletion to complete.
+func (m *assetManagerServerGroups) deleteServers(ctx context.Context, res db.Resource, cfg configForServerGroup, serversToDelete []*servers.Server, computeV2, loadbalancerV2 *gophercloud.ServiceClient) (castellum.OperationOutcome, error) {
 	serversInDeletion := make(map[string]string)
-	for idx := 0; uint64(idx) < countToDelete && idx < len(allServers); idx++ {
-		server := allServers[idx]
+	for _, server := range serversToDelete {
 		logg.Info("deleting server %s from %s", server.ID, res.AssetType)
 		for _, lb := range cfg.LoadbalancerPoolMemberships {
 			err := m.removeServerFromLoadbalancer(ctx, server, lb, loadbalancerV2)
@@ -285,7 +378,7 @@ func (m *assetManagerServerGroups) terminateServers(ctx context.Context, res db.
 				return castellum.OperationOutcomeErrored, err
 			}
 		}
-		if len(cfg.LoadbalancerPoolMemberships) > 0 {
+		if len(cfg.LoadbalancerPoolMemberships) > 0 && cfg.Drain.IsNone() {
 			// give some extra time for the server to answer its last outstanding client requests
 			time.Sleep(5 * time.Second)
 		}
@@ -331,78 +424,184 @@ func (m *assetManagerServerGroups) terminateServers(ctx context.Context, res db.
 	return castellum.OperationOutcomeSucceeded, nil
 }
 
-func (m *assetManagerServerGroups) createServers(ctx context.Context, res db.Resource, cfg configForServerGroup, group serverGroup, countToCreate uint64) (castellum.OperationOutcome, error) {
-	provider, eo, err := m.Provider.ProjectScopedClient(ctx, core.ProjectScope{
-		ID:        res.ScopeUUID,
-		RoleNames: m.LocalRoleNames,
-	})
-	if err != nil {
-		return castellum.OperationOutcomeErrored, err
+// The metadata key on servers that records when draining started, as a UNIX timestamp.
+const serverDrainStartedAtMetadataKey = "castellum_drain_started_at"
+
+func isServerDraining(server *servers.Server) bool {
+	_, exists := server.Metadata[serverDrainStartedAtMetadataKey]
+	return exists
+}
+
+// Starts draining those of the given servers that are not being drained yet,
+// and returns how many of them are not fully drained yet.
+//
+// The drain state is stored in the server metadata instead of in our own
+// database, so that it survives across multiple calls of SetAssetSize().
+func (m *assetManagerServerGroups) drainServers(ctx context.Context, res db.Resource, cfg configForServerGroup, drain configForDrain, serversToDrain []*servers.Server, computeV2, loadbalancerV2 *gophercloud.ServiceClient) (drainingCount int, err error) {
+	now := time.Now()
+	for _, server := range serversToDrain {
+		startedAtStr, isDraining := server.Metadata[serverDrainStartedAtMetadataKey]
+		if !isDraining {
+			logg.Info("draining server %s in %s", server.ID, res.AssetType)
+			for _, lb := range cfg.LoadbalancerPoolMemberships {
+				err := m.setServerWeightInLoadbalancer(ctx, server, lb, 0, loadbalancerV2)
+				if err != nil {
+					return 0, fmt.Errorf("cannot drain server %s in %s from LB pool %s: %w", server.ID, res.AssetType, lb.PoolUUID, err)
+				}
+			}
+			if drain.WebhookURL != "" {
+				err := callPreTerminationWebhook(ctx, drain.WebhookURL, res, server)
+				if err != nil {
+					return 0, fmt.Errorf("cannot call pre-termination webhook for server %s in %s: %w", server.ID, res.AssetType, err)
+				}
+			}
+			// this is done last, so that the steps above are retried if any of them fail
+			opts := servers.MetadataOpts{serverDrainStartedAtMetadataKey: strconv.FormatInt(now.Unix(), 10)}
+			_, err := servers.UpdateMetadata(ctx, computeV2, server.ID, opts).Extract()
+			if err != nil {
+				return 0, fmt.Errorf("cannot mark server %s in %s as draining: %w", server.ID, res.AssetType, err)
+			}
+			drainingCount++
+			continue
+		}
+
+		// servers are considered drained once the timeout has passed...
+		startedAt, err := strconv.ParseInt(startedAtStr, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot parse metadata %q on server %s in %s: %w", serverDrainStartedAtMetadataKey, server.ID, res.AssetType, err)
+		}
+		if now.Sub(time.Unix(startedAt, 0)) >= time.Duration(drain.TimeoutSeconds)*time.Second {
+			continue
+		}
+
+		// ...or once they do not have any active connections anymore
+		if drain.ActiveConnectionsQuery != "" {
+			queryStr := strings.ReplaceAll(drain.ActiveConnectionsQuery, "${ID}", server.ID)
+			queryStr = strings.ReplaceAll(queryStr, "${NAME}", server.Name)
+			value, err := m.Prometheus.GetSingleValue(ctx, queryStr, nil)
+			if err != nil && !promquery.IsErrNoRows(err) {
+				return 0, err
+			}
+			// if there is no data (yet), we cannot be sure and keep waiting for the timeout
+			if err == nil && value == 0 {
+				continue
+			}
+		}
+		drainingCount++
 	}
-	computeV2, err := openstack.NewComputeV2(provider, eo)
+	return drainingCount, nil
+}
+
+func (m *assetManagerServerGroups) undrainServers(ctx context.Context, res db.Resource, cfg configForServerGroup, group serverGroup) error {
+	clients, err := m.getProjectClients(ctx, res)
 	if err != nil {
-		return castellum.OperationOutcomeErrored, err
+		return err
 	}
-	imageV2, err := openstack.NewImageV2(provider, eo)
+	computeV2, loadbalancerV2 := clients.ComputeV2, clients.LoadbalancerV2
+
+	for _, serverID := range group.Members {
+		server, err := servers.Get(ctx, computeV2, serverID).Extract()
+		if err != nil {
+			return fmt.Errorf("cannot inspect server %s in %s: %w", serverID, res.AssetType, err)
+		}
+		if !isServerDraining(server) {
+			continue
+		}
+
+		logg.Info("putting server %s in %s back into service", server.ID, res.AssetType)
+		for _, lb := range cfg.LoadbalancerPoolMemberships {
+			// LB pool members are created with the default weight of 1 in addServerToLoadbalancer()
+			err := m.setServerWeightInLoadbalancer(ctx, server, lb, 1, loadbalancerV2)
+			if err != nil {
+				return fmt.Errorf("cannot undrain server %s in %s from LB pool %s: %w", server.ID, res.AssetType, lb.PoolUUID, err)
+			}
+		}
+		err = servers.DeleteMetadatum(ctx, computeV2, server.ID, serverDrainStartedAtMetadataKey).ExtractErr()
+		if err != nil {
+			return fmt.Errorf("cannot unmark server %s in %s as draining: %w", server.ID, res.AssetType, err)
+		}
+	}
+	return nil
+}
+
+// Payload for the pre-termination webhook.
+type preTerminationWebhookPayload struct {
+	AssetType  db.AssetType `json:"asset_type"`
+	ProjectID  string       `json:"project_id"`
+	ServerID   string       `json:"server_id"`
+	ServerName string       `json:"server_name"`
+}
+
+var preTerminationWebhookClient = &http.Client{Timeout: 30 * time.Second}
+
+func callPreTerminationWebhook(ctx context.Context, webhookURL string, res db.Resource, server *servers.Server) error {
+	buf, err := json.Marshal(preTerminationWebhookPayload{
+		AssetType:  res.AssetType,
+		ProjectID:  res.ScopeUUID,
+		ServerID:   server.ID,
+		ServerName: server.Name,
+	})
 	if err != nil {
-		return castellum.OperationOutcomeErrored, err
+		return err
 	}
-	keymgrV1, err := openstack.NewKeyManagerV1(provider, eo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(buf))
 	if err != nil {
-		return castellum.OperationOutcomeErrored, err
+		return err
 	}
-	loadbalancerV2, err := openstack.NewLoadBalancerV2(provider, eo)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := preTerminationWebhookClient.Do(req)
 	if err != nil {
-		return castellum.OperationOutcomeErrored, err
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// include the start of the response body to aid in debugging
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
 	}
+	return nil
+}
 
-	resolvedImageID, err := m.resolveImageIntoID(ctx, imageV2, cfg.Template.Image.Name)
+// Creates one server for each entry in `replacesServerIDs`. Non-empty entries
+// are recorded in the metadata of the respective new server, to mark it as the
+// replacement for the server with that ID.
+func (m *assetManagerServerGroups) createServers(ctx context.Context, res db.Resource, cfg configForServerGroup, group serverGroup, replacesServerIDs []string) (castellum.OperationOutcome, error) {
+	clients, err := m.getProjectClients(ctx, res)
 	if err != nil {
-		return Classify(err)
+		return castellum.OperationOutcomeErrored, err
 	}
-	resolvedFlavorID, err := m.resolveFlavorIntoID(ctx, computeV2, cfg.Template.Flavor.Name)
+	computeV2, loadbalancerV2 := clients.ComputeV2, clients.LoadbalancerV2
+
+	opts, err := m.prepareServerCreation(ctx, cfg, clients)
 	if err != nil {
 		return Classify(err)
 	}
-	resolvedKeypairName, err := m.pullKeypairFromBarbican(ctx, computeV2, keymgrV1, cfg.Template.PublicKey.BarbicanID)
-	if err != nil {
-		return Classify(err)
+	schedulerhints := servers.SchedulerHintOpts{
+		Group: group.ID,
 	}
 
-	// build creation request template
-	var networkOpts []servers.Network
-	for _, net := range cfg.Template.Networks {
-		networkOpts = append(networkOpts, servers.Network{UUID: net.UUID, Tag: net.Tag})
-	}
-	opts := func(name string) (opts servers.CreateOptsBuilder) {
-		opts = servers.CreateOpts{
-			AvailabilityZone: cfg.Template.AvailabilityZone,
-			BlockDevice:      cfg.Template.BlockDeviceMappings,
-			FlavorRef:        resolvedFlavorID,
-			ImageRef:         resolvedImageID,
-			Metadata:         cfg.Template.Metadata,
-			Name:             name,
-			Networks:         networkOpts,
-			SecurityGroups:   cfg.Template.SecurityGroupNames,
-			UserData:         cfg.Template.UserData,
-		}
-		opts = keypairs.CreateOptsExt{
-			CreateOptsBuilder: opts,
-			KeyName:           resolvedKeypairName,
+	// if configured, spread new servers across AZs (the servers that we are
+	// about to replace do not count since they will be deleted afterwards)
+	placement := newAZPlacement(cfg, nil)
+	if len(cfg.AvailabilityZones) > 0 {
+		allServers, err := m.listGroupServers(ctx, res, group, computeV2)
+		if err != nil {
+			return castellum.OperationOutcomeErrored, err
 		}
-		return opts
-	}
-	schedulerhints := servers.SchedulerHintOpts{
-		Group: group.ID,
+		allServers = slices.DeleteFunc(allServers, func(s *servers.Server) bool {
+			return slices.Contains(replacesServerIDs, s.ID)
+		})
+		placement = newAZPlacement(cfg, allServers)
 	}
 
 	// create servers
 	serversInCreation := make(map[string]string)
-	for idx := 0; uint64(idx) < countToCreate; idx++ {
+	for _, replacesServerID := range replacesServerIDs {
 		name := fmt.Sprintf("%s-%s", group.Name, makeNameDisambiguator())
-		logg.Info("creating server %s in %s", name, res.AssetType)
+		az := placement.pickForCreation()
+		logg.Info("creating server %s in %s%s", name, res.AssetType, formatAZForLog(az))
 
-		server, err := servers.Create(ctx, computeV2, opts(name), schedulerhints).Extract()
+		server, err := servers.Create(ctx, computeV2, opts(name, replacesServerID, az), schedulerhints).Extract()
 		if err != nil {
 			err = fmt.Errorf("cannot create server %s in %s: %w", name, res.AssetType, err)
 			if strings.Contains(err.Error(), "Quota exceeded for ") {
@@ -474,6 +673,121 @@ func (m *assetManagerServerGroups) createServers(ctx context.Context, res db.Res
 	return castellum.OperationOutcomeErrored, fmt.Errorf("timeout waiting for server creation in %s: %s", res.AssetType, strings.Join(msgs, ", "))
 }
 
+// projectClients contains the service clients that are used for acting
+// within the project of a server group.
+type projectClients struct {
+	ComputeV2      *gophercloud.ServiceClient
+	ImageV2        *gophercloud.ServiceClient
+	KeymgrV1       *gophercloud.ServiceClient
+	LoadbalancerV2 *gophercloud.ServiceClient
+}
+
+func (m *assetManagerServerGroups) getProjectClients(ctx context.Context, res db.Resource) (c projectClients, err error) {
+	provider, eo, err := m.Provider.ProjectScopedClient(ctx, core.ProjectScope{
+		ID:        res.ScopeUUID,
+		RoleNames: m.LocalRoleNames,
+	})
+	if err != nil {
+		return c, err
+	}
+	c.ComputeV2, err = openstack.NewComputeV2(provider, eo)
+	if err != nil {
+		return c, err
+	}
+	c.ImageV2, err = openstack.NewImageV2(provider, eo)
+	if err != nil {
+		return c, err
+	}
+	c.KeymgrV1, err = openstack.NewKeyManagerV1(provider, eo)
+	if err != nil {
+		return c, err
+	}
+	c.LoadbalancerV2, err = openstack.NewLoadBalancerV2(provider, eo)
+	return c, err
+}
+
+// Metadata keys that we put on servers when creating them, in order to
+// recognize outdated servers during a rolling update.
+const (
+	serverImageNameMetadataKey  = "castellum_image_name"
+	serverFlavorNameMetadataKey = "castellum_flavor_name"
+	// On servers created by a rolling update, this contains the ID of the
+	// outdated server that is being replaced.
+	serverReplacesMetadataKey = "castellum_replaces"
+)
+
+// Builds a function that renders the creation request for a new server. The
+// name references in the template are resolved once upfront, so that the
+// result can be used for creating multiple servers. The AZ is given for each
+// server since it is chosen by type azPlacement.
+func (m *assetManagerServerGroups) prepareServerCreation(ctx context.Context, cfg configForServerGroup, clients projectClients) (func(name, replacesServerID, availabilityZone string) servers.CreateOptsBuilder, error) {
+	resolvedImageID, err := m.resolveImageIntoID(ctx, clients.ImageV2, cfg.Template.Image.Name)
+	if err != nil {
+		return nil, err
+	}
+	resolvedFlavorID, err := m.resolveFlavorIntoID(ctx, clients.ComputeV2, cfg.Template.Flavor.Name)
+	if err != nil {
+		return nil, err
+	}
+	resolvedKeypairName, err := m.pullKeypairFromBarbican(ctx, clients.ComputeV2, clients.KeymgrV1, cfg.Template.PublicKey.BarbicanID)
+	if err != nil {
+		return nil, err
+	}
+
+	var networkOpts []servers.Network
+	for _, net := range cfg.Template.Networks {
+		networkOpts = append(networkOpts, servers.Network{UUID: net.UUID, Tag: net.Tag})
+	}
+	return func(name, replacesServerID, availabilityZone string) (opts servers.CreateOptsBuilder) {
+		metadata := maps.Clone(cfg.Template.Metadata)
+		if metadata == nil {
+			metadata = make(map[string]string, 3)
+		}
+		metadata[serverImageNameMetadataKey] = cfg.Template.Image.Name
+		metadata[serverFlavorNameMetadataKey] = cfg.Template.Flavor.Name
+		if replacesServerID != "" {
+			metadata[serverReplacesMetadataKey] = replacesServerID
+		}
+
+		opts = servers.CreateOpts{
+			AvailabilityZone: availabilityZone,
+			BlockDevice:      cfg.Template.BlockDeviceMappings,
+			FlavorRef:        resolvedFlavorID,
+			ImageRef:         resolvedImageID,
+			Metadata:         metadata,
+			Name:             name,
+			Networks:         networkOpts,
+			SecurityGroups:   cfg.Template.SecurityGroupNames,
+			UserData:         cfg.Template.UserData,
+		}
+		opts = keypairs.CreateOptsExt{
+			CreateOptsBuilder: opts,
+			KeyName:           resolvedKeypairName,
+		}
+		return opts
+	}, nil
+}
+
+// listGroupServers returns all servers in the given server group.
+func (m *assetManagerServerGroups) listGroupServers(ctx context.Context, res db.Resource, group serverGroup, computeV2 *gophercloud.ServiceClient) ([]*servers.Server, error) {
+	result := make([]*servers.Server, 0, len(group.Members))
+	for _, serverID := range group.Members {
+		server, err := servers.Get(ctx, computeV2, serverID).Extract()
+		if err != nil {
+			return nil, fmt.Errorf("cannot inspect server %s in %s: %w", serverID, res.AssetType, err)
+		}
+		result = append(result, server)
+	}
+	return result, nil
+}
+
+func formatAZForLog(az string) string {
+	if az == "" {
+		return ""
+	}
+	return " in AZ " + az
+}
+
 func makeNameDisambiguator() string {
 	// 5 bytes of data encode to exactly 8 base32 characters without padding
 	var buf [5]byte
@@ -522,27 +836,461 @@ func (m *assetManagerServerGroups) addServerToLoadbalancer(ctx context.Context,
 	return err
 }
 
+// Returns whether the server has joined the LB pool, i.e. whether it is a
+// member and considered healthy by the LB. If the server is not a member yet,
+// it is added to the pool.
+func (m *assetManagerServerGroups) ensureServerInLoadbalancer(ctx context.Context, server *servers.Server, cfg configForLBPoolMembership, loadbalancerV2 *gophercloud.ServiceClient) (bool, error) {
+	members, err := m.listLoadbalancerMembers(ctx, server, cfg, loadbalancerV2)
+	if err != nil {
+		return false, err
+	}
+	if len(members) == 0 {
+		return false, m.addServerToLoadbalancer(ctx, server, cfg, loadbalancerV2)
+	}
+	for _, member := range members {
+		// "NO_MONITOR" is reported when the pool does not have a health monitor
+		if member.OperatingStatus != "ONLINE" && member.OperatingStatus != "NO_MONITOR" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func (m *assetManagerServerGroups) removeServerFromLoadbalancer(ctx context.Context, server *servers.Server, cfg configForLBPoolMembership, loadbalancerV2 *gophercloud.ServiceClient) error {
+	members, err := m.listLoadbalancerMembers(ctx, server, cfg, loadbalancerV2)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		err := pools.DeleteMember(ctx, loadbalancerV2, cfg.PoolUUID, member.ID).ExtractErr()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *assetManagerServerGroups) setServerWeightInLoadbalancer(ctx context.Context, server *servers.Server, cfg configForLBPoolMembership, weight int, loadbalancerV2 *gophercloud.ServiceClient) error {
+	members, err := m.listLoadbalancerMembers(ctx, server, cfg, loadbalancerV2)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		_, err := pools.UpdateMember(ctx, loadbalancerV2, cfg.PoolUUID, member.ID, pools.UpdateMemberOpts{Weight: &weight}).Extract()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *assetManagerServerGroups) listLoadbalancerMembers(ctx context.Context, server *servers.Server, cfg configForLBPoolMembership, loadbalancerV2 *gophercloud.ServiceClient) ([]pools.Member, error) {
 	listOpts := pools.ListMembersOpts{
 		Name: server.Name,
 	}
 	pager, err := pools.ListMembers(loadbalancerV2, cfg.PoolUUID, listOpts).AllPages(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	members, err := pools.ExtractMembers(pager)
+	return pools.ExtractMembers(pager)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// rolling updates
+
+// MaintainAsset implements the core.AssetMaintainer interface.
+//
+// If configured, a rolling update replaces servers that were not created
+// from the current image and flavor in the template. For each outdated
+// server, a replacement is created first. Once the replacement is ACTIVE and
+// has joined all LB pools, the outdated server is drained (if configured) and
+// deleted. All state is kept in the server metadata, so that each call only
+// needs to perform the next step.
+func (m *assetManagerServerGroups) MaintainAsset(ctx context.Context, res db.Resource, assetUUID string) (string, error) {
+	cfg, err := m.parseAndValidateConfig(res.ConfigJSON)
 	if err != nil {
-		return err
+		return "", err
 	}
-	for _, member := range members {
-		err := pools.DeleteMember(ctx, loadbalancerV2, cfg.PoolUUID, member.ID).ExtractErr()
+	rollingUpdate, ok := cfg.RollingUpdate.Unpack()
+	if !ok {
+		return "", nil
+	}
+
+	groupID := strings.TrimPrefix(string(res.AssetType), "server-group:")
+	group, err := m.getServerGroup(ctx, groupID)
+	if err != nil {
+		return "", fmt.Errorf("cannot GET server group: %w", err)
+	}
+	clients, err := m.getProjectClients(ctx, res)
+	if err != nil {
+		return "", err
+	}
+
+	// find outdated servers, and replacements that are up to date
+	allServers, err := m.listGroupServers(ctx, res, group, clients.ComputeV2)
+	if err != nil {
+		return "", err
+	}
+	var (
+		outdatedServers []*servers.Server
+		replacements    []*servers.Server
+		isGroupMember   = make(map[string]bool, len(group.Members))
+	)
+	for _, server := range allServers {
+		isGroupMember[server.ID] = true
+		switch {
+		case !isServerUpToDate(server, cfg):
+			// servers that were shut off by the user are treated as non-existent
+			// in GetAssetStatus(), so we do not bring them back to life either
+			if server.Status != "SHUTOFF" {
+				outdatedServers = append(outdatedServers, server)
+			}
+		case server.Metadata[serverReplacesMetadataKey] != "":
+			replacements = append(replacements, server)
+		}
+	}
+	// replace the oldest servers first
+	sort.Slice(outdatedServers, func(i, j int) bool {
+		return outdatedServers[i].Created.Before(outdatedServers[j].Created)
+	})
+
+	// check on replacements that were created in earlier calls
+	var (
+		inProgressCount  int
+		unavailableCount int
+		readyReplacement = make(map[string]*servers.Server)
+		notReadyYet      = make(map[string]*servers.Server)
+	)
+	for _, server := range replacements {
+		ready, err := m.checkReplacementServer(ctx, res, cfg, server, clients)
 		if err != nil {
+			return "", err
+		}
+		replacedID := server.Metadata[serverReplacesMetadataKey]
+		switch {
+		case isGroupMember[replacedID] && ready:
+			readyReplacement[replacedID] = server
+			inProgressCount++
+		case isGroupMember[replacedID]:
+			notReadyYet[replacedID] = server
+			inProgressCount++
+		case !ready:
+			// the outdated server was deleted before its replacement was ready
+			inProgressCount++
+			unavailableCount++
+		default:
+			// the replacement is complete, so we do not need to track it anymore
+			err := servers.DeleteMetadatum(ctx, clients.ComputeV2, server.ID, serverReplacesMetadataKey).ExtractErr()
+			if err != nil {
+				return "", fmt.Errorf("cannot unmark server %s in %s as replacement: %w", server.ID, res.AssetType, err)
+			}
+		}
+	}
+
+	placement := newAZPlacement(cfg, allServers)
+
+	// retire outdated servers once their replacement is ready (or earlier, if
+	// we are allowed to have some unavailable servers), or create replacements
+	var opts func(name, replacesServerID, availabilityZone string) servers.CreateOptsBuilder
+	for _, server := range outdatedServers {
+		_, isReady := readyReplacement[server.ID]
+		_, isNotReady := notReadyYet[server.ID]
+		switch {
+		case isReady:
+			err := m.retireOutdatedServer(ctx, res, cfg, server, clients)
+			if err != nil {
+				return "", err
+			}
+		case isNotReady && unavailableCount < int(rollingUpdate.MaxUnavailable):
+			err := m.retireOutdatedServer(ctx, res, cfg, server, clients)
+			if err != nil {
+				return "", err
+			}
+			unavailableCount++
+		case !isNotReady && inProgressCount < int(rollingUpdate.BatchSize):
+			if opts == nil {
+				opts, err = m.prepareServerCreation(ctx, cfg, clients)
+				if err != nil {
+					return "", err
+				}
+			}
+			name := fmt.Sprintf("%s-%s", group.Name, makeNameDisambiguator())
+			placement.forget(server)
+			az := placement.pickForCreation()
+			logg.Info("creating server %s in %s%s to replace outdated server %s", name, res.AssetType, formatAZForLog(az), server.ID)
+			_, err := servers.Create(ctx, clients.ComputeV2, opts(name, server.ID, az), servers.SchedulerHintOpts{Group: group.ID}).Extract()
+			if err != nil {
+				return "", fmt.Errorf("cannot create server %s in %s: %w", name, res.AssetType, err)
+			}
+			inProgressCount++
+		}
+	}
+
+	if len(outdatedServers) == 0 && inProgressCount == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("rolling update in progress: %d outdated servers remaining, %d replacements in progress",
+		len(outdatedServers), inProgressCount), nil
+}
+
+func isServerUpToDate(server *servers.Server, cfg configForServerGroup) bool {
+	return server.Metadata[serverImageNameMetadataKey] == cfg.Template.Image.Name &&
+		server.Metadata[serverFlavorNameMetadataKey] == cfg.Template.Flavor.Name
+}
+
+// Returns whether the replacement server is ready to take over from the
+// server that it replaces, i.e. it is ACTIVE and has joined all LB pools.
+func (m *assetManagerServerGroups) checkReplacementServer(ctx context.Context, res db.Resource, cfg configForServerGroup, server *servers.Server, clients projectClients) (bool, error) {
+	switch server.Status {
+	case "ACTIVE":
+		ready := true
+		for _, lb := range cfg.LoadbalancerPoolMemberships {
+			joined, err := m.ensureServerInLoadbalancer(ctx, server, lb, clients.LoadbalancerV2)
+			if err != nil {
+				return false, fmt.Errorf("cannot add server %s in %s to LB pool %s: %w", server.ID, res.AssetType, lb.PoolUUID, err)
+			}
+			ready = ready && joined
+		}
+		return ready, nil
+	case "ERROR":
+		return false, fmt.Errorf("replacement server %s in %s is in status %s", server.ID, res.AssetType, server.Status)
+	default:
+		// keep waiting for this server to get into ACTIVE (or ERROR)
+		return false, nil
+	}
+}
+
+// Drains (if configured) and deletes the given server. We do not wait for the
+// deletion to complete: As long as the server still exists, this will be
+// called again during the next MaintainAsset().
+func (m *assetManagerServerGroups) retireOutdatedServer(ctx context.Context, res db.Resource, cfg configForServerGroup, server *servers.Server, clients projectClients) error {
+	if drain, ok := cfg.Drain.Unpack(); ok {
+		drainingCount, err := m.drainServers(ctx, res, cfg, drain, []*servers.Server{server}, clients.ComputeV2, clients.LoadbalancerV2)
+		if err != nil || drainingCount > 0 {
 			return err
 		}
 	}
+
+	logg.Info("deleting outdated server %s from %s", server.ID, res.AssetType)
+	for _, lb := range cfg.LoadbalancerPoolMemberships {
+		err := m.removeServerFromLoadbalancer(ctx, server, lb, clients.LoadbalancerV2)
+		if err != nil {
+			return fmt.Errorf("cannot remove server %s in %s from LB pool %s: %w", server.ID, res.AssetType, lb.PoolUUID, err)
+		}
+	}
+	err := servers.Delete(ctx, clients.ComputeV2, server.ID).ExtractErr()
+	if err != nil && !gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+		return fmt.Errorf("cannot delete server %s in %s: %w", server.ID, res.AssetType, err)
+	}
 	return nil
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// self-healing
+
+// Servers that are younger than this are not checked for health, since they
+// may still be booting. Usage metrics are also allowed to be missing for them.
+const serverStartupGracePeriod = 10 * time.Minute
+
+// Returns those of the given servers that need to be replaced because they
+// are in status ERROR, or because they are failing the health checks of any
+// of their LB pools.
+func (m *assetManagerServerGroups) findBrokenServers(ctx context.Context, res db.Resource, cfg configForServerGroup, allServers []*servers.Server) ([]*servers.Server, error) {
+	// pool members are named after their servers (see addServerToLoadbalancer)
+	isFailingHealthChecks := make(map[string]bool)
+	if len(cfg.LoadbalancerPoolMemberships) > 0 {
+		loadbalancerV2, err := m.Provider.CloudAdminClient(openstack.NewLoadBalancerV2)
+		if err != nil {
+			return nil, err
+		}
+		for _, lb := range cfg.LoadbalancerPoolMemberships {
+			pager, err := pools.ListMembers(loadbalancerV2, lb.PoolUUID, pools.ListMembersOpts{}).AllPages(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("cannot list members of LB pool %s for %s: %w", lb.PoolUUID, res.AssetType, err)
+			}
+			members, err := pools.ExtractMembers(pager)
+			if err != nil {
+				return nil, fmt.Errorf("cannot list members of LB pool %s for %s: %w", lb.PoolUUID, res.AssetType, err)
+			}
+			for _, member := range members {
+				if member.OperatingStatus == "ERROR" {
+					isFailingHealthChecks[member.Name] = true
+				}
+			}
+		}
+	}
+
+	var result []*servers.Server
+	for _, server := range allServers {
+		switch {
+		case server.Status == "ERROR":
+			result = append(result, server)
+		case server.Status != "ACTIVE" || isServerDraining(server) || time.Since(server.Created) < serverStartupGracePeriod:
+			// health checks are not meaningful for these servers
+			continue
+		case isFailingHealthChecks[server.Name]:
+			result = append(result, server)
+		}
+	}
+	return result, nil
+}
+
+// RepairAsset implements the core.AssetRepairer interface.
+//
+// Broken servers (as determined by findBrokenServers) are replaced by new
+// servers created from the template. The replacements are created first, and
+// are marked as such in their metadata, so that a retry of this operation
+// does not create them again.
+func (m *assetManagerServerGroups) RepairAsset(ctx context.Context, res db.Resource, assetUUID string) (castellum.OperationOutcome, error) {
+	cfg, err := m.parseAndValidateConfig(res.ConfigJSON)
+	if err != nil {
+		// see comment in SetAssetSize()
+		return castellum.OperationOutcomeErrored, err
+	}
+	selfHealing, ok := cfg.SelfHealing.Unpack()
+	if !ok {
+		// self-healing was disabled after this operation was created
+		return castellum.OperationOutcomeSucceeded, nil
+	}
+
+	groupID := strings.TrimPrefix(string(res.AssetType), "server-group:")
+	group, err := m.getServerGroup(ctx, groupID)
+	if err != nil {
+		return castellum.OperationOutcomeErrored, err
+	}
+	clients, err := m.getProjectClients(ctx, res)
+	if err != nil {
+		return castellum.OperationOutcomeErrored, err
+	}
+
+	allServers, err := m.listGroupServers(ctx, res, group, clients.ComputeV2)
+	if err != nil {
+		return castellum.OperationOutcomeErrored, err
+	}
+	brokenServers, err := m.findBrokenServers(ctx, res, cfg, allServers)
+	if err != nil {
+		return castellum.OperationOutcomeErrored, err
+	}
+	brokenServers = brokenServers[:min(len(brokenServers), int(selfHealing.BatchSize))]
+	if len(brokenServers) == 0 {
+		return castellum.OperationOutcomeSucceeded, nil
+	}
+
+	// create replacements, unless an earlier attempt already did so
+	hasReplacement := make(map[string]bool)
+	for _, server := range allServers {
+		if replacedID := server.Metadata[serverReplacesMetadataKey]; replacedID != "" {
+			hasReplacement[replacedID] = true
+		}
+	}
+	var replacesServerIDs []string
+	for _, server := range brokenServers {
+		logg.Info("replacing broken server %s in %s (status %s)", server.ID, res.AssetType, server.Status)
+		if !hasReplacement[server.ID] {
+			replacesServerIDs = append(replacesServerIDs, server.ID)
+		}
+	}
+	if len(replacesServerIDs) > 0 {
+		outcome, err := m.createServers(ctx, res, cfg, group, replacesServerIDs)
+		if err != nil {
+			return outcome, err
+		}
+	}
+
+	return m.deleteServers(ctx, res, cfg, brokenServers, clients.ComputeV2, clients.LoadbalancerV2)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// placement of servers in availability zones
+
+// Values for configForServerGroup.PlacementStrategy.
+const (
+	// New servers are created in the least-populated AZ, and downscaling
+	// terminates servers in the most-populated AZ first.
+	placementStrategySpread = "spread"
+)
+
+// azPlacement tracks how many servers of a server group are located in each
+// AZ, in order to choose the AZs for new servers and the servers to terminate.
+type azPlacement struct {
+	// empty if `availability_zones` is not configured
+	zones       []string
+	defaultZone string
+	counts      map[string]int
+}
+
+// Servers that are being replaced (as indicated by the metadata of their
+// replacements) are not counted since they will be deleted soon.
+func newAZPlacement(cfg configForServerGroup, allServers []*servers.Server) *azPlacement {
+	p := &azPlacement{
+		zones:       cfg.AvailabilityZones,
+		defaultZone: cfg.Template.AvailabilityZone,
+		counts:      make(map[string]int),
+	}
+	isReplaced := make(map[string]bool)
+	for _, server := range allServers {
+		if replacedID := server.Metadata[serverReplacesMetadataKey]; replacedID != "" {
+			isReplaced[replacedID] = true
+		}
+	}
+	for _, server := range allServers {
+		if !isReplaced[server.ID] {
+			p.counts[server.AvailabilityZone]++
+		}
+	}
+	return p
+}
+
+// forget removes the given server from the counts, e.g. because it is about
+// to be replaced.
+func (p *azPlacement) forget(server *servers.Server) {
+	p.counts[server.AvailabilityZone]--
+}
+
+// pickForCreation returns the AZ for a new server, and counts the new server
+// in it. Ties are broken by the order of `availability_zones`.
+func (p *azPlacement) pickForCreation() string {
+	if len(p.zones) == 0 {
+		return p.defaultZone
+	}
+	result := p.zones[0]
+	for _, zone := range p.zones[1:] {
+		if p.counts[zone] < p.counts[result] {
+			result = zone
+		}
+	}
+	p.counts[result]++
+	return result
+}
+
+// sortForTermination reorders the given servers (which are already sorted by
+// preference for termination) such that terminating servers from the front of
+// the list keeps the AZs balanced. Among servers in equally populated AZs, the
+// original order is kept. Servers that are being drained stay in front.
+func (p *azPlacement) sortForTermination(candidates []*servers.Server) []*servers.Server {
+	if len(p.zones) == 0 {
+		return candidates
+	}
+	counts := maps.Clone(p.counts)
+	remaining := slices.Clone(candidates)
+	result := make([]*servers.Server, 0, len(candidates))
+	for len(remaining) > 0 {
+		bestIdx := 0
+		if !isServerDraining(remaining[0]) {
+			for idx, server := range remaining {
+				if counts[server.AvailabilityZone] > counts[remaining[bestIdx].AvailabilityZone] {
+					bestIdx = idx
+				}
+			}
+		}
+		server := remaining[bestIdx]
+		result = append(result, server)
+		counts[server.AvailabilityZone]--
+		remaining = slices.Delete(remaining, bestIdx, bestIdx+1)
+	}
+	return result
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // resource configuration
 
@@ -552,8 +1300,35 @@ type configForLBPoolMembership struct {
 	MonitorPort  uint16 `json:"monitor_port"`
 }
 
+type configForDrain struct {
+	TimeoutSeconds         uint32 `json:"timeout_secs"`
+	ActiveConnectionsQuery string `json:"active_connections_query"`
+	WebhookURL             string `json:"pre_termination_webhook_url"`
+}
+
+type configForRollingUpdate struct {
+	BatchSize      uint32 `json:"batch_size"`
+	MaxUnavailable uint32 `json:"max_unavailable"`
+}
+
+type configForSelfHealing struct {
+	BatchSize uint32 `json:"batch_size"`
+}
+
+type configForUsageMetric struct {
+	Query             string  `json:"query"`
+	Normalization     string  `json:"normalization"`
+	CapacityPerServer float64 `json:"capacity_per_server"`
+}
+
 type configForServerGroup struct {
-	DeleteNewestFirst bool `json:"delete_newest_first"`
+	DeleteNewestFirst bool                                           `json:"delete_newest_first"`
+	Drain             Option[configForDrain]                         `json:"drain"`
+	RollingUpdate     Option[configForRollingUpdate]                 `json:"rolling_update"`
+	SelfHealing       Option[configForSelfHealing]                   `json:"self_healing"`
+	UsageMetrics      map[castellum.UsageMetric]configForUsageMetric `json:"usage_metrics"`
+	AvailabilityZones []string                                       `json:"availability_zones"`
+	PlacementStrategy string                                         `json:"placement_strategy"`
 	Template          struct {
 		AvailabilityZone    string                `json:"availability_zone"`
 		BlockDeviceMappings []servers.BlockDevice `json:"block_device_mapping_v2,omitempty"`
@@ -577,6 +1352,14 @@ type configForServerGroup struct {
 	LoadbalancerPoolMemberships []configForLBPoolMembership `json:"loadbalancer_pool_memberships"`
 }
 
+// Metadata keys that Castellum maintains on servers.
+var reservedMetadataKeys = []string{
+	serverDrainStartedAtMetadataKey,
+	serverImageNameMetadataKey,
+	serverFlavorNameMetadataKey,
+	serverReplacesMetadataKey,
+}
+
 var validBDMSourceTypes = []servers.SourceType{
 	servers.SourceBlank,
 	servers.SourceImage,
@@ -589,6 +1372,17 @@ var validBDMDestinationTypes = []servers.DestinationType{
 	servers.DestinationVolume,
 }
 
+// allUsageMetrics returns the builtin usage metrics as well as those
+// configured in `usage_metrics`.
+func (cfg configForServerGroup) allUsageMetrics() map[castellum.UsageMetric]configForUsageMetric {
+	result := make(map[castellum.UsageMetric]configForUsageMetric, len(serverUsageQueries)+len(cfg.UsageMetrics))
+	for metric, query := range serverUsageQueries {
+		result[metric] = configForUsageMetric{Query: query, Normalization: usageNormalizationRatio}
+	}
+	maps.Copy(result, cfg.UsageMetrics)
+	return result
+}
+
 func joinStrings[S ~string](inputs []S, separator string) string {
 	outputs := make([]string, len(inputs))
 	for idx, input := range inputs {
@@ -636,6 +1430,76 @@ func (m *assetManagerServerGroups) parseAndValidateConfig(configJSON string) (co
 			complain("loadbalancer_pool_memberships[%d].protocol_port is missing", idx)
 		}
 	}
+	if drain, ok := cfg.Drain.Unpack(); ok {
+		if drain.TimeoutSeconds == 0 {
+			complain("drain.timeout_secs is missing")
+		}
+		if drain.WebhookURL != "" {
+			u, err := url.Parse(drain.WebhookURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				complain("drain.pre_termination_webhook_url is not a valid HTTP(S) URL")
+			}
+		}
+	}
+	if rollingUpdate, ok := cfg.RollingUpdate.Unpack(); ok {
+		if rollingUpdate.BatchSize == 0 {
+			complain("rolling_update.batch_size is missing")
+		}
+		if rollingUpdate.MaxUnavailable > rollingUpdate.BatchSize {
+			complain("rolling_update.max_unavailable must not be larger than rolling_update.batch_size")
+		}
+	}
+	if selfHealing, ok := cfg.SelfHealing.Unpack(); ok {
+		if selfHealing.BatchSize == 0 {
+			complain("self_healing.batch_size is missing")
+		}
+	}
+	for _, metric := range slices.Sorted(maps.Keys(cfg.UsageMetrics)) {
+		metricCfg := cfg.UsageMetrics[metric]
+		_, isBuiltin := serverUsageQueries[metric]
+		switch {
+		case isBuiltin:
+			complain("usage_metrics[%q] conflicts with a builtin usage metric", metric)
+		case !usageMetricNameRx.MatchString(string(metric)):
+			complain("key for usage_metrics[%q] must match /%s/", metric, usageMetricNameRx.String())
+		}
+		if metricCfg.Query == "" {
+			complain("usage_metrics[%q].query is missing", metric)
+		}
+		switch metricCfg.Normalization {
+		case usageNormalizationRatio:
+			if metricCfg.CapacityPerServer != 0 {
+				complain("usage_metrics[%q].capacity_per_server must not be set for normalization %q", metric, usageNormalizationRatio)
+			}
+		case usageNormalizationAbsolute:
+			if metricCfg.CapacityPerServer <= 0 {
+				complain("usage_metrics[%q].capacity_per_server must be greater than 0 for normalization %q", metric, usageNormalizationAbsolute)
+			}
+		case "":
+			complain("usage_metrics[%q].normalization is missing", metric)
+		default:
+			complain("value for usage_metrics[%q].normalization must be either %q or %q", metric, usageNormalizationRatio, usageNormalizationAbsolute)
+		}
+	}
+	for idx, az := range cfg.AvailabilityZones {
+		if az == "" {
+			complain("availability_zones[%d] is empty", idx)
+		} else if slices.Index(cfg.AvailabilityZones, az) < idx {
+			complain("availability_zones[%d] is a duplicate of an earlier entry", idx)
+		}
+	}
+	if len(cfg.AvailabilityZones) > 0 && cfg.Template.AvailabilityZone != "" {
+		complain("template.availability_zone must not be set when availability_zones is set")
+	}
+	switch cfg.PlacementStrategy {
+	case "", placementStrategySpread:
+		// "spread" is the default (and currently the only strategy)
+	default:
+		complain("value for placement_strategy must be %q", placementStrategySpread)
+	}
+	if cfg.PlacementStrategy != "" && len(cfg.AvailabilityZones) == 0 {
+		complain("placement_strategy requires availability_zones to be set")
+	}
 	if cfg.Template.Flavor.Name == "" {
 		complain("template.flavor.name is missing")
 	}
@@ -649,6 +1513,9 @@ func (m *assetManagerServerGroups) parseAndValidateConfig(configJSON string) (co
 		if len(v) > 255 {
 			complain("value for template.metadata[%q] is too long (%d bytes, but max is 255 bytes)", k, len(v))
 		}
+		if slices.Contains(reservedMetadataKeys, k) {
+			complain("key for template.metadata[%q] is reserved for use by Castellum", k)
+		}
 	}
 	if len(cfg.Template.Networks) == 0 {
 		complain("template.networks is missing")
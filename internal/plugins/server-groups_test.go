@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/servers"
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/must"
+	"github.com/sapcc/go-bits/promquery"
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// stubComputeProviderClient serves all service clients from the same stub Nova.
+type stubComputeProviderClient struct {
+	core.ProviderClient
+	ComputeURL string
+}
+
+func (c stubComputeProviderClient) CloudAdminClient(_ core.ServiceClientFactory) (*gophercloud.ServiceClient, error) {
+	return &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       c.ComputeURL + "/",
+		Type:           "compute",
+	}, nil
+}
+
+// stubNova is a very small subset of the Nova API, with a single server group.
+func stubNova(serverIDs []string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /os-server-groups/group1", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"server_group": map[string]any{
+			"id": "group1", "name": "app", "members": serverIDs, "project_id": "project1",
+		}})
+	})
+	mux.HandleFunc("GET /servers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"server": map[string]any{
+			"id": r.PathValue("id"), "status": "ACTIVE", "created": time.Now().Add(-time.Hour).Format(time.RFC3339),
+		}})
+	})
+	return mux
+}
+
+// stubPrometheusByQuery answers each query with a single value, or with an empty result for unknown queries.
+func stubPrometheusByQuery(t *testing.T, valuesByQuery map[string]float64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		must.SucceedT(t, r.ParseForm())
+		result := []map[string]any{}
+		if value, exists := valuesByQuery[r.Form.Get("query")]; exists {
+			result = append(result, map[string]any{
+				"metric": map[string]string{},
+				"value":  []any{time.Now().Unix(), strconv.FormatFloat(value, 'f', -1, 64)},
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"status": "success",
+			"data":   map[string]any{"resultType": "vector", "result": result},
+		})
+	})
+}
+
+const testServerGroupConfigTemplate = `"template":{"flavor":{"name":"m1.small"},"image":{"name":"ubuntu"},"networks":[{"uuid":"net1"}],"public_key":{"barbican_uuid":"secret1"},"security_groups":["default"]}`
+
+func TestServerGroupsUsageMetricsConfig(t *testing.T) {
+	m := &assetManagerServerGroups{}
+	info := m.InfoForAssetType("server-group:group1").UnwrapOrPanic("server-group asset type not supported")
+	assert.Equal(t, info.UsageMetrics, []castellum.UsageMetric{"cpu", "ram"})
+
+	// custom usage metrics are reported in addition to the builtin ones
+	configJSON := `{` + testServerGroupConfigTemplate + `,"usage_metrics":{
+		"requests":{"query":"sum(rate(http_requests_total{server_id=\"${ID}\"}[5m]))","normalization":"absolute","capacity_per_server":100},
+		"queue":{"query":"queue_fill_ratio{project_id=\"${PROJECT_ID}\",server_id=\"${ID}\"}","normalization":"ratio"}
+	}}`
+	assert.Equal(t, m.InfoForResource(info, configJSON).UsageMetrics, []castellum.UsageMetric{"cpu", "queue", "ram", "requests"})
+	// invalid configuration falls back to the builtin metrics
+	assert.Equal(t, m.InfoForResource(info, `{"foo":"bar"}`).UsageMetrics, []castellum.UsageMetric{"cpu", "ram"})
+
+	_, err := m.parseAndValidateConfig(`{` + testServerGroupConfigTemplate + `,"usage_metrics":{
+		"cpu":{"query":"foo","normalization":"ratio"},
+		"Bad-Name":{"query":"foo","normalization":"ratio","capacity_per_server":10},
+		"empty":{"normalization":"absolute"},
+		"unknown":{"query":"foo","normalization":"percent"}
+	}}`)
+	assert.ErrEqual(t, err, `configuration is invalid: `+
+		`key for usage_metrics["Bad-Name"] must match /^[a-z][a-z0-9_]*$/, `+
+		`usage_metrics["Bad-Name"].capacity_per_server must not be set for normalization "ratio", `+
+		`usage_metrics["cpu"] conflicts with a builtin usage metric, `+
+		`usage_metrics["empty"].query is missing, `+
+		`usage_metrics["empty"].capacity_per_server must be greater than 0 for normalization "absolute", `+
+		`value for usage_metrics["unknown"].normalization must be either "ratio" or "absolute"`)
+}
+
+func TestServerGroupsGetAssetStatusWithCustomUsageMetrics(t *testing.T) {
+	novaServer := httptest.NewServer(stubNova([]string{"server1", "server2"}))
+	t.Cleanup(novaServer.Close)
+	valuesByQuery := map[string]float64{
+		`requests_per_second{group="group1",server="server1"}`:  150,
+		`requests_per_second{group="group1",server="server2"}`:  50,
+		`queue_fill_ratio{project="project1",server="server1"}`: 0.5,
+		`queue_fill_ratio{project="project1",server="server2"}`: 0.25,
+	}
+	for _, queryTemplate := range serverUsageQueries {
+		for _, serverID := range []string{"server1", "server2"} {
+			valuesByQuery[strings.ReplaceAll(queryTemplate, "${ID}", serverID)] = 0.1
+		}
+	}
+	promServer := httptest.NewServer(stubPrometheusByQuery(t, valuesByQuery))
+	t.Cleanup(promServer.Close)
+
+	m := &assetManagerServerGroups{
+		Provider:   stubComputeProviderClient{ComputeURL: novaServer.URL},
+		Prometheus: must.ReturnT(promquery.Config{ServerURL: promServer.URL}.Connect())(t),
+	}
+	res := db.Resource{
+		ScopeUUID: "project1",
+		AssetType: "server-group:group1",
+		ConfigJSON: `{` + testServerGroupConfigTemplate + `,"usage_metrics":{
+			"requests":{"query":"requests_per_second{group=\"${GROUP_ID}\",server=\"${ID}\"}","normalization":"absolute","capacity_per_server":100},
+			"queue":{"query":"queue_fill_ratio{project=\"${PROJECT_ID}\",server=\"${ID}\"}","normalization":"ratio"}
+		}}`,
+	}
+
+	status, err := m.GetAssetStatus(t.Context(), res, "group1", None[core.AssetStatus]())
+	must.SucceedT(t, err)
+	assert.Equal(t, status.Size, 2)
+	assert.Equal(t, status.Usage, castellum.UsageValues{
+		"cpu":      0.2,
+		"ram":      0.2,
+		"requests": 2, // (150 + 50) / 100
+		"queue":    0.75,
+	})
+
+	// values outside of 0..1 are rejected for normalization "ratio"
+	valuesByQuery[`queue_fill_ratio{project="project1",server="server2"}`] = 1.5
+	_, err = m.GetAssetStatus(t.Context(), res, "group1", None[core.AssetStatus]())
+	assert.ErrEqual(t, err, `expected value between 0..1, but got larger value from Prometheus query: queue_fill_ratio{project="project1",server="server2"}`)
+}
+
+func TestServerGroupsAZPlacement(t *testing.T) {
+	cfg := configForServerGroup{AvailabilityZones: []string{"az-a", "az-b", "az-c"}}
+	makeServer := func(id, az string, metadata map[string]string) *servers.Server {
+		return &servers.Server{ID: id, AvailabilityZone: az, Metadata: metadata}
+	}
+	allServers := []*servers.Server{
+		makeServer("s1", "az-a", nil),
+		makeServer("s2", "az-a", map[string]string{serverDrainStartedAtMetadataKey: "2026-01-01T00:00:00Z"}),
+		makeServer("s3", "az-b", nil),
+		makeServer("s4", "az-a", nil),
+		makeServer("s5", "az-c", map[string]string{serverReplacesMetadataKey: "s6"}),
+		makeServer("s6", "az-b", nil), // being replaced by s5, so it does not count
+	}
+	serverIDs := func(list []*servers.Server) []string {
+		result := make([]string, len(list))
+		for idx, server := range list {
+			result[idx] = server.ID
+		}
+		return result
+	}
+
+	// new servers go into the least-populated AZ, with ties broken by config order
+	p := newAZPlacement(cfg, allServers)
+	assert.Equal(t, p.pickForCreation(), "az-b")
+	assert.Equal(t, p.pickForCreation(), "az-c")
+	assert.Equal(t, p.pickForCreation(), "az-b")
+	assert.Equal(t, p.pickForCreation(), "az-c")
+	assert.Equal(t, p.pickForCreation(), "az-a")
+
+	// termination prefers the most-populated AZ, but draining servers stay in front
+	candidates := []*servers.Server{allServers[1], allServers[2], allServers[4], allServers[0], allServers[3]}
+	p = newAZPlacement(cfg, allServers)
+	assert.Equal(t, serverIDs(p.sortForTermination(candidates)), []string{"s2", "s1", "s3", "s5", "s4"})
+
+	// without `availability_zones`, the template AZ is used and the order is unchanged
+	cfg = configForServerGroup{}
+	cfg.Template.AvailabilityZone = "az-x"
+	p = newAZPlacement(cfg, allServers)
+	assert.Equal(t, p.pickForCreation(), "az-x")
+	assert.Equal(t, serverIDs(p.sortForTermination(candidates)), []string{"s2", "s3", "s5", "s1", "s4"})
+
+	m := &assetManagerServerGroups{}
+	_, err := m.parseAndValidateConfig(`{` + testServerGroupConfigTemplate + `,"availability_zones":["az-a","az-b"],"placement_strategy":"spread"}`)
+	must.SucceedT(t, err)
+	_, err = m.parseAndValidateConfig(`{` + testServerGroupConfigTemplate + `,"availability_zones":["az-a","","az-a"],"placement_strategy":"pack"}`)
+	assert.ErrEqual(t, err, `configuration is invalid: `+
+		`availability_zones[1] is empty, `+
+		`availability_zones[2] is a duplicate of an earlier entry, `+
+		`value for placement_strategy must be "spread"`)
+	_, err = m.parseAndValidateConfig(`{` + testServerGroupConfigTemplate + `,"placement_strategy":"spread"}`)
+	assert.ErrEqual(t, err, `configuration is invalid: placement_strategy requires availability_zones to be set`)
+}
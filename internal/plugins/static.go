@@ -7,7 +7,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
+	"time"
 
 	"github.com/sapcc/go-api-declarations/castellum"
 	. "go.xyrillian.de/gg/option"
@@ -35,6 +37,19 @@ type StaticAsset struct {
 
 	// When true, return a core.AssetNotFoundError from GetAssetStatus().
 	CannotFindAsset bool
+
+	// Reported by DescribeAssets().
+	Name     string
+	Metadata map[string]string
+
+	// Reported by GetAssetStatus().
+	BackendKey string
+
+	// Reported by MaintainAsset().
+	MaintenanceStatus string
+
+	// Reported by GetAssetStatus(), and cleared by RepairAsset().
+	NeedsRepair bool
 }
 
 // AssetManagerStatic is a core.AssetManager for testing purposes. It just
@@ -48,6 +63,7 @@ type AssetManagerStatic struct {
 	UsageMetrics              []castellum.UsageMetric
 	CheckResourceAllowedFails bool
 	SetAssetSizeFails         bool
+	SetAssetSizeInProgress    bool
 	ExpectsConfiguration      bool
 	ConflictsWithAssetType    db.AssetType
 }
@@ -113,6 +129,8 @@ var (
 	errSimulatedGetFailure = errors.New("GetAssetStatus failing as requested")
 	errSimulatedNotFound   = errors.New("GetAssetStatus asset not found in backend")
 	errSimulatedSetFailure = errors.New("SetAssetSize failing as requested")
+
+	errSimulatedNotificationFailure = errors.New("SendNotification failing as requested")
 )
 
 // ListAssets implements the core.AssetManager interface.
@@ -132,6 +150,20 @@ func (m AssetManagerStatic) ListAssets(_ context.Context, res db.Resource) ([]st
 	return uuids, nil
 }
 
+// DescribeAssets implements the core.AssetDescriber interface.
+func (m AssetManagerStatic) DescribeAssets(ctx context.Context, res db.Resource) ([]core.AssetDescription, error) {
+	uuids, err := m.ListAssets(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]core.AssetDescription, len(uuids))
+	for idx, uuid := range uuids {
+		asset := m.Assets[res.ScopeUUID][uuid]
+		result[idx] = core.AssetDescription{UUID: uuid, Name: asset.Name, Metadata: asset.Metadata}
+	}
+	return result, nil
+}
+
 // GetAssetStatus implements the core.AssetManager interface.
 func (m AssetManagerStatic) GetAssetStatus(_ context.Context, res db.Resource, assetUUID string, previousStatus Option[core.AssetStatus]) (core.AssetStatus, error) {
 	if res.AssetType != m.AssetType {
@@ -156,7 +188,7 @@ func (m AssetManagerStatic) GetAssetStatus(_ context.Context, res db.Resource, a
 	if asset.NewSize != 0 {
 		asset.RemainingDelay--
 		if asset.RemainingDelay == 0 {
-			asset = StaticAsset{Size: asset.NewSize, Usage: asset.Usage}
+			asset = StaticAsset{Size: asset.NewSize, Usage: asset.Usage, BackendKey: asset.BackendKey}
 		}
 		assets[assetUUID] = asset
 	}
@@ -166,9 +198,48 @@ func (m AssetManagerStatic) GetAssetStatus(_ context.Context, res db.Resource, a
 		Usage:             castellum.UsageValues{castellum.SingularUsageMetric: float64(asset.Usage)},
 		StrictMinimumSize: asset.StrictMinimumSize,
 		StrictMaximumSize: asset.StrictMaximumSize,
+		BackendKey:        asset.BackendKey,
+		NeedsRepair:       asset.NeedsRepair,
 	}, nil
 }
 
+// MaintainAsset implements the core.AssetMaintainer interface.
+func (m AssetManagerStatic) MaintainAsset(_ context.Context, res db.Resource, assetUUID string) (string, error) {
+	if res.AssetType != m.AssetType {
+		return "", errWrongAssetType
+	}
+	assets, exists := m.Assets[res.ScopeUUID]
+	if !exists {
+		return "", errUnknownProject
+	}
+	asset, exists := assets[assetUUID]
+	if !exists {
+		return "", errUnknownAsset
+	}
+	return asset.MaintenanceStatus, nil
+}
+
+// RepairAsset implements the core.AssetRepairer interface.
+func (m AssetManagerStatic) RepairAsset(_ context.Context, res db.Resource, assetUUID string) (castellum.OperationOutcome, error) {
+	if res.AssetType != m.AssetType {
+		return castellum.OperationOutcomeErrored, errWrongAssetType
+	}
+	assets, exists := m.Assets[res.ScopeUUID]
+	if !exists {
+		return castellum.OperationOutcomeErrored, errUnknownProject
+	}
+	asset, exists := assets[assetUUID]
+	if !exists {
+		return castellum.OperationOutcomeErrored, errUnknownAsset
+	}
+	if m.SetAssetSizeFails {
+		return castellum.OperationOutcomeFailed, errSimulatedSetFailure
+	}
+	asset.NeedsRepair = false
+	assets[assetUUID] = asset
+	return castellum.OperationOutcomeSucceeded, nil
+}
+
 // SetAssetSize implements the core.AssetManager interface.
 func (m AssetManagerStatic) SetAssetSize(_ context.Context, res db.Resource, assetUUID string, oldSize, newSize uint64) (castellum.OperationOutcome, error) {
 	if res.AssetType != m.AssetType {
@@ -191,6 +262,45 @@ func (m AssetManagerStatic) SetAssetSize(_ context.Context, res db.Resource, ass
 	if m.SetAssetSizeFails {
 		return castellum.OperationOutcomeFailed, errSimulatedSetFailure
 	}
+	if m.SetAssetSizeInProgress {
+		return castellum.OperationOutcomeErrored, core.ResizeInProgressError{
+			Message:    "SetAssetSize in progress as requested",
+			RetryAfter: 5 * time.Minute,
+		}
+	}
 	assets[assetUUID] = StaticAsset{Size: newSize, Usage: asset.Usage}
 	return castellum.OperationOutcomeSucceeded, nil
 }
+
+// NotifierStatic is a core.Notifier for testing purposes. It records all
+// notifications that it is asked to deliver instead of sending them anywhere.
+type NotifierStatic struct {
+	// If non-empty, only transitions into these states are of interest.
+	States []castellum.OperationState
+	// When true, all delivery attempts fail.
+	SendNotificationFails bool
+
+	SentNotifications []core.NotificationPayload
+}
+
+// PluginTypeID implements the core.Notifier interface.
+func (n *NotifierStatic) PluginTypeID() string { return "static" }
+
+// Init implements the core.Notifier interface.
+func (n *NotifierStatic) Init(ctx context.Context, provider core.ProviderClient) error {
+	return nil // unused
+}
+
+// IsInterestedIn implements the core.Notifier interface.
+func (n *NotifierStatic) IsInterestedIn(state castellum.OperationState) bool {
+	return len(n.States) == 0 || slices.Contains(n.States, state)
+}
+
+// SendNotification implements the core.Notifier interface.
+func (n *NotifierStatic) SendNotification(_ context.Context, payload core.NotificationPayload) error {
+	if n.SendNotificationFails {
+		return errSimulatedNotificationFailure
+	}
+	n.SentNotifications = append(n.SentNotifications, payload)
+	return nil
+}
@@ -4,6 +4,10 @@
 package plugins
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/errext"
 )
@@ -38,3 +42,36 @@ func Classify(err error) (castellum.OperationOutcome, error) {
 		return castellum.OperationOutcomeErrored, err
 	}
 }
+
+// Converts a host string as reported by Cinder or Manila (e.g.
+// "cinder-volume@netapp-fil01#aggr1") into a value for
+// core.AssetStatus.BackendKey by removing the pool name, since resizes on the
+// same backend affect all its pools.
+func backendKeyFromHost(host string) string {
+	backend, _, _ := strings.Cut(host, "#")
+	return backend
+}
+
+var allOperationStates = []castellum.OperationState{
+	castellum.OperationStateCreated,
+	castellum.OperationStateConfirmed,
+	castellum.OperationStateGreenlit,
+	castellum.OperationStateCancelled,
+	castellum.OperationStateSucceeded,
+	castellum.OperationStateFailed,
+	castellum.OperationStateErrored,
+}
+
+// parseOperationStates parses a comma-separated list of operation states, as
+// used by notifiers to select which state transitions they are interested in.
+func parseOperationStates(input string) (map[castellum.OperationState]bool, error) {
+	result := make(map[castellum.OperationState]bool)
+	for field := range strings.SplitSeq(input, ",") {
+		state := castellum.OperationState(strings.TrimSpace(field))
+		if !slices.Contains(allOperationStates, state) {
+			return nil, fmt.Errorf("invalid operation state: %q", state)
+		}
+		result[state] = true
+	}
+	return result, nil
+}
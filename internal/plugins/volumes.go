@@ -0,0 +1,252 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumetypes"
+	"github.com/prometheus/common/model"
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/promquery"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+type assetTypeVolumes struct {
+	AllVolumes     bool
+	VolumeTypeName string
+}
+
+func parseVolumesAssetType(assetType db.AssetType) Option[assetTypeVolumes] {
+	if assetType == "volumes" {
+		return Some(assetTypeVolumes{AllVolumes: true})
+	}
+	if volumeType, ok := strings.CutPrefix(string(assetType), "volumes-type:"); ok && volumeType != "" {
+		return Some(assetTypeVolumes{AllVolumes: false, VolumeTypeName: volumeType})
+	}
+	return None[assetTypeVolumes]()
+}
+
+func (t assetTypeVolumes) matches(vol volumes.Volume) bool {
+	return t.AllVolumes || vol.VolumeType == t.VolumeTypeName
+}
+
+type assetManagerVolumes struct {
+	Cinder        *gophercloud.ServiceClient
+	VolumeMetrics *promquery.BulkQueryCache[string, cinderVolumeMetrics]
+}
+
+func init() {
+	core.AssetManagerRegistry.Add(func() core.AssetManager { return &assetManagerVolumes{} })
+}
+
+// PluginTypeID implements the core.AssetManager interface.
+func (m *assetManagerVolumes) PluginTypeID() string { return "volumes" }
+
+// Init implements the core.AssetManager interface.
+func (m *assetManagerVolumes) Init(ctx context.Context, provider core.ProviderClient) (err error) {
+	m.Cinder, err = provider.CloudAdminClient(openstack.NewBlockStorageV3)
+	if err != nil {
+		return err
+	}
+	m.Cinder.Microversion = "3.42" // for extending volumes in status "in-use", requires Cinder at least on Pike
+
+	promClient, err := promquery.ConfigFromEnv("CASTELLUM_VOLUMES_PROMETHEUS").Connect()
+	if err != nil {
+		return err
+	}
+	m.VolumeMetrics = promquery.NewBulkQueryCache(cinderVolumeQueries, 30*time.Second, promClient)
+	return nil
+}
+
+// InfoForAssetType implements the core.AssetManager interface.
+func (m *assetManagerVolumes) InfoForAssetType(assetType db.AssetType) Option[core.AssetTypeInfo] {
+	if parseVolumesAssetType(assetType).IsSome() {
+		return Some(core.AssetTypeInfo{
+			AssetType:    assetType,
+			UsageMetrics: []castellum.UsageMetric{castellum.SingularUsageMetric},
+		})
+	}
+	return None[core.AssetTypeInfo]()
+}
+
+// CheckResourceAllowed implements the core.AssetManager interface.
+func (m *assetManagerVolumes) CheckResourceAllowed(ctx context.Context, assetType db.AssetType, scopeUUID, configJSON string, existingResources map[db.AssetType]struct{}) error {
+	if configJSON != "" {
+		return core.ErrNoConfigurationAllowed
+	}
+
+	parsed, ok := parseVolumesAssetType(assetType).Unpack()
+	if !ok {
+		return fmt.Errorf("could not parse asset type %s", assetType)
+	}
+
+	if !parsed.AllVolumes {
+		pages, err := volumetypes.List(m.Cinder, volumetypes.ListOpts{IsPublic: volumetypes.VisibilityDefault}).AllPages(ctx)
+		if err != nil {
+			return fmt.Errorf("while fetching volume types from Cinder API: %w", err)
+		}
+		allVolumeTypes, err := volumetypes.ExtractVolumeTypes(pages)
+		if err != nil {
+			return fmt.Errorf("while fetching volume types from Cinder API: %w", err)
+		}
+		if !slices.ContainsFunc(allVolumeTypes, func(vt volumetypes.VolumeType) bool { return vt.Name == parsed.VolumeTypeName }) {
+			return fmt.Errorf("no such volume type: %q", parsed.VolumeTypeName)
+		}
+	}
+
+	for otherAssetType := range existingResources {
+		parsedOther, ok := parseVolumesAssetType(otherAssetType).Unpack()
+		if ok && parsed.AllVolumes != parsedOther.AllVolumes {
+			return fmt.Errorf("cannot create a %q resource because of possible contradiction with existing %q resource",
+				string(assetType), string(otherAssetType))
+		}
+	}
+
+	return nil
+}
+
+// ListAssets implements the core.AssetManager interface.
+func (m *assetManagerVolumes) ListAssets(ctx context.Context, res db.Resource) ([]string, error) {
+	vols, err := m.listVolumes(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, len(vols))
+	for idx, vol := range vols {
+		result[idx] = vol.ID
+	}
+	return result, nil
+}
+
+// DescribeAssets implements the core.AssetDescriber interface.
+func (m *assetManagerVolumes) DescribeAssets(ctx context.Context, res db.Resource) ([]core.AssetDescription, error) {
+	vols, err := m.listVolumes(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]core.AssetDescription, len(vols))
+	for idx, vol := range vols {
+		result[idx] = core.AssetDescription{UUID: vol.ID, Name: vol.Name, Metadata: vol.Metadata}
+	}
+	return result, nil
+}
+
+func (m *assetManagerVolumes) listVolumes(ctx context.Context, res db.Resource) ([]volumes.Volume, error) {
+	assetType, ok := parseVolumesAssetType(res.AssetType).Unpack()
+	if !ok {
+		return nil, fmt.Errorf("could not parse asset type %s", res.AssetType)
+	}
+
+	opts := volumes.ListOpts{AllTenants: true, TenantID: res.ScopeUUID}
+	pages, err := volumes.List(m.Cinder, opts).AllPages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("while listing volumes for project %s in Cinder: %w", res.ScopeUUID, err)
+	}
+	allVolumes, err := volumes.ExtractVolumes(pages)
+	if err != nil {
+		return nil, fmt.Errorf("while listing volumes for project %s in Cinder: %w", res.ScopeUUID, err)
+	}
+
+	var result []volumes.Volume
+	for _, vol := range allVolumes {
+		// only attached volumes have a filesystem that reports usage
+		if assetType.matches(vol) && len(vol.Attachments) > 0 {
+			result = append(result, vol)
+		}
+	}
+	return result, nil
+}
+
+// SetAssetSize implements the core.AssetManager interface.
+func (m *assetManagerVolumes) SetAssetSize(ctx context.Context, res db.Resource, assetUUID string, oldSize, newSize uint64) (castellum.OperationOutcome, error) {
+	// defense in depth: GetAssetStatus() reports StrictMinimumSize = Size, so we should never be asked to shrink
+	if newSize <= oldSize {
+		return castellum.OperationOutcomeErrored, fmt.Errorf("cannot resize volume %s from %d GiB to %d GiB: volumes cannot be shrunk", assetUUID, oldSize, newSize)
+	}
+	if newSize > math.MaxInt { // we need to convert `newSize` to int to satisfy the Gophercloud API
+		return castellum.OperationOutcomeErrored, fmt.Errorf("newSize out of bounds: %d", newSize)
+	}
+
+	err := volumes.ExtendSize(ctx, m.Cinder, assetUUID, volumes.ExtendSizeOpts{NewSize: int(newSize)}).ExtractErr()
+	if err != nil {
+		// If the resize fails because of missing quota, it's the user's fault, not ours.
+		if gophercloud.ResponseCodeIs(err, http.StatusRequestEntityTooLarge) {
+			return castellum.OperationOutcomeFailed, err
+		}
+		return castellum.OperationOutcomeErrored, err
+	}
+	return castellum.OperationOutcomeSucceeded, nil
+}
+
+// GetAssetStatus implements the core.AssetManager interface.
+func (m *assetManagerVolumes) GetAssetStatus(ctx context.Context, res db.Resource, assetUUID string, previousStatus Option[core.AssetStatus]) (core.AssetStatus, error) {
+	vol, err := volumes.Get(ctx, m.Cinder, assetUUID).Extract()
+	if err != nil {
+		if gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+			return core.AssetStatus{}, core.AssetNotFoundError{InnerError: fmt.Errorf("volume not found in Cinder: %w", err)}
+		}
+		return core.AssetStatus{}, fmt.Errorf("cannot get status of volume %s from Cinder API: %w", assetUUID, err)
+	}
+	if len(vol.Attachments) == 0 {
+		// defense in depth: this volume should already have been ignored during ListAssets
+		return core.AssetStatus{}, core.AssetNotFoundError{InnerError: errors.New("ignoring because volume is not attached")}
+	}
+	if vol.Size < 0 {
+		return core.AssetStatus{}, fmt.Errorf("invalid size reported by Cinder for volume %s: %d GiB", assetUUID, vol.Size)
+	}
+
+	// query Prometheus metrics for usage
+	metrics, err := m.VolumeMetrics.Get(ctx, assetUUID)
+	if err != nil {
+		return core.AssetStatus{}, err
+	}
+	if metrics.UsedGiB == nil {
+		return core.AssetStatus{}, fmt.Errorf("no filesystem metrics for volume %q", assetUUID)
+	}
+
+	size := uint64(vol.Size)
+	return core.AssetStatus{
+		Size:              size,
+		StrictMinimumSize: Some(size), // volumes cannot be shrunk
+		Usage:             castellum.UsageValues{castellum.SingularUsageMetric: *metrics.UsedGiB},
+		BackendKey:        backendKeyFromHost(vol.Host),
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// type declarations and configuration for promquery.BulkQueryCache
+
+// NOTE: The `volume_id` label is not part of the standard node-exporter metrics. It needs to be added by relabeling.
+const cinderVolumeUsedBytesQuery = `max by (volume_id) (node_filesystem_size_bytes{volume_id!=""} - node_filesystem_avail_bytes{volume_id!=""})`
+
+type cinderVolumeMetrics struct {
+	UsedGiB *float64
+}
+
+var cinderVolumeQueries = []promquery.BulkQuery[string, cinderVolumeMetrics]{
+	{
+		Query:       cinderVolumeUsedBytesQuery,
+		Description: "Cinder volume filesystem used bytes",
+		Keyer: func(sample *model.Sample) string {
+			return string(sample.Metric["volume_id"])
+		},
+		Filler: func(entry *cinderVolumeMetrics, sample *model.Sample) {
+			entry.UsedGiB = new(asGigabytes(sample.Value))
+		},
+	},
+}
@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/errext"
+	"github.com/sapcc/go-bits/must"
+	"github.com/sapcc/go-bits/promquery"
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+type stubVolume struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Metadata    map[string]string `json:"metadata"`
+	Size        int               `json:"size"`
+	VolumeType  string            `json:"volume_type"`
+	Attachments []any             `json:"attachments"`
+	Host        string            `json:"os-vol-host-attr:host,omitempty"`
+}
+
+// stubCinder is a very small subset of the Cinder API.
+type stubCinder struct {
+	Volumes     map[string]*stubVolume
+	ExtendCalls []string
+}
+
+func (s *stubCinder) Handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /types", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"volume_types": []map[string]any{
+			{"id": "b8d8f4ff-7a4c-4c41-8b4e-4c7b6d0a2e6c", "name": "ssd"},
+			{"id": "0c5b1a2f-5d32-4e8e-9ad0-46f5c2d7c1b2", "name": "hdd"},
+		}})
+	})
+	mux.HandleFunc("GET /volumes/detail", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("all_tenants"), "true")
+		assert.Equal(t, r.URL.Query().Get("project_id"), "project1")
+		var vols []*stubVolume
+		for _, id := range []string{"vol1", "vol2", "vol3", "vol4"} {
+			if vol, ok := s.Volumes[id]; ok {
+				vols = append(vols, vol)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"volumes": vols})
+	})
+	mux.HandleFunc("GET /volumes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		vol, ok := s.Volumes[r.PathValue("id")]
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"volume": vol})
+	})
+	mux.HandleFunc("POST /volumes/{id}/action", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("OpenStack-API-Version"), "volume 3.42")
+		body := string(must.ReturnT(io.ReadAll(r.Body))(t))
+		s.ExtendCalls = append(s.ExtendCalls, r.PathValue("id")+" "+body)
+		if r.PathValue("id") == "vol2" {
+			http.Error(w, "VolumeSizeExceedsAvailableQuota: Requested volume or snapshot exceeds allowed gigabytes quota.", http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	must.Succeed(json.NewEncoder(w).Encode(data))
+}
+
+// stubPrometheus answers every query with the same result vector.
+func stubPrometheus(t *testing.T, usedBytesByVolumeID map[string]float64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/api/v1/query")
+		must.SucceedT(t, r.ParseForm())
+		assert.Equal(t, r.Form.Get("query"), cinderVolumeUsedBytesQuery)
+
+		var result []map[string]any
+		for volumeID, usedBytes := range usedBytesByVolumeID {
+			result = append(result, map[string]any{
+				"metric": map[string]string{"volume_id": volumeID},
+				"value":  []any{time.Now().Unix(), strconv.FormatFloat(usedBytes, 'f', -1, 64)},
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"status": "success",
+			"data":   map[string]any{"resultType": "vector", "result": result},
+		})
+	})
+}
+
+func setupVolumesTest(t *testing.T) (*assetManagerVolumes, *stubCinder) {
+	cinder := &stubCinder{Volumes: map[string]*stubVolume{
+		"vol1": {ID: "vol1", Name: "data", Metadata: map[string]string{"backup": "true"}, Size: 20, VolumeType: "ssd", Attachments: []any{map[string]string{"server_id": "server1"}}, Host: "cinder-volume@netapp-fil01#aggr1"},
+		"vol2": {ID: "vol2", Name: "logs", Size: 50, VolumeType: "hdd", Attachments: []any{map[string]string{"server_id": "server1"}}},
+		"vol3": {ID: "vol3", Size: 10, VolumeType: "ssd", Attachments: []any{}},
+		"vol4": {ID: "vol4", Size: 30, VolumeType: "ssd", Attachments: []any{map[string]string{"server_id": "server2"}}},
+	}}
+	cinderServer := httptest.NewServer(cinder.Handler(t))
+	t.Cleanup(cinderServer.Close)
+	promServer := httptest.NewServer(stubPrometheus(t, map[string]float64{
+		"vol1": 15 << 30,
+		"vol2": 10 << 30,
+		// no metrics for vol4
+	}))
+	t.Cleanup(promServer.Close)
+
+	promClient := must.ReturnT(promquery.Config{ServerURL: promServer.URL}.Connect())(t)
+	m := &assetManagerVolumes{
+		Cinder: &gophercloud.ServiceClient{
+			ProviderClient: &gophercloud.ProviderClient{},
+			Endpoint:       cinderServer.URL + "/",
+			Type:           "block-storage",
+			Microversion:   "3.42",
+		},
+		VolumeMetrics: promquery.NewBulkQueryCache(cinderVolumeQueries, 30*time.Second, promClient),
+	}
+	return m, cinder
+}
+
+func TestVolumesAssetTypes(t *testing.T) {
+	m, _ := setupVolumesTest(t)
+	ctx := t.Context()
+
+	assert.Equal(t, m.InfoForAssetType("volumes").IsSome(), true)
+	assert.Equal(t, m.InfoForAssetType("volumes-type:ssd").IsSome(), true)
+	assert.Equal(t, m.InfoForAssetType("volumes-type:").IsSome(), false)
+	assert.Equal(t, m.InfoForAssetType("nfs-shares").IsSome(), false)
+
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "volumes", "project1", "", nil), nil)
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "volumes", "project1", `{"foo":"bar"}`, nil), core.ErrNoConfigurationAllowed)
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "volumes-type:ssd", "project1", "", nil), nil)
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "volumes-type:nvme", "project1", "", nil), `no such volume type: "nvme"`)
+
+	// "volumes" and "volumes-type:..." cannot be mixed in the same project, but several "volumes-type:..." can
+	existing := map[db.AssetType]struct{}{"volumes-type:hdd": {}}
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "volumes-type:ssd", "project1", "", existing), nil)
+	assert.ErrEqual(t, m.CheckResourceAllowed(ctx, "volumes", "project1", "", existing),
+		`cannot create a "volumes" resource because of possible contradiction with existing "volumes-type:hdd" resource`)
+}
+
+func TestVolumesListAssets(t *testing.T) {
+	m, _ := setupVolumesTest(t)
+	ctx := t.Context()
+
+	// unattached volumes (vol3) are ignored
+	result, err := m.ListAssets(ctx, db.Resource{ScopeUUID: "project1", AssetType: "volumes"})
+	must.SucceedT(t, err)
+	assert.Equal(t, result, []string{"vol1", "vol2", "vol4"})
+
+	result, err = m.ListAssets(ctx, db.Resource{ScopeUUID: "project1", AssetType: "volumes-type:ssd"})
+	must.SucceedT(t, err)
+	assert.Equal(t, result, []string{"vol1", "vol4"})
+}
+
+func TestVolumesDescribeAssets(t *testing.T) {
+	m, _ := setupVolumesTest(t)
+	ctx := t.Context()
+
+	result, err := m.DescribeAssets(ctx, db.Resource{ScopeUUID: "project1", AssetType: "volumes-type:ssd"})
+	must.SucceedT(t, err)
+	assert.Equal(t, len(result), 2)
+	assert.Equal(t, result[0].UUID, "vol1")
+	assert.Equal(t, result[0].Name, "data")
+	assert.Equal(t, result[0].Metadata["backup"], "true")
+	assert.Equal(t, result[1].UUID, "vol4")
+	assert.Equal(t, result[1].Name, "")
+	assert.Equal(t, len(result[1].Metadata), 0)
+}
+
+func TestVolumesGetAssetStatus(t *testing.T) {
+	m, cinder := setupVolumesTest(t)
+	ctx := t.Context()
+	res := db.Resource{ScopeUUID: "project1", AssetType: "volumes"}
+
+	status, err := m.GetAssetStatus(ctx, res, "vol1", None[core.AssetStatus]())
+	must.SucceedT(t, err)
+	assert.Equal(t, status, core.AssetStatus{
+		Size:              20,
+		Usage:             castellum.UsageValues{castellum.SingularUsageMetric: 15},
+		StrictMinimumSize: Some[uint64](20),
+		BackendKey:        "cinder-volume@netapp-fil01", // (without pool name)
+	})
+
+	// size is always taken from Cinder
+	cinder.Volumes["vol1"].Size = 25
+	status, err = m.GetAssetStatus(ctx, res, "vol1", Some(status))
+	must.SucceedT(t, err)
+	assert.Equal(t, status.Size, 25)
+	assert.Equal(t, status.StrictMinimumSize, Some[uint64](25))
+
+	// volumes without metrics report an error
+	_, err = m.GetAssetStatus(ctx, res, "vol4", None[core.AssetStatus]())
+	assert.ErrEqual(t, err, `no filesystem metrics for volume "vol4"`)
+
+	// deleted or detached volumes are reported as such
+	_, err = m.GetAssetStatus(ctx, res, "vol5", None[core.AssetStatus]())
+	assert.Equal(t, errext.IsOfType[core.AssetNotFoundError](err), true)
+	_, err = m.GetAssetStatus(ctx, res, "vol3", None[core.AssetStatus]())
+	assert.Equal(t, errext.IsOfType[core.AssetNotFoundError](err), true)
+}
+
+func TestVolumesSetAssetSize(t *testing.T) {
+	m, cinder := setupVolumesTest(t)
+	ctx := t.Context()
+	res := db.Resource{ScopeUUID: "project1", AssetType: "volumes"}
+
+	outcome, err := m.SetAssetSize(ctx, res, "vol1", 20, 24)
+	must.SucceedT(t, err)
+	assert.Equal(t, outcome, castellum.OperationOutcomeSucceeded)
+
+	// quota errors are the user's fault
+	outcome, err = m.SetAssetSize(ctx, res, "vol2", 50, 60)
+	assert.Equal(t, err != nil, true)
+	assert.Equal(t, outcome, castellum.OperationOutcomeFailed)
+
+	// shrinking is not possible (and not even attempted)
+	outcome, err = m.SetAssetSize(ctx, res, "vol1", 24, 20)
+	assert.ErrEqual(t, err, "cannot resize volume vol1 from 24 GiB to 20 GiB: volumes cannot be shrunk")
+	assert.Equal(t, outcome, castellum.OperationOutcomeErrored)
+
+	assert.Equal(t, cinder.ExtendCalls, []string{
+		`vol1 {"os-extend":{"new_size":24}}`,
+		`vol2 {"os-extend":{"new_size":60}}`,
+	})
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/osext"
+
+	"github.com/sapcc/castellum/internal/core"
+)
+
+type notifierWebhook struct {
+	URL    string
+	States map[castellum.OperationState]bool
+	Client *http.Client
+}
+
+func init() {
+	core.NotifierRegistry.Add(func() core.Notifier { return &notifierWebhook{} })
+}
+
+// PluginTypeID implements the core.Notifier interface.
+func (n *notifierWebhook) PluginTypeID() string { return "webhook" }
+
+// Init implements the core.Notifier interface.
+func (n *notifierWebhook) Init(ctx context.Context, provider core.ProviderClient) (err error) {
+	n.URL, err = osext.NeedGetenv("CASTELLUM_WEBHOOK_URL")
+	if err != nil {
+		return err
+	}
+	n.States, err = parseOperationStates(osext.GetenvOrDefault("CASTELLUM_WEBHOOK_STATES",
+		"created,confirmed,greenlit,cancelled,succeeded,failed,errored"))
+	if err != nil {
+		return fmt.Errorf("cannot parse CASTELLUM_WEBHOOK_STATES: %w", err)
+	}
+	n.Client = &http.Client{Timeout: 30 * time.Second}
+	return nil
+}
+
+// IsInterestedIn implements the core.Notifier interface.
+func (n *notifierWebhook) IsInterestedIn(state castellum.OperationState) bool {
+	return n.States[state]
+}
+
+// SendNotification implements the core.Notifier interface.
+func (n *notifierWebhook) SendNotification(ctx context.Context, payload core.NotificationPayload) error {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// include the start of the response body to aid in debugging
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
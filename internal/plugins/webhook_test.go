@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package plugins
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/must"
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/castellum/internal/core"
+)
+
+var testNotificationPayload = core.NotificationPayload{
+	ProjectUUID:  "project1",
+	DomainUUID:   "domain1",
+	AssetType:    "nfs-shares",
+	AssetUUID:    "share1",
+	Reason:       castellum.OperationReasonHigh,
+	OldSize:      1000,
+	NewSize:      1200,
+	FromState:    castellum.OperationStateGreenlit,
+	ToState:      castellum.OperationStateFailed,
+	ErrorMessage: "quota exceeded",
+	OccurredAt:   1557122400,
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Setenv("CASTELLUM_WEBHOOK_URL", "http://example.com/")
+	t.Setenv("CASTELLUM_WEBHOOK_STATES", "succeeded,failed")
+	n := &notifierWebhook{}
+	must.SucceedT(t, n.Init(t.Context(), nil))
+	assert.Equal(t, n.IsInterestedIn(castellum.OperationStateFailed), true)
+	assert.Equal(t, n.IsInterestedIn(castellum.OperationStateCreated), false)
+
+	t.Setenv("CASTELLUM_WEBHOOK_STATES", "succeeded,exploded")
+	assert.ErrEqual(t, (&notifierWebhook{}).Init(t.Context(), nil), `cannot parse CASTELLUM_WEBHOOK_STATES: invalid operation state: "exploded"`)
+
+	var (
+		requestBodies []string
+		failRequests  bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Method, http.MethodPost)
+		assert.Equal(t, r.Header.Get("Content-Type"), "application/json")
+		requestBodies = append(requestBodies, string(must.ReturnT(io.ReadAll(r.Body))(t)))
+		if failRequests {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	n.URL = srv.URL
+
+	must.SucceedT(t, n.SendNotification(t.Context(), testNotificationPayload))
+	failRequests = true
+	assert.ErrEqual(t, n.SendNotification(t.Context(), testNotificationPayload), "webhook responded with 503 Service Unavailable: service unavailable")
+
+	expectedBody := `{"project_id":"project1","domain_id":"domain1","asset_type":"nfs-shares","asset_id":"share1","reason":"high","old_size":1000,"new_size":1200,"from_state":"greenlit","to_state":"failed","error_message":"quota exceeded","occurred_at":1557122400}`
+	assert.Equal(t, requestBodies, []string{expectedBody, expectedBody})
+}
@@ -0,0 +1,299 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/errext"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// Input is the format of the usage time series file for `castellum replay`.
+type Input struct {
+	// These are only used to match the max_asset_sizes from the config, and can
+	// be left empty if the config does not have any.
+	AssetType db.AssetType `json:"asset_type"`
+	ProjectID string       `json:"project_id"`
+
+	Samples []Sample `json:"samples"`
+}
+
+// Sample appears in type Input.
+type Sample struct {
+	AtUnix int64                 `json:"at"`
+	Size   uint64                `json:"size"`
+	Usage  castellum.UsageValues `json:"usage"`
+}
+
+// LoadInput reads an Input from the given file and validates it.
+func LoadInput(path string) (Input, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return Input{}, err
+	}
+	var in Input
+	err = json.Unmarshal(buf, &in)
+	if err != nil {
+		return Input{}, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	err = in.validate()
+	if err != nil {
+		return Input{}, fmt.Errorf("invalid time series in %s: %w", path, err)
+	}
+	if in.AssetType == "" {
+		in.AssetType = "replay"
+	}
+	return in, nil
+}
+
+func (in Input) validate() error {
+	if len(in.Samples) == 0 {
+		return errors.New("no samples given")
+	}
+	metrics := in.usageMetrics()
+	for idx, sample := range in.Samples {
+		if idx > 0 && sample.AtUnix <= in.Samples[idx-1].AtUnix {
+			return fmt.Errorf("samples[%d] is not chronologically after samples[%d]", idx, idx-1)
+		}
+		if !slices.Equal(slices.Sorted(maps.Keys(sample.Usage)), metrics) {
+			return fmt.Errorf("samples[%d] has different usage metrics than samples[0]", idx)
+		}
+	}
+	return nil
+}
+
+// The usage metrics of the replayed asset are taken from the samples.
+func (in Input) usageMetrics() []castellum.UsageMetric {
+	return slices.Sorted(maps.Keys(in.Samples[0].Usage))
+}
+
+// PrepareResource builds the resource for a replay from the resource
+// configuration given in the same format as for the
+// `PUT /v1/projects/:id/resources/:type` API, and validates it in the same way.
+func PrepareResource(ctx context.Context, in Input, cfg core.Config, resourceJSON string) (db.Resource, errext.ErrorSet) {
+	var spec core.ResourceSpec
+	err := json.Unmarshal([]byte(resourceJSON), &spec)
+	if err != nil {
+		var errs errext.ErrorSet
+		errs.Addf("resource configuration is not valid JSON: %s", err.Error())
+		return db.Resource{}, errs
+	}
+
+	res := db.Resource{
+		ScopeUUID: in.ProjectID,
+		AssetType: in.AssetType,
+	}
+	team := core.AssetManagerTeam{assetManager{
+		Info: core.AssetTypeInfo{
+			AssetType:    in.AssetType,
+			UsageMetrics: in.usageMetrics(),
+		},
+	}}
+	errs := core.ApplyResourceSpecInto(ctx, &res, spec, map[db.AssetType]struct{}{res.AssetType: {}}, cfg, team)
+	return res, errs
+}
+
+// Result is the result of Run().
+type Result struct {
+	// Operations that finished during the replay, in chronological order.
+	FinishedOperations []db.FinishedOperation
+	// The operation that was still pending at the end of the replay, if any.
+	PendingOperation Option[db.PendingOperation]
+	// The time of the last sample.
+	FinishedAt time.Time
+}
+
+// Run feeds the samples from the given input through the resize logic for the
+// given resource, and reports all operations that would have been performed.
+//
+// Each sample is treated like a scrape of the asset by the observer, so the
+// decisions taken on each sample mirror those taken in
+// tasks.Context.processAssetScrape(). Resize operations are assumed to succeed
+// and take effect immediately once they are greenlit. Because of this, only the
+// size of the first sample is used. The sizes in all later samples are
+// ignored, since those include the effects of the resizes that actually
+// happened. Operations that need to be greenlit by a user are assumed to never
+// be greenlit.
+func Run(in Input, res db.Resource, cfg core.Config) Result {
+	r := replayer{
+		Resource: res,
+		Logic: core.LogicOfResource(res, core.AssetTypeInfo{
+			AssetType:    res.AssetType,
+			UsageMetrics: in.usageMetrics(),
+		}, None[db.AssetOverride]()),
+		Config: cfg,
+		Asset: db.Asset{
+			UUID: "replay",
+			Size: in.Samples[0].Size,
+		},
+	}
+	for _, sample := range in.Samples {
+		now := time.Unix(sample.AtUnix, 0).UTC()
+		r.Asset.Usage = sample.Usage
+
+		// the worker would have executed operations that got greenlit since the
+		// previous sample before this sample was taken
+		r.maybeExecuteOperation(now)
+		r.processSample(now)
+		// if an operation gets greenlit right now, the worker picks it up right away
+		r.maybeExecuteOperation(now)
+	}
+
+	return Result{
+		FinishedOperations: r.FinishedOperations,
+		PendingOperation:   r.PendingOperation,
+		FinishedAt:         time.Unix(in.Samples[len(in.Samples)-1].AtUnix, 0).UTC(),
+	}
+}
+
+type replayer struct {
+	Resource db.Resource
+	Logic    core.ResourceLogic
+	Config   core.Config
+	Asset    db.Asset
+
+	FinishedOperations []db.FinishedOperation
+	PendingOperation   Option[db.PendingOperation]
+}
+
+func (r *replayer) eligibleOperations() map[castellum.OperationReason]uint64 {
+	return core.GetEligibleOperations(r.Logic, core.StatusOfAsset(r.Asset, r.Config, r.Resource))
+}
+
+func (r *replayer) maybeExecuteOperation(now time.Time) {
+	op, ok := r.PendingOperation.Unpack()
+	if !ok {
+		return
+	}
+	greenlitAt, ok := op.GreenlitAt.Unpack()
+	if !ok || greenlitAt.After(now) {
+		return
+	}
+	r.Asset.Size = op.NewSize
+	r.finishOperation(op, castellum.OperationOutcomeSucceeded, greenlitAt)
+}
+
+func (r *replayer) finishOperation(op db.PendingOperation, outcome castellum.OperationOutcome, finishedAt time.Time) {
+	r.FinishedOperations = append(r.FinishedOperations, op.IntoFinishedOperation(outcome, finishedAt))
+	r.PendingOperation = None[db.PendingOperation]()
+}
+
+func (r *replayer) lastResize() Option[db.FinishedOperation] {
+	for _, op := range slices.Backward(r.FinishedOperations) {
+		if op.Outcome == castellum.OperationOutcomeSucceeded {
+			return Some(op)
+		}
+	}
+	return None[db.FinishedOperation]()
+}
+
+func (r *replayer) processSample(now time.Time) {
+	eligibleFor := r.eligibleOperations()
+
+	if op, ok := r.PendingOperation.Unpack(); ok {
+		// like maybeCancelOperation()
+		_, isEligible := eligibleFor[op.Reason]
+		if op.Reason == castellum.OperationReasonHigh {
+			if _, canBeUpgraded := eligibleFor[castellum.OperationReasonCritical]; canBeUpgraded {
+				isEligible = false
+			}
+		}
+		if !isEligible {
+			r.finishOperation(op, castellum.OperationOutcomeCancelled, now)
+		}
+	}
+
+	if op, ok := r.PendingOperation.Unpack(); ok {
+		// like maybeUpdateOperation()
+		op.NewSize = eligibleFor[op.Reason]
+
+		// like maybeConfirmOperation()
+		if op.ConfirmedAt.IsNone() && !now.Before(core.EarliestConfirmationOf(r.Resource, op.Reason, op.CreatedAt)) {
+			op.ConfirmedAt = Some(now)
+			op.GreenlitAt = core.GreenlightTimeOf(r.Resource, op.Reason, now)
+		}
+		r.PendingOperation = Some(op)
+		return
+	}
+
+	// like maybeCreateOperation()
+	reason, newSize, ok := core.SelectOperation(eligibleFor)
+	if !ok || newSize == r.Asset.Size {
+		return
+	}
+	if core.IsInCooldownFor(r.Resource, reason, r.lastResize(), now) {
+		return
+	}
+	op := db.PendingOperation{
+		Reason:    reason,
+		OldSize:   r.Asset.Size,
+		NewSize:   newSize,
+		Usage:     r.Asset.Usage,
+		CreatedAt: now,
+	}
+	if op.Reason == castellum.OperationReasonCritical {
+		op.ConfirmedAt = Some(op.CreatedAt)
+		op.GreenlitAt = core.GreenlightTimeOf(r.Resource, op.Reason, op.CreatedAt)
+	}
+	r.PendingOperation = Some(op)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// asset manager for validating the resource configuration
+
+// assetManager is a core.AssetManager that only knows about the asset type of
+// the replayed asset. It is only used for ApplyResourceSpecInto().
+type assetManager struct {
+	Info core.AssetTypeInfo
+}
+
+// PluginTypeID implements the core.AssetManager interface.
+func (m assetManager) PluginTypeID() string { return "replay" }
+
+// Init implements the core.AssetManager interface.
+func (m assetManager) Init(ctx context.Context, provider core.ProviderClient) error {
+	return nil // unused
+}
+
+// InfoForAssetType implements the core.AssetManager interface.
+func (m assetManager) InfoForAssetType(assetType db.AssetType) Option[core.AssetTypeInfo] {
+	if assetType == m.Info.AssetType {
+		return Some(m.Info)
+	}
+	return None[core.AssetTypeInfo]()
+}
+
+// CheckResourceAllowed implements the core.AssetManager interface.
+func (m assetManager) CheckResourceAllowed(_ context.Context, _ db.AssetType, _, _ string, _ map[db.AssetType]struct{}) error {
+	// the type-specific configuration does not influence the resize logic, so
+	// we accept whatever is given
+	return nil
+}
+
+// ListAssets implements the core.AssetManager interface.
+func (m assetManager) ListAssets(_ context.Context, _ db.Resource) ([]string, error) {
+	return nil, errors.New("not supported during replay")
+}
+
+// SetAssetSize implements the core.AssetManager interface.
+func (m assetManager) SetAssetSize(_ context.Context, _ db.Resource, _ string, _, _ uint64) (castellum.OperationOutcome, error) {
+	return castellum.OperationOutcomeErrored, errors.New("not supported during replay")
+}
+
+// GetAssetStatus implements the core.AssetManager interface.
+func (m assetManager) GetAssetStatus(_ context.Context, _ db.Resource, _ string, _ Option[core.AssetStatus]) (core.AssetStatus, error) {
+	return core.AssetStatus{}, errors.New("not supported during replay")
+}
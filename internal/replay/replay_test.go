@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+const testResourceJSON = `{
+	"low_threshold": {"usage_percent": 20, "delay_seconds": 3600},
+	"high_threshold": {"usage_percent": 80, "delay_seconds": 1200},
+	"critical_threshold": {"usage_percent": 95},
+	"size_steps": {"percent": 20}
+}`
+
+func unix(timestamp int64) time.Time {
+	return time.Unix(timestamp, 0).UTC()
+}
+
+func singular(x float64) castellum.UsageValues {
+	return castellum.UsageValues{castellum.SingularUsageMetric: x}
+}
+
+func makeInput(usages ...float64) Input {
+	in := Input{AssetType: "replay"}
+	for idx, usage := range usages {
+		in.Samples = append(in.Samples, Sample{
+			AtUnix: int64(600 * idx),
+			Size:   1000, // only the first size is used, so we don't need to bother with the others
+			Usage:  singular(usage),
+		})
+	}
+	return in
+}
+
+func TestReplay(t *testing.T) {
+	var cfg core.Config
+	in := makeInput(500, 850, 860, 870, 880, 990, 1150, 1150, 300)
+	assert.Equal(t, in.validate(), nil)
+
+	res, errs := PrepareResource(t.Context(), in, cfg, testResourceJSON)
+	assert.Equal(t, errs.Join(", "), "")
+
+	result := Run(in, res, cfg)
+	assert.Equal(t, result.FinishedOperations, []db.FinishedOperation{
+		// the high threshold is crossed at t=600, but the operation can only be
+		// confirmed at t=1800 because of the delay
+		{
+			Reason:      castellum.OperationReasonHigh,
+			Outcome:     castellum.OperationOutcomeSucceeded,
+			OldSize:     1000,
+			NewSize:     1200,
+			CreatedAt:   unix(600),
+			ConfirmedAt: Some(unix(1800)),
+			GreenlitAt:  Some(unix(1800)),
+			FinishedAt:  unix(1800),
+			Usage:       singular(850),
+		},
+		// after the resize, the high threshold is crossed again at t=3000, but
+		// this operation is replaced by a critical one at t=3600
+		{
+			Reason:     castellum.OperationReasonHigh,
+			Outcome:    castellum.OperationOutcomeCancelled,
+			OldSize:    1200,
+			NewSize:    1440,
+			CreatedAt:  unix(3000),
+			FinishedAt: unix(3600),
+			Usage:      singular(990),
+		},
+		{
+			Reason:      castellum.OperationReasonCritical,
+			Outcome:     castellum.OperationOutcomeSucceeded,
+			OldSize:     1200,
+			NewSize:     1440,
+			CreatedAt:   unix(3600),
+			ConfirmedAt: Some(unix(3600)),
+			GreenlitAt:  Some(unix(3600)),
+			FinishedAt:  unix(3600),
+			Usage:       singular(1150),
+		},
+	})
+	// at the end, the usage (300 of 1440) is barely above the low threshold
+	assert.Equal(t, result.PendingOperation, None[db.PendingOperation]())
+	assert.Equal(t, result.FinishedAt, unix(4800))
+}
+
+func TestReplayWithApprovalRequired(t *testing.T) {
+	var cfg core.Config
+	in := makeInput(500, 850, 860, 870, 880)
+
+	resourceJSON := testResourceJSON[:len(testResourceJSON)-1] + `, "approval_required": {"high": true}}`
+	res, errs := PrepareResource(t.Context(), in, cfg, resourceJSON)
+	assert.Equal(t, errs.Join(", "), "")
+
+	// operations that require approval are never executed during the replay
+	result := Run(in, res, cfg)
+	assert.Equal(t, len(result.FinishedOperations), 0)
+	assert.Equal(t, result.PendingOperation, Some(db.PendingOperation{
+		Reason:      castellum.OperationReasonHigh,
+		OldSize:     1000,
+		NewSize:     1200,
+		CreatedAt:   unix(600),
+		ConfirmedAt: Some(unix(1800)),
+		Usage:       singular(850),
+	}))
+}
+
+func TestReplayWithMaintenanceWindow(t *testing.T) {
+	var cfg core.Config
+	in := makeInput(500, 850, 860, 870, 880, 890, 900, 910)
+
+	resourceJSON := testResourceJSON[:len(testResourceJSON)-1] + `, "maintenance_window": {"start": "01:05", "end": "02:00"}}`
+	res, errs := PrepareResource(t.Context(), in, cfg, resourceJSON)
+	assert.Equal(t, errs.Join(", "), "")
+
+	// the operation is executed at the start of the maintenance window, even
+	// though that falls between two samples
+	result := Run(in, res, cfg)
+	assert.Equal(t, result.FinishedOperations, []db.FinishedOperation{{
+		Reason:      castellum.OperationReasonHigh,
+		Outcome:     castellum.OperationOutcomeSucceeded,
+		OldSize:     1000,
+		NewSize:     1200,
+		CreatedAt:   unix(600),
+		ConfirmedAt: Some(unix(1800)),
+		GreenlitAt:  Some(unix(3900)),
+		FinishedAt:  unix(3900),
+		Usage:       singular(850),
+	}})
+	assert.Equal(t, result.PendingOperation, None[db.PendingOperation]())
+}
+
+func TestReplayInputValidation(t *testing.T) {
+	var cfg core.Config
+
+	assert.ErrEqual(t, Input{}.validate(), "no samples given")
+
+	in := makeInput(500, 600)
+	in.Samples[1].AtUnix = 0
+	assert.ErrEqual(t, in.validate(), "samples[1] is not chronologically after samples[0]")
+
+	in = makeInput(500, 600)
+	in.Samples[1].Usage = castellum.UsageValues{"first": 10, "second": 20}
+	assert.ErrEqual(t, in.validate(), "samples[1] has different usage metrics than samples[0]")
+
+	// the resource configuration is validated like in the API
+	in = makeInput(500, 600)
+	_, errs := PrepareResource(t.Context(), in, cfg, `{}`)
+	assert.Equal(t, errs.Join(", "), "at least one threshold must be configured, size step must be greater than 0%")
+	_, errs = PrepareResource(t.Context(), in, cfg, `{"low_threshold":{"usage_percent":20,"delay_seconds":60},"size_steps":{"percent":20},"size_constraints":{"maximum":2000}}`)
+	assert.Equal(t, errs.Join(", "), "")
+
+	// thresholds must refer to the usage metrics that occur in the time series
+	_, errs = PrepareResource(t.Context(), in, cfg, `{"low_threshold":{"usage_percent":{"first":20},"delay_seconds":60},"size_steps":{"percent":20}}`)
+	assert.Equal(t, errs.Join(", "), `missing low threshold, low threshold specified for metric "first" which is not valid for this asset type`)
+}
+
+func TestReplayWithCooldown(t *testing.T) {
+	var cfg core.Config
+	in := makeInput(500, 850, 860, 870, 100, 100, 100, 100, 100, 100, 100)
+
+	resourceJSON := testResourceJSON[:len(testResourceJSON)-1] + `, "cooldown": {"low_seconds": 3600}}`
+	res, errs := PrepareResource(t.Context(), in, cfg, resourceJSON)
+	assert.Equal(t, errs.Join(", "), "")
+
+	// after the upsize at t=1800, usage drops below the low threshold
+	// immediately, but the downsize is only created when the cooldown ends
+	result := Run(in, res, cfg)
+	assert.Equal(t, len(result.FinishedOperations), 1)
+	assert.Equal(t, result.PendingOperation, Some(db.PendingOperation{
+		Reason:    castellum.OperationReasonLow,
+		OldSize:   1200,
+		NewSize:   960,
+		CreatedAt: unix(5400),
+		Usage:     singular(100),
+	}))
+}
@@ -5,9 +5,11 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sapcc/go-api-declarations/castellum"
 	"github.com/sapcc/go-bits/jobloop"
@@ -41,6 +43,10 @@ const (
 // AssetResizingJob returns a job where each task is an asset that needs to be
 // resized.
 func (c *Context) AssetResizingJob(registerer prometheus.Registerer) jobloop.Job {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	registerer.MustRegister(throttledResizesCounter)
 	return (&jobloop.TxGuardedJob[*gsql.Tx, db.PendingOperation]{
 		Metadata: jobloop.JobMetadata{
 			ReadableName:    "asset resizing",
@@ -58,7 +64,39 @@ func (c *Context) AssetResizingJob(registerer prometheus.Registerer) jobloop.Job
 }
 
 func (c *Context) discoverAssetResize(ctx context.Context, tx *gsql.Tx, labels prometheus.Labels) (db.PendingOperation, error) {
-	return db.PendingOperationStore.SelectOne(ctx, tx, selectAndDeleteNextResizeQuery, c.TimeNow())
+	limits := c.Config.Current().ResizeRateLimits
+	if len(limits) == 0 {
+		return db.PendingOperationStore.SelectOne(ctx, tx, selectAndDeleteNextResizeQuery, c.TimeNow())
+	}
+
+	// when rate limits are configured, skip over operations that are throttled
+	// (they stay in the queue and will be picked up once the limits allow it)
+	throttle := newResizeThrottle(limits, c.TimeNow())
+	skippedIDs := []int64{}
+	for {
+		var candidate resizeCandidate
+		err := tx.QueryRow(selectNextResizeCandidateQuery, c.TimeNow(), pq.Array(skippedIDs)).
+			Scan(&candidate.OperationID, &candidate.ScopeUUID, &candidate.AssetType, &candidate.BackendKey)
+		if err != nil {
+			return db.PendingOperation{}, err
+		}
+
+		throttledBy, err := throttle.Check(tx, candidate)
+		if err != nil {
+			return db.PendingOperation{}, fmt.Errorf("while checking rate limits for pending operation %d: %w", candidate.OperationID, err)
+		}
+		if scope, ok := throttledBy.Unpack(); ok {
+			throttledResizesCounter.With(prometheus.Labels{
+				"asset_type": string(candidate.AssetType),
+				"limit":      string(scope),
+			}).Inc()
+			skippedIDs = append(skippedIDs, candidate.OperationID)
+			continue
+		}
+
+		return db.PendingOperationStore.SelectOne(ctx, tx,
+			`DELETE FROM pending_operations WHERE id = $1 RETURNING *`, candidate.OperationID)
+	}
 }
 
 func (c *Context) processAssetResize(ctx context.Context, tx *gsql.Tx, op db.PendingOperation, labels prometheus.Labels) error {
@@ -81,7 +119,32 @@ func (c *Context) processAssetResize(ctx context.Context, tx *gsql.Tx, op db.Pen
 
 	// perform the resize operation (we give asset.Size instead of op.OldSize
 	// since this is the most up-to-date asset size that we have)
-	outcome, err := manager.SetAssetSize(ctx, res, asset.UUID, asset.Size, op.NewSize)
+	var outcome castellum.OperationOutcome
+	if op.Reason == db.OperationReasonRepair {
+		if repairer, ok := manager.(core.AssetRepairer); ok {
+			outcome, err = repairer.RepairAsset(ctx, res, asset.UUID)
+		} else {
+			outcome, err = castellum.OperationOutcomeErrored, fmt.Errorf("asset manager for asset type %q cannot repair assets", res.AssetType)
+		}
+	} else {
+		outcome, err = manager.SetAssetSize(ctx, res, asset.UUID, asset.Size, op.NewSize)
+	}
+
+	// if the resize needs more time, put this operation back in the queue
+	// without touching the retry budget
+	var inProgress core.ResizeInProgressError
+	if errors.As(err, &inProgress) {
+		logg.Info("resize of %s %s to size %d is still in progress: %s", string(res.AssetType), asset.UUID, op.NewSize, inProgress.Message)
+		op.ID = 0
+		op.RetryAt = Some(c.TimeNow().Add(inProgress.RetryAfter))
+
+		err = db.PendingOperationStore.Insert(ctx, tx, &op)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
 	errorMessage := ""
 	if err != nil {
 		logg.Error("cannot resize %s %s to size %d: %s", string(res.AssetType), asset.UUID, op.NewSize, err.Error())
@@ -125,6 +188,10 @@ func (c *Context) processAssetResize(ctx context.Context, tx *gsql.Tx, op db.Pen
 		}
 	}
 
-	core.CountStateTransition(res, asset.UUID, castellum.OperationStateGreenlit, finishedOp.State())
+	transition := core.TransitionOfFinishedOperation(res, asset.UUID, finishedOp, castellum.OperationStateGreenlit)
+	err = c.Notifiers.RecordStateTransition(ctx, tx, transition, c.TimeNow())
+	if err != nil {
+		return err
+	}
 	return tx.Commit()
 }
@@ -214,3 +214,143 @@ func TestErroringResize(t *testing.T) {
 		"cannot set size smaller than current usage",
 	)
 }
+
+func TestResizeRateLimits(t *testing.T) {
+	ctx := t.Context()
+	s := test.NewSetup(t,
+		commonSetupOptionsForWorkerTest(),
+		test.WithConfig(`{
+			"resize_rate_limits": [
+				{ "asset_type": "foo", "per": "project", "max_per_hour": 1 },
+				{ "asset_type": ".*", "per": "backend", "max_concurrent": 1 }
+			]
+		}`),
+	)
+	resizeJob := setupAssetResizeTest(t, s, 2)
+
+	// add two greenlit PendingOperations in the same project
+	s.Clock.StepBy(10 * time.Minute)
+	for idx, reason := range []castellum.OperationReason{castellum.OperationReasonHigh, castellum.OperationReasonLow} {
+		newSize := uint64(1200)
+		if reason == castellum.OperationReasonLow {
+			newSize = 800
+		}
+		must.SucceedT(t, db.PendingOperationStore.Insert(ctx, s.DB, &db.PendingOperation{
+			AssetID:     int64(idx + 1),
+			Reason:      reason,
+			OldSize:     1000,
+			NewSize:     newSize,
+			Usage:       castellum.UsageValues{castellum.SingularUsageMetric: 500},
+			CreatedAt:   s.Clock.Now().Add(-10 * time.Minute),
+			ConfirmedAt: Some(s.Clock.Now().Add(-5 * time.Minute)),
+			GreenlitAt:  Some(s.Clock.Now().Add(-5 * time.Minute)),
+		}))
+	}
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// the first operation gets executed (the backend limit does not apply
+	// because these assets do not report a backend key)
+	must.SucceedT(t, resizeJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			UPDATE assets SET expected_size = 1200, resized_at = %[3]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, confirmed_at, greenlit_at, finished_at, usage) VALUES (1, 'high', 'succeeded', 1000, 1200, %[1]d, %[2]d, %[2]d, %[3]d, '{"singular":500}');
+			DELETE FROM pending_operations WHERE id = 1 AND asset_id = 1;
+		`,
+		s.Clock.Now().Add(-10*time.Minute).Unix(),
+		s.Clock.Now().Add(-5*time.Minute).Unix(),
+		s.Clock.Now().Unix(),
+	)
+
+	// the second operation is throttled by the per-project limit and stays queued
+	s.Clock.StepBy(30 * time.Minute)
+	err := resizeJob.ProcessOne(ctx)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %s instead", err.Error())
+	}
+	tr.DBChanges().AssertEmpty()
+
+	// once the first operation is more than an hour ago, the second operation can go ahead
+	s.Clock.StepBy(31 * time.Minute)
+	must.SucceedT(t, resizeJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			UPDATE assets SET expected_size = 800, resized_at = %[3]d WHERE id = 2 AND resource_id = 1 AND uuid = 'asset2';
+			INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, confirmed_at, greenlit_at, finished_at, usage) VALUES (2, 'low', 'succeeded', 1000, 800, %[1]d, %[2]d, %[2]d, %[3]d, '{"singular":500}');
+			DELETE FROM pending_operations WHERE id = 2 AND asset_id = 2;
+		`,
+		s.Clock.Now().Add(-71*time.Minute).Unix(),
+		s.Clock.Now().Add(-66*time.Minute).Unix(),
+		s.Clock.Now().Unix(),
+	)
+}
+
+func TestResizeInProgress(t *testing.T) {
+	ctx := t.Context()
+	s := test.NewSetup(t,
+		commonSetupOptionsForWorkerTest(),
+	)
+	resizeJob := setupAssetResizeTest(t, s, 1)
+
+	// add a greenlit PendingOperation
+	s.Clock.StepBy(10 * time.Minute)
+	var (
+		createdAt   = s.Clock.Now().Add(-10 * time.Minute)
+		confirmedAt = s.Clock.Now().Add(-5 * time.Minute)
+		greenlitAt  = s.Clock.Now().Add(-5 * time.Minute)
+	)
+	pendingOp := db.PendingOperation{
+		AssetID:     1,
+		Reason:      castellum.OperationReasonLow,
+		OldSize:     1000,
+		NewSize:     600,
+		Usage:       castellum.UsageValues{castellum.SingularUsageMetric: 500},
+		CreatedAt:   createdAt,
+		ConfirmedAt: Some(confirmedAt),
+		GreenlitAt:  Some(greenlitAt),
+	}
+	must.SucceedT(t, db.PendingOperationStore.Insert(ctx, s.DB, &pendingOp))
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// while the asset manager reports the resize as in progress, the operation
+	// is put back in the queue without counting against the retry budget
+	amStatic := s.ManagerForAssetType("foo")
+	amStatic.SetAssetSizeInProgress = true
+	for attempt := range tasks.MaxRetries + 1 {
+		must.SucceedT(t, resizeJob.ProcessOne(ctx))
+		tr.DBChanges().AssertEqualf(`
+				DELETE FROM pending_operations WHERE id = %[1]d AND asset_id = 1;
+				INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, confirmed_at, greenlit_at, retry_at, usage) VALUES (%[2]d, 1, 'low', 1000, 600, %[3]d, %[4]d, %[5]d, %[6]d, '{"singular":500}');
+			`,
+			attempt+1, // ID of pending operation deleted in this attempt
+			attempt+2, // ID of pending operation created after this attempt
+			createdAt.Unix(),
+			confirmedAt.Unix(),
+			greenlitAt.Unix(),
+			s.Clock.Now().Add(5*time.Minute).Unix(),
+		)
+
+		// the operation is not picked up again until its retry_at has passed
+		err := resizeJob.ProcessOne(ctx)
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows, got %s instead", err.Error())
+		}
+		s.Clock.StepBy(10 * time.Minute)
+	}
+
+	// once the resize completes, the operation finishes normally
+	amStatic.SetAssetSizeInProgress = false
+	must.SucceedT(t, resizeJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			UPDATE assets SET expected_size = 600, resized_at = %[4]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, confirmed_at, greenlit_at, finished_at, usage) VALUES (1, 'low', 'succeeded', 1000, 600, %[1]d, %[2]d, %[3]d, %[4]d, '{"singular":500}');
+			DELETE FROM pending_operations WHERE id = 5 AND asset_id = 1;
+		`,
+		createdAt.Unix(),
+		confirmedAt.Unix(),
+		greenlitAt.Unix(),
+		s.Clock.Now().Unix(),
+	)
+}
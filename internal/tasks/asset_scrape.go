@@ -72,7 +72,7 @@ func (c *Context) processAssetScrape(ctx context.Context, tx *gsql.Tx, asset db.
 	}
 	labels["asset_type"] = string(res.AssetType)
 
-	manager, info := c.Team.ForAssetType(res.AssetType)
+	manager, info := c.Team.ForResource(res)
 	if manager == nil {
 		return fmt.Errorf("no asset manager for asset type %q", res.AssetType)
 	}
@@ -85,6 +85,17 @@ func (c *Context) processAssetScrape(ctx context.Context, tx *gsql.Tx, asset db.
 		return err
 	}
 
+	// merge overrides for this asset into the resource configuration
+	override, err := db.AssetOverrideStore.SelectOneOrNoneWhere(ctx, tx, `asset_id = $1`, asset.ID)
+	if err != nil {
+		return err
+	}
+	logic := core.LogicOfResource(res, info, override)
+	if asset.ExclusionReason != "" {
+		// excluded assets are still scraped for informational purposes, but never resized
+		logic.Disabled = true
+	}
+
 	// check asset status
 	oldStatus := None[core.AssetStatus]()
 	if !asset.NeverScraped {
@@ -93,6 +104,7 @@ func (c *Context) processAssetScrape(ctx context.Context, tx *gsql.Tx, asset db.
 			Usage:             asset.Usage,
 			StrictMinimumSize: asset.StrictMinimumSize,
 			StrictMaximumSize: asset.StrictMaximumSize,
+			BackendKey:        asset.BackendKey,
 		})
 	}
 	startedAt := c.TimeNow()
@@ -143,7 +155,7 @@ func (c *Context) processAssetScrape(ctx context.Context, tx *gsql.Tx, asset db.
 			))
 		}
 		logg.Info("observed %s %s at size = %d, %s, resource config = %s",
-			res.AssetType, asset.UUID, status.Size, strings.Join(valueLogStrings, ", "), must.Return(json.Marshal(core.LogicOfResource(res, info))),
+			res.AssetType, asset.UUID, status.Size, strings.Join(valueLogStrings, ", "), must.Return(json.Marshal(logic)),
 		)
 	}
 
@@ -154,6 +166,7 @@ func (c *Context) processAssetScrape(ctx context.Context, tx *gsql.Tx, asset db.
 	asset.ScrapeDurationSecs = finishedAt.Sub(startedAt).Seconds()
 	asset.ScrapeErrorMessage = ""
 	asset.NeverScraped = false
+	asset.BackendKey = status.BackendKey
 	var writeScrapeResults bool
 	switch {
 	case asset.ExpectedSize == None[uint64]():
@@ -199,10 +212,10 @@ func (c *Context) processAssetScrape(ctx context.Context, tx *gsql.Tx, asset db.
 
 	// compute value of `asset.CriticalUsages` field (for reporting to admin only)
 	var criticalUsageMetrics []string
-	if res.CriticalThresholdPercent.IsNonZero() && res.MaximumSize.IsNoneOr(is.Above(asset.Size)) {
+	if logic.CriticalThresholdPercent.IsNonZero() && logic.MaximumSize.IsNoneOr(is.Above(asset.Size)) {
 		usagePerc := core.GetMultiUsagePercent(asset.Size, asset.Usage)
 		for _, metric := range info.UsageMetrics {
-			if usagePerc[metric] >= res.CriticalThresholdPercent[metric] {
+			if criticalPerc := logic.CriticalThresholdPercent[metric]; criticalPerc > 0 && usagePerc[metric] >= criticalPerc {
 				criticalUsageMetrics = append(criticalUsageMetrics, string(metric))
 			}
 		}
@@ -218,6 +231,32 @@ func (c *Context) processAssetScrape(ctx context.Context, tx *gsql.Tx, asset db.
 		return tx.Commit()
 	}
 
+	// record usage history (this is also the basis for predictive upscaling)
+	cfg := c.Config.Current()
+	if res.PredictiveHorizonSeconds > 0 || cfg.UsageHistoryRetentionFor(res.AssetType).IsSome() {
+		err = db.AssetUsageSampleStore.Upsert(ctx, tx, &db.AssetUsageSample{
+			AssetID:    asset.ID,
+			RecordedAt: core.UsageSampleTimeFor(c.TimeNow()),
+			Size:       asset.Size,
+			Usage:      asset.Usage,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot record usage sample for %s %s: %w", res.AssetType, asset.UUID, err)
+		}
+	}
+
+	// for predictive upscaling, estimate the usage trend from recent samples
+	assetStatus := core.StatusOfAsset(asset, cfg, res)
+	if res.PredictiveHorizonSeconds > 0 {
+		samples, err := db.AssetUsageSampleStore.SelectWhere(ctx, tx,
+			`asset_id = $1 AND recorded_at >= $2 ORDER BY recorded_at`,
+			asset.ID, c.TimeNow().Add(-core.UsageTrendLookback)).Collect()
+		if err != nil {
+			return fmt.Errorf("cannot load usage samples for %s %s: %w", res.AssetType, asset.UUID, err)
+		}
+		assetStatus.UsageGrowthPerSecond = core.EstimateUsageGrowth(samples, info.UsageMetrics)
+	}
+
 	// never touch operations in status "greenlit" - they may be executing on a
 	// worker right now
 	if pendingOp.IsSomeAnd(func(op db.PendingOperation) bool { return op.GreenlitAt.IsSomeAnd(is.NotAfter(c.TimeNow())) }) {
@@ -226,27 +265,65 @@ func (c *Context) processAssetScrape(ctx context.Context, tx *gsql.Tx, asset db.
 
 	// if there is a pending operation, try to move it forward
 	if op, ok := pendingOp.Unpack(); ok {
-		pendingOp, err = c.maybeCancelOperation(ctx, tx, res, asset, info, op)
+		pendingOp, err = c.maybeCancelOperation(ctx, tx, res, asset, logic, assetStatus, op)
 		if err != nil {
 			return fmt.Errorf("cannot cancel operation on %s %s: %s", res.AssetType, asset.UUID, err.Error())
 		}
 	}
 	if op, ok := pendingOp.Unpack(); ok {
-		pendingOp, err = c.maybeUpdateOperation(ctx, tx, res, asset, info, op)
+		pendingOp, err = c.maybeUpdateOperation(ctx, tx, res, asset, logic, assetStatus, op)
 		if err != nil {
 			return fmt.Errorf("cannot update operation on %s %s: %s", res.AssetType, asset.UUID, err.Error())
 		}
 	}
 	if op, ok := pendingOp.Unpack(); ok {
-		pendingOp, err = c.maybeConfirmOperation(ctx, tx, res, asset, info, op)
+		pendingOp, err = c.maybeConfirmOperation(ctx, tx, res, asset, logic, assetStatus, op)
 		if err != nil {
 			return fmt.Errorf("cannot confirm operation on %s %s: %s", res.AssetType, asset.UUID, err.Error())
 		}
 	}
 
+	// if there is no pending operation (or if we just cancelled it), a broken
+	// asset gets repaired before anything else happens to it
+	if _, ok := manager.(core.AssetRepairer); ok && pendingOp.IsNone() && status.NeedsRepair && asset.ExclusionReason == "" {
+		pendingOp, err = c.createRepairOperation(ctx, tx, res, asset)
+		if err != nil {
+			return fmt.Errorf("cannot create repair operation on %s %s: %s", res.AssetType, asset.UUID, err.Error())
+		}
+	}
+
+	// if there is still no pending operation, the asset manager may perform
+	// maintenance on the asset
+	if maintainer, ok := manager.(core.AssetMaintainer); ok && pendingOp.IsNone() && asset.ExclusionReason == "" {
+		maintenanceStatus, err := maintainer.MaintainAsset(ctx, res, asset.UUID)
+		if err != nil {
+			maintenanceStatus = "cannot continue maintenance: " + err.Error()
+		}
+		if asset.MaintenanceStatus != maintenanceStatus {
+			asset.MaintenanceStatus = maintenanceStatus
+			dbErr := db.AssetStore.Update(ctx, tx, asset)
+			if dbErr != nil {
+				return dbErr
+			}
+		}
+		if err != nil {
+			dbErr := tx.Commit()
+			if dbErr != nil {
+				return dbErr
+			}
+			return fmt.Errorf("cannot perform maintenance on %s %s: %w", res.AssetType, asset.UUID, err)
+		}
+		if maintenanceStatus != "" {
+			// while maintenance is in progress, the asset's size and usage are in
+			// flux, so we do not start any operations that could interfere with it
+			logg.Info("maintenance in progress on %s %s: %s", res.AssetType, asset.UUID, maintenanceStatus)
+			return tx.Commit()
+		}
+	}
+
 	// if there is no pending operation (or if we just cancelled it), see if we can start one
 	if pendingOp.IsNone() {
-		err = c.maybeCreateOperation(ctx, tx, res, asset, info)
+		err = c.maybeCreateOperation(ctx, tx, res, asset, logic, assetStatus)
 		if err != nil {
 			return fmt.Errorf("cannot create operation on %s %s: %s", res.AssetType, asset.UUID, err.Error())
 		}
@@ -255,7 +332,7 @@ func (c *Context) processAssetScrape(ctx context.Context, tx *gsql.Tx, asset db.
 	return tx.Commit()
 }
 
-func (c Context) maybeCreateOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset, info core.AssetTypeInfo) error {
+func (c Context) maybeCreateOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset, logic core.ResourceLogic, status core.AssetStatus) error {
 	op := db.PendingOperation{
 		AssetID:   asset.ID,
 		OldSize:   asset.Size,
@@ -263,17 +340,10 @@ func (c Context) maybeCreateOperation(ctx context.Context, tx *gsql.Tx, res db.R
 		CreatedAt: c.TimeNow(),
 	}
 
-	eligibleFor := core.GetEligibleOperations(core.LogicOfResource(res, info), core.StatusOfAsset(asset, c.Config, res))
-	if val, exists := eligibleFor[castellum.OperationReasonCritical]; exists {
-		op.Reason = castellum.OperationReasonCritical
-		op.NewSize = val
-	} else if val, exists := eligibleFor[castellum.OperationReasonHigh]; exists {
-		op.Reason = castellum.OperationReasonHigh
-		op.NewSize = val
-	} else if val, exists := eligibleFor[castellum.OperationReasonLow]; exists {
-		op.Reason = castellum.OperationReasonLow
-		op.NewSize = val
-	} else {
+	eligibleFor := core.GetEligibleOperations(logic, status)
+	var ok bool
+	op.Reason, op.NewSize, ok = core.SelectOperation(eligibleFor)
+	if !ok {
 		// no threshold exceeded -> do not create an operation
 		return nil
 	}
@@ -284,20 +354,70 @@ func (c Context) maybeCreateOperation(ctx context.Context, tx *gsql.Tx, res db.R
 		return nil
 	}
 
+	// skip the operation if a user cancelled the same kind of operation on this
+	// asset and asked for it to not be recreated for a while
+	if asset.IsSnoozedFor(op.Reason, op.CreatedAt) {
+		return nil
+	}
+
+	// skip the operation if the last resize of this asset was too recent
+	if core.HasCooldown(res) {
+		lastResize, err := db.FinishedOperationStore.SelectOneOrNoneWhere(ctx, tx,
+			`asset_id = $1 AND outcome = 'succeeded' ORDER BY finished_at DESC LIMIT 1`, asset.ID)
+		if err != nil {
+			return err
+		}
+		if core.IsInCooldownFor(res, op.Reason, lastResize, op.CreatedAt) {
+			return nil
+		}
+	}
+
 	// critical operations can be confirmed immediately
 	if op.Reason == castellum.OperationReasonCritical {
 		op.ConfirmedAt = Some(op.CreatedAt)
-		// right now, nothing requires operator approval
-		op.GreenlitAt = op.ConfirmedAt
+		op.GreenlitAt = core.GreenlightTimeOf(res, op.Reason, op.CreatedAt)
 	}
 
-	core.CountStateTransition(res, asset.UUID, castellum.OperationStateDidNotExist, op.State())
+	transition := core.TransitionOfPendingOperation(res, asset.UUID, op, castellum.OperationStateDidNotExist, op.StateAt(c.TimeNow()))
+	err := c.Notifiers.RecordStateTransition(ctx, tx, transition, c.TimeNow())
+	if err != nil {
+		return err
+	}
 	return db.PendingOperationStore.Insert(ctx, tx, &op)
 }
 
-func (c Context) maybeCancelOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset, info core.AssetTypeInfo, op db.PendingOperation) (Option[db.PendingOperation], error) {
+func (c Context) createRepairOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset) (Option[db.PendingOperation], error) {
+	// repair operations are not tied to any threshold, so they are confirmed
+	// immediately (but still need to respect approval and maintenance windows)
+	now := c.TimeNow()
+	op := db.PendingOperation{
+		AssetID:     asset.ID,
+		Reason:      db.OperationReasonRepair,
+		OldSize:     asset.Size,
+		NewSize:     asset.Size,
+		Usage:       asset.Usage,
+		CreatedAt:   now,
+		ConfirmedAt: Some(now),
+		GreenlitAt:  core.GreenlightTimeOf(res, db.OperationReasonRepair, now),
+	}
+
+	transition := core.TransitionOfPendingOperation(res, asset.UUID, op, castellum.OperationStateDidNotExist, op.StateAt(now))
+	err := c.Notifiers.RecordStateTransition(ctx, tx, transition, now)
+	if err != nil {
+		return None[db.PendingOperation](), err
+	}
+	err = db.PendingOperationStore.Insert(ctx, tx, &op)
+	return Some(op), err
+}
+
+func (c Context) maybeCancelOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset, logic core.ResourceLogic, status core.AssetStatus, op db.PendingOperation) (Option[db.PendingOperation], error) {
+	// operations requested by a user or for repairing an asset are not tied to any threshold
+	if op.Reason == db.OperationReasonManual || op.Reason == db.OperationReasonRepair {
+		return Some(op), nil
+	}
+
 	// cancel when the threshold that triggered this operation is no longer being crossed
-	eligibleFor := core.GetEligibleOperations(core.LogicOfResource(res, info), core.StatusOfAsset(asset, c.Config, res))
+	eligibleFor := core.GetEligibleOperations(logic, status)
 	_, isEligible := eligibleFor[op.Reason]
 	if op.Reason == castellum.OperationReasonHigh {
 		if _, canBeUpgraded := eligibleFor[castellum.OperationReasonCritical]; canBeUpgraded {
@@ -312,9 +432,13 @@ func (c Context) maybeCancelOperation(ctx context.Context, tx *gsql.Tx, res db.R
 		return Some(op), nil
 	}
 
-	core.CountStateTransition(res, asset.UUID, op.State(), castellum.OperationStateCancelled)
 	finishedOp := op.IntoFinishedOperation(castellum.OperationOutcomeCancelled, c.TimeNow())
-	err := db.PendingOperationStore.Delete(ctx, tx, op)
+	transition := core.TransitionOfFinishedOperation(res, asset.UUID, finishedOp, op.StateAt(c.TimeNow()))
+	err := c.Notifiers.RecordStateTransition(ctx, tx, transition, c.TimeNow())
+	if err != nil {
+		return None[db.PendingOperation](), err
+	}
+	err = db.PendingOperationStore.Delete(ctx, tx, op)
 	if err != nil {
 		return None[db.PendingOperation](), err
 	}
@@ -322,9 +446,9 @@ func (c Context) maybeCancelOperation(ctx context.Context, tx *gsql.Tx, res db.R
 	return None[db.PendingOperation](), err
 }
 
-func (c Context) maybeUpdateOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset, info core.AssetTypeInfo, op db.PendingOperation) (Option[db.PendingOperation], error) {
+func (c Context) maybeUpdateOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset, logic core.ResourceLogic, status core.AssetStatus, op db.PendingOperation) (Option[db.PendingOperation], error) {
 	// do not touch `op` unless the corresponding threshold is still being crossed
-	eligibleFor := core.GetEligibleOperations(core.LogicOfResource(res, info), core.StatusOfAsset(asset, c.Config, res))
+	eligibleFor := core.GetEligibleOperations(logic, status)
 	newSize, exists := eligibleFor[op.Reason]
 	if !exists {
 		return Some(op), nil
@@ -341,32 +465,68 @@ func (c Context) maybeUpdateOperation(ctx context.Context, tx *gsql.Tx, res db.R
 	return Some(op), err
 }
 
-func (c Context) maybeConfirmOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset, info core.AssetTypeInfo, op db.PendingOperation) (Option[db.PendingOperation], error) {
+func (c Context) maybeConfirmOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset, logic core.ResourceLogic, status core.AssetStatus, op db.PendingOperation) (Option[db.PendingOperation], error) {
+	// operations that are already confirmed are only waiting for their greenlight
+	// (either by a user, or by their scheduled greenlight time arriving)
+	if op.ConfirmedAt.IsSome() {
+		return c.maybeRescheduleOperation(ctx, tx, res, asset, op)
+	}
+
 	// can only confirm when the corresponding threshold is still being crossed
-	if _, exists := core.GetEligibleOperations(core.LogicOfResource(res, info), core.StatusOfAsset(asset, c.Config, res))[op.Reason]; !exists {
+	if _, exists := core.GetEligibleOperations(logic, status)[op.Reason]; !exists {
 		return Some(op), nil
 	}
 
 	// can only confirm when it has been like this for at least the configured delay
-	var earliestConfirm time.Time
-	switch op.Reason {
-	case castellum.OperationReasonLow:
-		earliestConfirm = op.CreatedAt.Add(time.Duration(res.LowDelaySeconds) * time.Second)
-	case castellum.OperationReasonHigh:
-		earliestConfirm = op.CreatedAt.Add(time.Duration(res.HighDelaySeconds) * time.Second)
-	case castellum.OperationReasonCritical:
-		// defense in depth - maybeCreateOperation() should already have confirmed this
-		earliestConfirm = op.CreatedAt
-	}
-	if c.TimeNow().Before(earliestConfirm) {
+	// (for critical operations, this is defense in depth - maybeCreateOperation()
+	// should already have confirmed them)
+	if c.TimeNow().Before(core.EarliestConfirmationOf(res, op.Reason, op.CreatedAt)) {
 		return Some(op), nil
 	}
 
-	previousState := op.State()
+	previousState := op.StateAt(c.TimeNow())
 	confirmedAt := c.TimeNow()
 	op.ConfirmedAt = Some(confirmedAt)
-	op.GreenlitAt = op.ConfirmedAt // right now, nothing requires operator approval
+	op.GreenlitAt = core.GreenlightTimeOf(res, op.Reason, confirmedAt)
 	err := db.PendingOperationStore.Update(ctx, tx, op)
-	core.CountStateTransition(res, asset.UUID, previousState, op.State())
+	if err != nil {
+		return Some(op), err
+	}
+	transition := core.TransitionOfPendingOperation(res, asset.UUID, op, previousState, op.StateAt(c.TimeNow()))
+	err = c.Notifiers.RecordStateTransition(ctx, tx, transition, c.TimeNow())
+	return Some(op), err
+}
+
+func (c Context) maybeRescheduleOperation(ctx context.Context, tx *gsql.Tx, res db.Resource, asset db.Asset, op db.PendingOperation) (Option[db.PendingOperation], error) {
+	// a greenlight given by a user is never overridden (except for manual
+	// operations, whose greenlight time is taken from the maintenance window
+	// rather than chosen by the user)
+	if op.GreenlitByUserUUID.IsSome() && op.Reason != db.OperationReasonManual {
+		return Some(op), nil
+	}
+
+	// otherwise the greenlight time is recomputed in case the resource
+	// configuration has changed since the operation was confirmed (e.g. when
+	// approval is no longer required); since the caller does not give us
+	// operations that are already greenlit, we only move from "now" onwards
+	now := c.TimeNow()
+	greenlitAt := core.GreenlightTimeOf(res, op.Reason, now)
+	if greenlitAt.IsNone() && op.GreenlitAt.IsNone() {
+		return Some(op), nil
+	}
+	if oldValue, ok := op.GreenlitAt.Unpack(); ok && greenlitAt.IsSomeAnd(oldValue.Equal) {
+		return Some(op), nil
+	}
+
+	previousState := op.StateAt(now)
+	op.GreenlitAt = greenlitAt
+	err := db.PendingOperationStore.Update(ctx, tx, op)
+	if err != nil {
+		return Some(op), err
+	}
+	if newState := op.StateAt(now); previousState != newState {
+		transition := core.TransitionOfPendingOperation(res, asset.UUID, op, previousState, newState)
+		err = c.Notifiers.RecordStateTransition(ctx, tx, transition, now)
+	}
 	return Some(op), err
 }
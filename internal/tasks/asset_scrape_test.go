@@ -318,6 +318,433 @@ func TestCriticalUpsizeTowardsGreenlight(t *testing.T) {
 	})
 }
 
+func TestUpsizeRequiringApproval(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET high_requires_approval = TRUE, critical_requires_approval = TRUE`))
+		tr.DBChanges().Ignore()
+
+		// when the "High" threshold gets crossed, a "High" operation gets created in
+		// state "created"
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 800})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":800}', next_scrape_at = %[1]d, never_scraped = FALSE WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, usage) VALUES (1, 1, 'high', 1000, 1200, %[2]d, '{"singular":800}');
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+
+		// when the delay is over, the operation only moves into state "Confirmed"
+		// since it requires approval
+		s.Clock.StepBy(time.Hour)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				UPDATE pending_operations SET confirmed_at = %[2]d WHERE id = 1 AND asset_id = 1;
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+
+		// further scrapes do not change the operation while it waits for approval
+		s.Clock.StepBy(time.Hour)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+		)
+
+		// when a user greenlights the operation (as through the API), it is
+		// greenlit and will not be touched by the observer anymore, even if the
+		// reason disappears
+		greenlitAt := s.Clock.Now()
+		must.SucceedT(t, s.DBExec(`UPDATE pending_operations SET greenlit_at = $1, greenlit_by_user_uuid = $2`, greenlitAt, "user1"))
+		tr.DBChanges().Ignore()
+
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 780})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":780}', next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+		)
+
+		// the operation is then executed by a worker as usual
+		resizeJob := s.TaskContext.AssetResizingJob(s.Registry)
+		must.SucceedT(t, resizeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET expected_size = 1200, resized_at = %[4]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, confirmed_at, greenlit_at, finished_at, greenlit_by_user_uuid, usage) VALUES (1, 'high', 'succeeded', 1000, 1200, %[1]d, %[2]d, %[3]d, %[4]d, 'user1', '{"singular":800}');
+				DELETE FROM pending_operations WHERE id = 1 AND asset_id = 1;
+			`,
+			s.Clock.Now().Add(-130*time.Minute).Unix(),
+			s.Clock.Now().Add(-70*time.Minute).Unix(),
+			greenlitAt.Unix(),
+			s.Clock.Now().Unix(),
+		)
+	})
+}
+
+func TestUpsizeRequiringApprovalTowardsCancel(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET high_requires_approval = TRUE`))
+		tr.DBChanges().Ignore()
+
+		// when the "High" threshold gets crossed, a "High" operation gets created
+		// and, after the delay, confirmed without being greenlit
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 800})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+		s.Clock.StepBy(time.Hour)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":800}', next_scrape_at = %[1]d, never_scraped = FALSE WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, confirmed_at, usage) VALUES (1, 1, 'high', 1000, 1200, %[2]d, %[3]d, '{"singular":800}');
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Add(-time.Hour).Unix(),
+			s.Clock.Now().Unix(),
+		)
+
+		// while waiting for approval, the operation gets cancelled when usage goes
+		// back to normal
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 700})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":700}', next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, confirmed_at, finished_at, usage) VALUES (1, 'high', 'cancelled', 1000, 1200, %[2]d, %[3]d, %[4]d, '{"singular":800}');
+				DELETE FROM pending_operations WHERE id = 1 AND asset_id = 1;
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Add(-70*time.Minute).Unix(),
+			s.Clock.Now().Add(-10*time.Minute).Unix(),
+			s.Clock.Now().Unix(),
+		)
+	})
+}
+
+func TestUpsizeAfterApprovalRequirementIsLifted(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET high_requires_approval = TRUE`))
+		tr.DBChanges().Ignore()
+
+		// get a "High" operation into state "confirmed" where it waits for approval
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 800})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+		s.Clock.StepBy(time.Hour)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+		tr.DBChanges().Ignore()
+
+		// when approval is not required anymore, the next scrape greenlights the
+		// operation instead of leaving it stranded
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET high_requires_approval = FALSE`))
+		tr.DBChanges().Ignore()
+
+		s.Clock.StepBy(10 * time.Minute)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				UPDATE pending_operations SET greenlit_at = %[2]d WHERE id = 1 AND asset_id = 1;
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+	})
+}
+
+func TestCriticalUpsizeRequiringApproval(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET critical_requires_approval = TRUE`))
+		tr.DBChanges().Ignore()
+
+		// when the "Critical" threshold gets crossed, a "Critical" operation gets
+		// created and immediately confirmed, but not greenlit
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 950})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":950}', critical_usages = 'singular', next_scrape_at = %[1]d, never_scraped = FALSE WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, confirmed_at, usage) VALUES (1, 1, 'critical', 1000, 1200, %[2]d, %[2]d, '{"singular":950}');
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+	})
+}
+
+func TestUpsizeWithMaintenanceWindow(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		// maintenance window is between 12:00 and 13:00 UTC every day (the test
+		// clock starts shortly after midnight)
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = $1`, `{"start":"12:00","end":"13:00"}`))
+		tr.DBChanges().Ignore()
+		windowStart := time.Unix(12*3600, 0).UTC()
+
+		// a "High" operation gets created in state "created" as usual...
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 800})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+		tr.DBChanges().Ignore()
+
+		// ...but when it gets confirmed, it will only be greenlit once the maintenance window begins
+		s.Clock.StepBy(time.Hour)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				UPDATE pending_operations SET confirmed_at = %[2]d, greenlit_at = %[3]d WHERE id = 1 AND asset_id = 1;
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+			windowStart.Unix(),
+		)
+
+		// while waiting for the maintenance window, the operation can still be
+		// cancelled when usage goes back to normal (since it was never greenlit,
+		// the scheduled greenlight is not carried over into the finished operation)
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 700})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":700}', next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, confirmed_at, finished_at, usage) VALUES (1, 'high', 'cancelled', 1000, 1200, %[2]d, %[3]d, %[4]d, '{"singular":800}');
+				DELETE FROM pending_operations WHERE id = 1 AND asset_id = 1;
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Add(-80*time.Minute).Unix(),
+			s.Clock.Now().Add(-10*time.Minute).Unix(),
+			s.Clock.Now().Unix(),
+		)
+	})
+}
+
+func TestCriticalUpsizeWithMaintenanceWindow(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		// when critical operations bypass the maintenance window, they get greenlit immediately
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = $1`, `{"start":"12:00","end":"13:00","critical_bypass":true}`))
+		tr.DBChanges().Ignore()
+
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 950})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":950}', critical_usages = 'singular', next_scrape_at = %[1]d, never_scraped = FALSE WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, confirmed_at, greenlit_at, usage) VALUES (1, 1, 'critical', 1000, 1200, %[2]d, %[2]d, %[2]d, '{"singular":950}');
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+	})
+}
+
+func TestUpsizeAfterMaintenanceWindowChanges(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		// get a "High" operation into state "confirmed" where it waits for the
+		// maintenance window between 12:00 and 13:00 UTC
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = $1`, `{"start":"12:00","end":"13:00"}`))
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 800})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+		s.Clock.StepBy(time.Hour)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+		tr.DBChanges().Ignore()
+
+		// when the maintenance window is moved, the next scrape moves the scheduled greenlight along with it
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = $1`, `{"start":"15:00","end":"16:00"}`))
+		tr.DBChanges().Ignore()
+
+		s.Clock.StepBy(10 * time.Minute)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				UPDATE pending_operations SET greenlit_at = %[2]d WHERE id = 1 AND asset_id = 1;
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			15*3600,
+		)
+
+		// further scrapes do not change the scheduled greenlight
+		s.Clock.StepBy(10 * time.Minute)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+		)
+
+		// a greenlight that was scheduled by a user is not touched when the
+		// maintenance window changes
+		must.SucceedT(t, s.DBExec(`UPDATE pending_operations SET greenlit_by_user_uuid = $1`, "user1"))
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = $1`, `{"start":"18:00","end":"19:00"}`))
+		tr.DBChanges().Ignore()
+
+		s.Clock.StepBy(10 * time.Minute)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+		)
+
+		// when the maintenance window is removed, an operation without a greenlight
+		// from a user is greenlit immediately
+		must.SucceedT(t, s.DBExec(`UPDATE pending_operations SET greenlit_by_user_uuid = NULL`))
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = ''`))
+		tr.DBChanges().Ignore()
+
+		s.Clock.StepBy(10 * time.Minute)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				UPDATE pending_operations SET greenlit_at = %[2]d WHERE id = 1 AND asset_id = 1;
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+	})
+}
+
+func TestManualResizeIsNotCancelled(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		// a manual operation (as created through the API) is waiting for the
+		// maintenance window between 12:00 and 13:00 UTC
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = $1`, `{"start":"12:00","end":"13:00"}`))
+		must.SucceedT(t, db.PendingOperationStore.Insert(ctx, s.DB, &db.PendingOperation{
+			AssetID:            1,
+			Reason:             db.OperationReasonManual,
+			OldSize:            1000,
+			NewSize:            2000,
+			Usage:              castellum.UsageValues{castellum.SingularUsageMetric: 500},
+			CreatedAt:          s.Clock.Now(),
+			ConfirmedAt:        Some(s.Clock.Now()),
+			GreenlitAt:         Some(time.Unix(12*3600, 0).UTC()),
+			GreenlitByUserUUID: Some("user1"),
+		}))
+
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		// since no threshold is crossed, a normal operation would be cancelled, but
+		// a manual operation is left alone
+		s.Clock.StepBy(10 * time.Minute)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d, never_scraped = FALSE WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+		)
+
+		// the greenlight of a manual operation was derived from the maintenance
+		// window, so it follows along when the window is moved
+		must.SucceedT(t, s.DBExec(`UPDATE resources SET maintenance_window_json = $1`, `{"start":"15:00","end":"16:00"}`))
+		tr.DBChanges().Ignore()
+
+		s.Clock.StepBy(10 * time.Minute)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				UPDATE pending_operations SET greenlit_at = %[2]d WHERE id = 1 AND asset_id = 1;
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			15*3600,
+		)
+	})
+}
+
+func TestSnoozedOperationIsNotRecreated(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		// a user has cancelled a "High" operation and snoozed it for one hour (as through the API)
+		must.SucceedT(t, s.DBExec(`UPDATE assets SET snoozed_reason = 'high', snoozed_until = $1`, s.Clock.Now().Add(time.Hour)))
+		tr.DBChanges().Ignore()
+
+		// while the snooze is active, no "High" operation gets created
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 800})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":800}', next_scrape_at = %[1]d, never_scraped = FALSE WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+		)
+
+		// operations with a different reason are not affected by the snooze
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 950})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":950}', critical_usages = 'singular', next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, confirmed_at, greenlit_at, usage) VALUES (1, 1, 'critical', 1000, 1200, %[2]d, %[2]d, %[2]d, '{"singular":950}');
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+		must.SucceedT(t, s.DBExec(`DELETE FROM pending_operations`))
+		tr.DBChanges().Ignore()
+
+		// once the snooze expires, the "High" operation gets created as usual
+		s.Clock.StepBy(time.Hour)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 800})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":800}', critical_usages = '', next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, usage) VALUES (2, 1, 'high', 1000, 1200, %[2]d, '{"singular":800}');
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+	})
+}
+
 func TestReplaceNormalWithCriticalUpsize(t *testing.T) {
 	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
 		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
@@ -697,3 +1124,227 @@ func TestMaxAssetSizeRules(t *testing.T) {
 		s.Clock.Now().Unix(),
 	)
 }
+
+func TestPredictiveUpsize(t *testing.T) {
+	ctx := t.Context()
+	s := test.NewSetup(t,
+		commonSetupOptionsForWorkerTest(),
+	)
+	scrapeJob := s.TaskContext.AssetScrapingJob(s.Registry)
+	must.SucceedT(t, db.ResourceStore.Insert(ctx, s.DB, &db.Resource{
+		ScopeUUID:                "project1",
+		AssetType:                "foo",
+		LowThresholdPercent:      castellum.UsageValues{castellum.SingularUsageMetric: 20},
+		LowDelaySeconds:          3600,
+		HighThresholdPercent:     castellum.UsageValues{castellum.SingularUsageMetric: 80},
+		HighDelaySeconds:         3600,
+		CriticalThresholdPercent: castellum.UsageValues{castellum.SingularUsageMetric: 95},
+		SizeStepPercent:          20,
+		PredictiveHorizonSeconds: 3600,
+	}))
+	must.SucceedT(t, db.AssetStore.Insert(ctx, s.DB, &db.Asset{
+		ResourceID:   1,
+		UUID:         "asset1",
+		Size:         1000,
+		Usage:        castellum.UsageValues{castellum.SingularUsageMetric: 400},
+		NextScrapeAt: s.Clock.Now(),
+		ExpectedSize: None[uint64](),
+	}))
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// usage grows by 100 every 10 minutes, so the asset will be full in less
+	// than an hour; but until enough samples have been collected, no operation
+	// is created
+	amStatic := s.ManagerForAssetType("foo")
+	setAsset := func(usage uint64) {
+		amStatic.Assets = map[string]map[string]plugins.StaticAsset{
+			"project1": {"asset1": {Size: 1000, Usage: usage}},
+		}
+	}
+
+	setAsset(500)
+	s.Clock.StepBy(10 * time.Minute)
+	t1 := s.Clock.Now()
+	must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			INSERT INTO asset_usage_samples (asset_id, recorded_at, size, usage) VALUES (1, %[1]d, 1000, '{"singular":500}');
+			UPDATE assets SET usage = '{"singular":500}', next_scrape_at = %[2]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+		`,
+		t1.Unix(), t1.Add(tasks.AssetScrapeInterval).Unix(),
+	)
+
+	setAsset(600)
+	s.Clock.StepBy(10 * time.Minute)
+	t2 := s.Clock.Now()
+	must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			INSERT INTO asset_usage_samples (asset_id, recorded_at, size, usage) VALUES (1, %[1]d, 1000, '{"singular":600}');
+			UPDATE assets SET usage = '{"singular":600}', next_scrape_at = %[2]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+		`,
+		t2.Unix(), t2.Add(tasks.AssetScrapeInterval).Unix(),
+	)
+
+	// with the third sample, the trend projects usage of 1300 within the
+	// horizon, so a "high" operation is created even though usage is below
+	// the high threshold; the target size keeps the projected usage below 80%
+	setAsset(700)
+	s.Clock.StepBy(10 * time.Minute)
+	t3 := s.Clock.Now()
+	must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			INSERT INTO asset_usage_samples (asset_id, recorded_at, size, usage) VALUES (1, %[1]d, 1000, '{"singular":700}');
+			UPDATE assets SET usage = '{"singular":700}', next_scrape_at = %[2]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, usage) VALUES (1, 1, 'high', 1000, 1626, %[1]d, '{"singular":700}');
+		`,
+		t3.Unix(), t3.Add(tasks.AssetScrapeInterval).Unix(),
+	)
+}
+
+func TestNoOperationDuringCooldown(t *testing.T) {
+	ctx := t.Context()
+	s := test.NewSetup(t,
+		commonSetupOptionsForWorkerTest(),
+	)
+	scrapeJob := s.TaskContext.AssetScrapingJob(s.Registry)
+	must.SucceedT(t, db.ResourceStore.Insert(ctx, s.DB, &db.Resource{
+		ScopeUUID:            "project1",
+		AssetType:            "foo",
+		LowThresholdPercent:  castellum.UsageValues{castellum.SingularUsageMetric: 20},
+		LowDelaySeconds:      3600,
+		HighThresholdPercent: castellum.UsageValues{castellum.SingularUsageMetric: 80},
+		HighDelaySeconds:     3600,
+		SizeStepPercent:      20,
+		LowCooldownSeconds:   3600,
+	}))
+	must.SucceedT(t, db.AssetStore.Insert(ctx, s.DB, &db.Asset{
+		ResourceID:   1,
+		UUID:         "asset1",
+		Size:         1000,
+		Usage:        castellum.UsageValues{castellum.SingularUsageMetric: 850},
+		NextScrapeAt: s.Clock.Now(),
+		ExpectedSize: None[uint64](),
+	}))
+	// the asset was just upsized
+	must.SucceedT(t, db.FinishedOperationStore.Insert(ctx, s.DB, &db.FinishedOperation{
+		AssetID:     1,
+		Reason:      castellum.OperationReasonHigh,
+		Outcome:     castellum.OperationOutcomeSucceeded,
+		OldSize:     800,
+		NewSize:     1000,
+		Usage:       castellum.UsageValues{castellum.SingularUsageMetric: 700},
+		CreatedAt:   s.Clock.Now().Add(-2 * time.Hour),
+		ConfirmedAt: Some(s.Clock.Now().Add(-1 * time.Hour)),
+		GreenlitAt:  Some(s.Clock.Now().Add(-1 * time.Hour)),
+		FinishedAt:  s.Clock.Now(),
+	}))
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// usage drops below the low threshold, but no operation is created while
+	// the asset is in cooldown
+	amStatic := s.ManagerForAssetType("foo")
+	amStatic.Assets = map[string]map[string]plugins.StaticAsset{
+		"project1": {"asset1": {Size: 1000, Usage: 100}},
+	}
+	s.Clock.StepBy(30 * time.Minute)
+	must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			UPDATE assets SET usage = '{"singular":100}', next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+		`,
+		s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+	)
+
+	// when the cooldown has passed, the operation is created
+	s.Clock.StepBy(30 * time.Minute)
+	must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			UPDATE assets SET next_scrape_at = %[1]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, usage) VALUES (1, 1, 'low', 1000, 800, %[2]d, '{"singular":100}');
+		`,
+		s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+		s.Clock.Now().Unix(),
+	)
+}
+
+func TestNoOperationDuringMaintenance(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		// while the asset manager reports maintenance in progress, the
+		// maintenance status is recorded, but no operation is created even
+		// though the "High" threshold is crossed
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 800, MaintenanceStatus: "replacing things"})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET usage = '{"singular":800}', next_scrape_at = %[1]d, never_scraped = FALSE, maintenance_status = 'replacing things' WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+		)
+
+		// when the maintenance is complete, the operation is created
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 800})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d, maintenance_status = '' WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, usage) VALUES (1, 1, 'high', 1000, 1200, %[2]d, '{"singular":800}');
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+	})
+}
+
+func TestRepairOperation(t *testing.T) {
+	runAssetScrapeTest(t, func(ctx context.Context, s test.Setup, setAsset func(plugins.StaticAsset), scrapeJob jobloop.Job) {
+		resizeJob := s.TaskContext.AssetResizingJob(s.Registry)
+		tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+		tr0.Ignore()
+
+		// when the asset manager reports that the asset needs repair, a repair
+		// operation is created and greenlit immediately even though no threshold
+		// is crossed
+		s.Clock.StepBy(10 * time.Minute)
+		setAsset(plugins.StaticAsset{Size: 1000, Usage: 500, NeedsRepair: true})
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d, never_scraped = FALSE WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO pending_operations (id, asset_id, reason, old_size, new_size, created_at, confirmed_at, greenlit_at, usage) VALUES (1, 1, 'repair', 1000, 1000, %[2]d, %[2]d, %[2]d, '{"singular":500}');
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+			s.Clock.Now().Unix(),
+		)
+		createdAt := s.Clock.Now()
+
+		// the worker executes the repair operation by calling RepairAsset()
+		s.Clock.StepBy(time.Minute)
+		must.SucceedT(t, resizeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET expected_size = 1000, resized_at = %[2]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+				INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, confirmed_at, greenlit_at, finished_at, usage) VALUES (1, 'repair', 'succeeded', 1000, 1000, %[1]d, %[1]d, %[1]d, %[2]d, '{"singular":500}');
+				DELETE FROM pending_operations WHERE id = 1 AND asset_id = 1;
+			`,
+			createdAt.Unix(),
+			s.Clock.Now().Unix(),
+		)
+
+		// once the asset is repaired, no further repair operation is created
+		s.Clock.StepBy(10 * time.Minute)
+		must.SucceedT(t, scrapeJob.ProcessOne(ctx))
+
+		tr.DBChanges().AssertEqualf(`
+				UPDATE assets SET next_scrape_at = %[1]d, expected_size = NULL, resized_at = NULL WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			`,
+			s.Clock.Now().Add(tasks.AssetScrapeInterval).Unix(),
+		)
+	})
+}
@@ -15,9 +15,10 @@ import (
 // Context holds things used by the various task implementations in this
 // package.
 type Context struct {
-	Config         core.Config
+	Config         *core.ConfigSource
 	DB             *gsql.DB
 	Team           core.AssetManagerTeam
+	Notifiers      core.NotifierTeam
 	ProviderClient core.ProviderClient
 
 	// dependency injection slots (usually filled by ApplyDefaults(), but filled
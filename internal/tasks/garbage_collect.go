@@ -5,11 +5,16 @@ package tasks
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sapcc/go-bits/jobloop"
+	"github.com/sapcc/go-bits/sqlext"
 	"go.xyrillian.de/gg/gsql"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
 )
 
 // GarbageCollectionJob removes old entries from the finished_operations table.
@@ -24,13 +29,86 @@ func (c *Context) GarbageCollectionJob(registerer prometheus.Registerer) jobloop
 		},
 		Interval: 1 * time.Hour,
 		Task: func(ctx context.Context, _ prometheus.Labels) error {
-			return CollectGarbage(c.DB, time.Now().Add(-14*24*time.Hour)) // 14 days
+			return CollectGarbage(ctx, c.DB, c.Config.Current(), c.TimeNow())
+		},
+	}).Setup(registerer)
+}
+
+var collectGarbageQuery = sqlext.SimplifyWhitespace(`
+	DELETE FROM finished_operations o
+	 USING assets a, resources r
+	 WHERE o.asset_id = a.id AND a.resource_id = r.id AND r.asset_type = $1 AND o.finished_at < $2
+`)
+
+// CollectGarbage removes entries from the finished_operations table that are
+// older than the retention period configured for their respective asset type.
+func CollectGarbage(ctx context.Context, dbi *gsql.DB, cfg core.Config, now time.Time) error {
+	var assetTypes []db.AssetType
+	err := sqlext.ForeachRow(dbi, `SELECT DISTINCT asset_type FROM resources`, nil, func(rows *sql.Rows) error {
+		var assetType db.AssetType
+		err := rows.Scan(&assetType)
+		assetTypes = append(assetTypes, assetType)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, assetType := range assetTypes {
+		maxFinishedAt := now.Add(-cfg.OperationRetentionFor(assetType))
+		_, err := dbi.ExecContext(ctx, collectGarbageQuery, assetType, maxFinishedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UsageHistoryGarbageCollectionJob removes old entries from the asset_usage_samples table.
+func (c *Context) UsageHistoryGarbageCollectionJob(registerer prometheus.Registerer) jobloop.Job {
+	return (&jobloop.CronJob{
+		Metadata: jobloop.JobMetadata{
+			ReadableName: "garbage collection of asset usage samples",
+			CounterOpts: prometheus.CounterOpts{
+				Name: "castellum_usage_history_garbage_collection_runs",
+				Help: "Counter for garbage collection runs of the asset_usage_samples table.",
+			},
+		},
+		Interval: 1 * time.Hour,
+		Task: func(ctx context.Context, _ prometheus.Labels) error {
+			return CollectUsageHistoryGarbage(ctx, c.DB, c.Config.Current(), c.TimeNow())
 		},
 	}).Setup(registerer)
 }
 
-// CollectGarbage removes old entries from the finished_operations table.
-func CollectGarbage(dbi *gsql.DB, maxLastUpdatedAt time.Time) error {
-	_, err := dbi.Exec(`DELETE FROM finished_operations WHERE finished_at < $1`, maxLastUpdatedAt)
-	return err
+var collectUsageHistoryGarbageQuery = sqlext.SimplifyWhitespace(`
+	DELETE FROM asset_usage_samples s
+	 USING assets a, resources r
+	 WHERE s.asset_id = a.id AND a.resource_id = r.id AND r.asset_type = $1 AND s.recorded_at < $2
+`)
+
+// CollectUsageHistoryGarbage removes entries from the asset_usage_samples
+// table that are older than the usage history retention period configured for
+// their respective asset type. If no usage history is retained for an asset
+// type, only the samples needed for predictive upscaling are kept.
+func CollectUsageHistoryGarbage(ctx context.Context, dbi *gsql.DB, cfg core.Config, now time.Time) error {
+	var assetTypes []db.AssetType
+	err := sqlext.ForeachRow(dbi, `SELECT DISTINCT asset_type FROM resources`, nil, func(rows *sql.Rows) error {
+		var assetType db.AssetType
+		err := rows.Scan(&assetType)
+		assetTypes = append(assetTypes, assetType)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, assetType := range assetTypes {
+		retention := cfg.UsageHistoryRetentionFor(assetType).UnwrapOr(core.UsageTrendLookback)
+		_, err := dbi.ExecContext(ctx, collectUsageHistoryGarbageQuery, assetType, now.Add(-retention))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -20,24 +20,24 @@ import (
 func TestCollectGarbage(t *testing.T) {
 	s := test.NewSetup(t,
 		commonSetupOptionsForWorkerTest(),
+		// finished operations for "bar" are kept for longer than the default of 14 days
+		test.WithConfig(`{"operation_retention":[{"asset_type":"bar","days":30}]}`),
 	)
 	ctx := t.Context()
-	fakeNow := time.Unix(0, 0).UTC()
+	fakeNow := time.Unix(100*86400, 0).UTC()
+	day := 24 * time.Hour
 
 	// setup some minimal scaffolding (we can only insert finished_operations
 	// with valid asset IDs into the DB)
-	must.SucceedT(t, db.ResourceStore.Insert(ctx, s.DB, &db.Resource{
-		ScopeUUID: "project1",
-		AssetType: "foo",
-	}))
-	must.SucceedT(t, db.AssetStore.Insert(ctx, s.DB, &db.Asset{
-		ResourceID: 1,
-		UUID:       "asset1",
-	}))
-	must.SucceedT(t, db.AssetStore.Insert(ctx, s.DB, &db.Asset{
-		ResourceID: 1,
-		UUID:       "asset2",
-	}))
+	must.SucceedT(t, db.ResourceStore.Insert(ctx, s.DB,
+		&db.Resource{ScopeUUID: "project1", AssetType: "foo"},
+		&db.Resource{ScopeUUID: "project1", AssetType: "bar"},
+	))
+	must.SucceedT(t, db.AssetStore.Insert(ctx, s.DB,
+		&db.Asset{ResourceID: 1, UUID: "asset1"},
+		&db.Asset{ResourceID: 1, UUID: "asset2"},
+		&db.Asset{ResourceID: 2, UUID: "asset3"},
+	))
 
 	ops := []*db.FinishedOperation{
 		{
@@ -47,30 +47,40 @@ func TestCollectGarbage(t *testing.T) {
 			OldSize:    1000,
 			NewSize:    1200,
 			Usage:      castellum.UsageValues{castellum.SingularUsageMetric: 800},
-			CreatedAt:  fakeNow.Add(-40 * time.Minute),
-			FinishedAt: fakeNow.Add(-30 * time.Minute),
+			CreatedAt:  fakeNow.Add(-21 * day),
+			FinishedAt: fakeNow.Add(-20 * day),
 		},
 		{
-			AssetID:    2,
+			AssetID:     2,
+			Reason:      castellum.OperationReasonCritical,
+			Outcome:     castellum.OperationOutcomeSucceeded,
+			OldSize:     1000,
+			NewSize:     1200,
+			Usage:       castellum.UsageValues{castellum.SingularUsageMetric: 800},
+			CreatedAt:   fakeNow.Add(-10 * day),
+			ConfirmedAt: Some(fakeNow.Add(-10 * day)),
+			GreenlitAt:  Some(fakeNow.Add(-10 * day)),
+			FinishedAt:  fakeNow.Add(-10 * day),
+		},
+		{
+			AssetID:    3,
 			Reason:     castellum.OperationReasonHigh,
 			Outcome:    castellum.OperationOutcomeCancelled,
 			OldSize:    1000,
 			NewSize:    1200,
 			Usage:      castellum.UsageValues{castellum.SingularUsageMetric: 800},
-			CreatedAt:  fakeNow.Add(-25 * time.Minute),
-			FinishedAt: fakeNow.Add(-20 * time.Minute),
+			CreatedAt:  fakeNow.Add(-41 * day),
+			FinishedAt: fakeNow.Add(-40 * day),
 		},
 		{
-			AssetID:     2,
-			Reason:      castellum.OperationReasonCritical,
-			Outcome:     castellum.OperationOutcomeSucceeded,
-			OldSize:     1000,
-			NewSize:     1200,
-			Usage:       castellum.UsageValues{castellum.SingularUsageMetric: 800},
-			CreatedAt:   fakeNow.Add(-20 * time.Minute),
-			ConfirmedAt: Some(fakeNow.Add(-20 * time.Minute)),
-			GreenlitAt:  Some(fakeNow.Add(-20 * time.Minute)),
-			FinishedAt:  fakeNow.Add(-10 * time.Minute),
+			AssetID:    3,
+			Reason:     castellum.OperationReasonLow,
+			Outcome:    castellum.OperationOutcomeCancelled,
+			OldSize:    1200,
+			NewSize:    1000,
+			Usage:      castellum.UsageValues{castellum.SingularUsageMetric: 200},
+			CreatedAt:  fakeNow.Add(-21 * day),
+			FinishedAt: fakeNow.Add(-20 * day),
 		},
 	}
 	must.SucceedT(t, db.FinishedOperationStore.Insert(ctx, s.DB, ops...))
@@ -78,16 +88,58 @@ func TestCollectGarbage(t *testing.T) {
 	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
 	tr0.Ignore()
 
-	must.SucceedT(t, tasks.CollectGarbage(s.DB, fakeNow.Add(-15*time.Minute)))
+	// for "foo", the operation from 20 days ago is deleted; for "bar", only the one from 40 days ago
+	must.SucceedT(t, tasks.CollectGarbage(ctx, s.DB, s.Config, fakeNow))
 
-	// NOTE: `finished_operations` does not have a primary key, so this diff shows the full deleted records insteadj
+	// NOTE: `finished_operations` does not have a primary key, so this diff shows the full deleted records instead
 	tr.DBChanges().AssertEqualf(`
-			DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'high' AND outcome = 'cancelled' AND old_size = 1000 AND new_size = 1200 AND created_at = %[1]d AND confirmed_at = NULL AND greenlit_at = NULL AND finished_at = %[2]d AND greenlit_by_user_uuid = NULL AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":800}';
-			DELETE FROM finished_operations WHERE asset_id = 2 AND reason = 'high' AND outcome = 'cancelled' AND old_size = 1000 AND new_size = 1200 AND created_at = %[3]d AND confirmed_at = NULL AND greenlit_at = NULL AND finished_at = %[4]d AND greenlit_by_user_uuid = NULL AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":800}';
+			DELETE FROM finished_operations WHERE asset_id = 1 AND reason = 'high' AND outcome = 'cancelled' AND old_size = 1000 AND new_size = 1200 AND created_at = %[1]d AND confirmed_at = NULL AND greenlit_at = NULL AND finished_at = %[2]d AND greenlit_by_user_uuid = NULL AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":800}' AND cancelled_by_user_uuid = NULL;
+			DELETE FROM finished_operations WHERE asset_id = 3 AND reason = 'high' AND outcome = 'cancelled' AND old_size = 1000 AND new_size = 1200 AND created_at = %[3]d AND confirmed_at = NULL AND greenlit_at = NULL AND finished_at = %[4]d AND greenlit_by_user_uuid = NULL AND error_message = '' AND errored_attempts = 0 AND usage = '{"singular":800}' AND cancelled_by_user_uuid = NULL;
 		`,
 		ops[0].CreatedAt.Unix(),
 		ops[0].FinishedAt.Unix(),
-		ops[1].CreatedAt.Unix(),
-		ops[1].FinishedAt.Unix(),
+		ops[2].CreatedAt.Unix(),
+		ops[2].FinishedAt.Unix(),
+	)
+}
+
+func TestCollectUsageHistoryGarbage(t *testing.T) {
+	s := test.NewSetup(t,
+		commonSetupOptionsForWorkerTest(),
+		// usage history for "bar" is kept for 7 days; for "foo", only the samples
+		// required for predictive upscaling are kept
+		test.WithConfig(`{"usage_history_retention":[{"asset_type":"bar","days":7}]}`),
+	)
+	ctx := t.Context()
+	fakeNow := time.Unix(100*86400, 0).UTC()
+	day := 24 * time.Hour
+
+	must.SucceedT(t, db.ResourceStore.Insert(ctx, s.DB,
+		&db.Resource{ScopeUUID: "project1", AssetType: "foo"},
+		&db.Resource{ScopeUUID: "project1", AssetType: "bar"},
+	))
+	must.SucceedT(t, db.AssetStore.Insert(ctx, s.DB,
+		&db.Asset{ResourceID: 1, UUID: "asset1"},
+		&db.Asset{ResourceID: 2, UUID: "asset2"},
+	))
+
+	usage := castellum.UsageValues{castellum.SingularUsageMetric: 500}
+	must.SucceedT(t, db.AssetUsageSampleStore.Insert(ctx, s.DB,
+		&db.AssetUsageSample{AssetID: 1, RecordedAt: fakeNow.Add(-3 * time.Hour), Size: 1000, Usage: usage},
+		&db.AssetUsageSample{AssetID: 1, RecordedAt: fakeNow.Add(-1 * time.Hour), Size: 1000, Usage: usage},
+		&db.AssetUsageSample{AssetID: 2, RecordedAt: fakeNow.Add(-8 * day), Size: 1000, Usage: usage},
+		&db.AssetUsageSample{AssetID: 2, RecordedAt: fakeNow.Add(-6 * day), Size: 1000, Usage: usage},
+	))
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	must.SucceedT(t, tasks.CollectUsageHistoryGarbage(ctx, s.DB, s.Config, fakeNow))
+	tr.DBChanges().AssertEqualf(`
+			DELETE FROM asset_usage_samples WHERE asset_id = 1 AND recorded_at = %[1]d;
+			DELETE FROM asset_usage_samples WHERE asset_id = 2 AND recorded_at = %[2]d;
+		`,
+		fakeNow.Add(-3*time.Hour).Unix(),
+		fakeNow.Add(-8*day).Unix(),
 	)
 }
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-bits/jobloop"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/sqlext"
+	"go.xyrillian.de/gg/gsql"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+// WARNING: This must be run in a transaction, or else `FOR UPDATE SKIP LOCKED`
+// will not work as expected.
+var selectNextNotificationQuery = sqlext.SimplifyWhitespace(`
+	SELECT * FROM notifications WHERE next_delivery_at <= $1
+	ORDER BY next_delivery_at ASC LIMIT 1
+	-- prevent other job loops from working on the same notification concurrently
+	FOR UPDATE SKIP LOCKED
+`)
+
+const (
+	// MaxNotificationAttempts is the maximum number of delivery attempts per
+	// notification for NotificationDeliveryJob.
+	MaxNotificationAttempts = 10
+	// NotificationRetryInterval is the retry interval for NotificationDeliveryJob
+	// after the first failed delivery attempt. It doubles with each further
+	// failed attempt, up to MaxNotificationRetryInterval.
+	NotificationRetryInterval = 1 * time.Minute
+	// MaxNotificationRetryInterval is the upper bound for the exponential
+	// backoff in NotificationDeliveryJob.
+	MaxNotificationRetryInterval = 1 * time.Hour
+)
+
+// NotificationDeliveryJob returns a job where each task is a notification that
+// needs to be delivered by one of the configured notifiers.
+func (c *Context) NotificationDeliveryJob(registerer prometheus.Registerer) jobloop.Job {
+	return (&jobloop.TxGuardedJob[*gsql.Tx, db.Notification]{
+		Metadata: jobloop.JobMetadata{
+			ReadableName:    "notification delivery",
+			ConcurrencySafe: true, // because "FOR UPDATE SKIP LOCKED" is used
+			CounterOpts: prometheus.CounterOpts{
+				Name: "castellum_notification_deliveries",
+				Help: "Counter for notification delivery attempts.",
+			},
+			CounterLabels: []string{"notifier"},
+		},
+		BeginTx:     c.DB.Begin,
+		DiscoverRow: c.discoverNotification,
+		ProcessRow:  c.deliverNotification,
+	}).Setup(registerer)
+}
+
+func (c *Context) discoverNotification(ctx context.Context, tx *gsql.Tx, labels prometheus.Labels) (db.Notification, error) {
+	return db.NotificationStore.SelectOne(ctx, tx, selectNextNotificationQuery, c.TimeNow())
+}
+
+func (c *Context) deliverNotification(ctx context.Context, tx *gsql.Tx, n db.Notification, labels prometheus.Labels) error {
+	labels["notifier"] = n.NotifierType
+
+	// notifications for notifiers that have been removed from the configuration cannot be delivered anymore
+	notifier := c.Notifiers.ForPluginTypeID(n.NotifierType)
+	if notifier == nil {
+		logg.Error("discarding notification %d because notifier %q is not enabled", n.ID, n.NotifierType)
+		err := db.NotificationStore.Delete(ctx, tx, n)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	var payload core.NotificationPayload
+	err := json.Unmarshal([]byte(n.PayloadJSON), &payload)
+	if err == nil {
+		err = notifier.SendNotification(ctx, payload)
+	}
+	if err == nil {
+		err = db.NotificationStore.Delete(ctx, tx, n)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	// on failure, retry later with exponential backoff, unless we have exhausted our retry budget
+	deliveryErr := fmt.Errorf("cannot deliver notification %d via %s: %w", n.ID, n.NotifierType, err)
+	n.FailedAttempts++
+	if n.FailedAttempts >= MaxNotificationAttempts {
+		logg.Error("giving up on notification %d after %d failed attempts", n.ID, n.FailedAttempts)
+		err = db.NotificationStore.Delete(ctx, tx, n)
+	} else {
+		n.NextDeliveryAt = c.TimeNow().Add(notificationRetryIntervalAfter(n.FailedAttempts))
+		n.ErrorMessage = deliveryErr.Error()
+		err = db.NotificationStore.Update(ctx, tx, n)
+	}
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return deliveryErr
+}
+
+func notificationRetryIntervalAfter(failedAttempts uint32) time.Duration {
+	interval := NotificationRetryInterval
+	for range failedAttempts - 1 {
+		interval *= 2
+		if interval >= MaxNotificationRetryInterval {
+			return MaxNotificationRetryInterval
+		}
+	}
+	return interval
+}
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package tasks_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/castellum"
+	"github.com/sapcc/go-bits/easypg"
+	"github.com/sapcc/go-bits/must"
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+	"github.com/sapcc/castellum/internal/plugins"
+	"github.com/sapcc/castellum/internal/test"
+)
+
+func TestNotificationDelivery(t *testing.T) {
+	ctx := t.Context()
+	notifier := &plugins.NotifierStatic{
+		States: []castellum.OperationState{castellum.OperationStateSucceeded, castellum.OperationStateFailed},
+	}
+	s := test.NewSetup(t,
+		commonSetupOptionsForWorkerTest(),
+		test.WithNotifiers(notifier),
+	)
+	resizeJob := setupAssetResizeTest(t, s, 1)
+	deliveryJob := s.TaskContext.NotificationDeliveryJob(s.Registry)
+
+	// add a greenlit PendingOperation
+	s.Clock.StepBy(5 * time.Minute)
+	pendingOp := db.PendingOperation{
+		AssetID:     1,
+		Reason:      castellum.OperationReasonHigh,
+		OldSize:     1000,
+		NewSize:     1200,
+		Usage:       castellum.UsageValues{castellum.SingularUsageMetric: 500},
+		CreatedAt:   s.Clock.Now().Add(-5 * time.Minute),
+		ConfirmedAt: Some(s.Clock.Now()),
+		GreenlitAt:  Some(s.Clock.Now()),
+	}
+	must.SucceedT(t, db.PendingOperationStore.Insert(ctx, s.DB, &pendingOp))
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// when the operation finishes, a notification is enqueued in the same transaction
+	s.Clock.StepBy(time.Minute)
+	must.SucceedT(t, resizeJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			UPDATE assets SET expected_size = 1200, resized_at = %[3]d WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			INSERT INTO finished_operations (asset_id, reason, outcome, old_size, new_size, created_at, confirmed_at, greenlit_at, finished_at, usage) VALUES (1, 'high', 'succeeded', 1000, 1200, %[1]d, %[2]d, %[2]d, %[3]d, '{"singular":500}');
+			INSERT INTO notifications (id, notifier_type, payload_json, created_at, next_delivery_at) VALUES (1, 'static', '{"project_id":"project1","domain_id":"","asset_type":"foo","asset_id":"asset1","reason":"high","old_size":1000,"new_size":1200,"from_state":"greenlit","to_state":"succeeded","occurred_at":%[3]d}', %[3]d, %[3]d);
+			DELETE FROM pending_operations WHERE id = 1 AND asset_id = 1;
+		`,
+		s.Clock.Now().Add(-6*time.Minute).Unix(),
+		s.Clock.Now().Add(-1*time.Minute).Unix(),
+		s.Clock.Now().Unix(),
+	)
+
+	// the delivery job delivers the notification and removes it from the queue
+	must.SucceedT(t, deliveryJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqual(`DELETE FROM notifications WHERE id = 1;`)
+	assert.Equal(t, notifier.SentNotifications, []core.NotificationPayload{{
+		ProjectUUID: "project1",
+		AssetType:   "foo",
+		AssetUUID:   "asset1",
+		Reason:      castellum.OperationReasonHigh,
+		OldSize:     1000,
+		NewSize:     1200,
+		FromState:   castellum.OperationStateGreenlit,
+		ToState:     castellum.OperationStateSucceeded,
+		OccurredAt:  s.Clock.Now().Unix(),
+	}})
+
+	// nothing more to do
+	err := deliveryJob.ProcessOne(ctx)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %s instead", err.Error())
+	}
+	tr.DBChanges().AssertEmpty()
+}
+
+func TestNotificationDeliveryWithRetries(t *testing.T) {
+	ctx := t.Context()
+	notifier := &plugins.NotifierStatic{SendNotificationFails: true}
+	s := test.NewSetup(t,
+		commonSetupOptionsForWorkerTest(),
+		test.WithNotifiers(notifier),
+	)
+	deliveryJob := s.TaskContext.NotificationDeliveryJob(s.Registry)
+
+	createdAt := s.Clock.Now()
+	must.SucceedT(t, db.NotificationStore.Insert(ctx, s.DB, &db.Notification{
+		NotifierType:   "static",
+		PayloadJSON:    `{"project_id":"project1","domain_id":"domain1","asset_type":"foo","asset_id":"asset1","reason":"high","old_size":1000,"new_size":1200,"from_state":"greenlit","to_state":"failed","occurred_at":0}`,
+		CreatedAt:      createdAt,
+		NextDeliveryAt: createdAt,
+	}))
+	// this notification is for a notifier that is not enabled, so it will be discarded
+	must.SucceedT(t, db.NotificationStore.Insert(ctx, s.DB, &db.Notification{
+		NotifierType:   "webhook",
+		PayloadJSON:    `{}`,
+		CreatedAt:      createdAt,
+		NextDeliveryAt: createdAt.Add(time.Second),
+	}))
+
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// failed deliveries are retried with exponential backoff
+	s.Clock.StepBy(time.Minute)
+	err := deliveryJob.ProcessOne(ctx)
+	assert.ErrEqual(t, err, "cannot deliver notification 1 via static: SendNotification failing as requested")
+	tr.DBChanges().AssertEqualf(`
+			UPDATE notifications SET next_delivery_at = %d, failed_attempts = 1, error_message = 'cannot deliver notification 1 via static: SendNotification failing as requested' WHERE id = 1;
+		`,
+		s.Clock.Now().Add(1*time.Minute).Unix(),
+	)
+
+	must.SucceedT(t, deliveryJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqual(`DELETE FROM notifications WHERE id = 2;`)
+
+	s.Clock.StepBy(time.Minute)
+	err = deliveryJob.ProcessOne(ctx)
+	assert.ErrEqual(t, err, "cannot deliver notification 1 via static: SendNotification failing as requested")
+	tr.DBChanges().AssertEqualf(`
+			UPDATE notifications SET next_delivery_at = %d, failed_attempts = 2 WHERE id = 1;
+		`,
+		s.Clock.Now().Add(2*time.Minute).Unix(),
+	)
+
+	// once the notifier works again, the notification gets delivered
+	notifier.SendNotificationFails = false
+	s.Clock.StepBy(2 * time.Minute)
+	must.SucceedT(t, deliveryJob.ProcessOne(ctx))
+	tr.DBChanges().AssertEqual(`DELETE FROM notifications WHERE id = 1;`)
+	assert.Equal(t, len(notifier.SentNotifications), 1)
+	assert.Equal(t, notifier.SentNotifications[0].ToState, castellum.OperationStateFailed)
+}
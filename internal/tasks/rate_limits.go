@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package tasks
+
+import (
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-bits/sqlext"
+	"go.xyrillian.de/gg/gsql"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/castellum/internal/core"
+	"github.com/sapcc/castellum/internal/db"
+)
+
+var throttledResizesCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "castellum_throttled_asset_resizes",
+		Help: "Counter for pending resize operations that were held back by a rate limit.",
+	},
+	[]string{"asset_type", "limit"},
+)
+
+// WARNING: This must be run in a transaction, or else `FOR UPDATE SKIP LOCKED`
+// will not work as expected.
+var selectNextResizeCandidateQuery = sqlext.SimplifyWhitespace(`
+	SELECT po.id, r.scope_uuid, r.asset_type, a.backend_key
+	  FROM pending_operations po
+	  JOIN assets a ON a.id = po.asset_id
+	  JOIN resources r ON r.id = a.resource_id
+	 WHERE po.greenlit_at < $1 AND (po.retry_at IS NULL OR po.retry_at < $1) AND po.id != ALL($2)
+	 ORDER BY po.reason ASC LIMIT 1
+	 -- prevent other job loops from working on the same asset concurrently
+	 FOR UPDATE OF po SKIP LOCKED
+`)
+
+// Each resize in progress holds an advisory lock (key, slot) for each rate
+// limit that applies to it, so the number of resizes in progress can be
+// counted across all worker processes.
+var countResizesInProgressQuery = sqlext.SimplifyWhitespace(`
+	SELECT COUNT(*) FROM pg_locks WHERE locktype = 'advisory' AND classid = $1 AND objsubid = 2 AND granted
+`)
+
+var countRecentResizesQueryTemplate = sqlext.SimplifyWhitespace(`
+	SELECT r.asset_type, COUNT(*)
+	  FROM finished_operations o
+	  JOIN assets a ON a.id = o.asset_id
+	  JOIN resources r ON r.id = a.resource_id
+	 WHERE o.outcome IN ('succeeded', 'failed', 'errored') AND o.finished_at > $1 AND %s = $2
+	 GROUP BY r.asset_type
+`)
+
+var rateLimitKeyColumns = map[core.RateLimitScope]string{
+	core.RateLimitPerProject:   "r.scope_uuid",
+	core.RateLimitPerAssetType: "r.asset_type",
+	core.RateLimitPerBackend:   "a.backend_key",
+}
+
+// resizeCandidate is a pending operation that discoverAssetResize() considers
+// for execution, along with the attributes that rate limits can refer to.
+type resizeCandidate struct {
+	OperationID int64
+	ScopeUUID   string
+	AssetType   db.AssetType
+	BackendKey  string
+}
+
+func (c resizeCandidate) keyValueFor(scope core.RateLimitScope) string {
+	switch scope {
+	case core.RateLimitPerProject:
+		return c.ScopeUUID
+	case core.RateLimitPerAssetType:
+		return string(c.AssetType)
+	case core.RateLimitPerBackend:
+		return c.BackendKey
+	default:
+		return ""
+	}
+}
+
+// resizeThrottle enforces core.Config.ResizeRateLimits within a single
+// transaction of the asset resizing job.
+//
+// Advisory locks taken within the transaction cannot be released before the
+// transaction ends, so when a candidate is throttled, the locks it already
+// acquired for other limits remain held. This errs on the side of caution:
+// those locks are reused by later candidates in the same transaction.
+type resizeThrottle struct {
+	Limits []core.ResizeRateLimit
+	Now    time.Time
+	// lock keys for which this transaction holds a slot
+	heldKeys map[int32]bool
+	// lock keys whose limits are known to be exhausted
+	exhaustedKeys map[int32]bool
+}
+
+func newResizeThrottle(limits []core.ResizeRateLimit, now time.Time) *resizeThrottle {
+	return &resizeThrottle{
+		Limits:        limits,
+		Now:           now,
+		heldKeys:      make(map[int32]bool),
+		exhaustedKeys: make(map[int32]bool),
+	}
+}
+
+// Check returns which kind of limit (if any) prevents the given candidate from
+// being executed right now.
+func (t *resizeThrottle) Check(tx *gsql.Tx, candidate resizeCandidate) (Option[core.RateLimitScope], error) {
+	for idx, limit := range t.Limits {
+		if !limit.AssetTypeRx.MatchString(string(candidate.AssetType)) {
+			continue
+		}
+		value := candidate.keyValueFor(limit.Per)
+		if value == "" {
+			continue
+		}
+		key := rateLimitLockKey(idx, value)
+		if t.exhaustedKeys[key] {
+			return Some(limit.Per), nil
+		}
+
+		if !t.heldKeys[key] {
+			ok, err := t.acquireSlot(tx, limit, key, candidate)
+			if err != nil {
+				return None[core.RateLimitScope](), err
+			}
+			if !ok {
+				t.exhaustedKeys[key] = true
+				return Some(limit.Per), nil
+			}
+			t.heldKeys[key] = true
+		}
+
+		if limit.MaxPerHour > 0 {
+			count, err := t.countResizesInLastHour(tx, limit, key, value)
+			if err != nil {
+				return None[core.RateLimitScope](), err
+			}
+			// NOTE: `count` includes the candidate itself since we hold a slot for it
+			if count > uint64(limit.MaxPerHour) {
+				t.exhaustedKeys[key] = true
+				return Some(limit.Per), nil
+			}
+		}
+	}
+	return None[core.RateLimitScope](), nil
+}
+
+func (t *resizeThrottle) acquireSlot(tx *gsql.Tx, limit core.ResizeRateLimit, key int32, candidate resizeCandidate) (bool, error) {
+	if limit.MaxConcurrent == 0 {
+		// no concurrency limit, but we still need to take a lock such that our
+		// resize is visible to countResizesInProgressQuery
+		_, err := tx.Exec(`SELECT pg_advisory_xact_lock($1, $2)`, key, int32(candidate.OperationID))
+		return err == nil, err
+	}
+
+	for slot := range int32(limit.MaxConcurrent) {
+		var ok bool
+		err := tx.QueryRow(`SELECT pg_try_advisory_xact_lock($1, $2)`, key, slot).Scan(&ok)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (t *resizeThrottle) countResizesInLastHour(tx *gsql.Tx, limit core.ResizeRateLimit, key int32, value string) (uint64, error) {
+	var result uint64
+	err := tx.QueryRow(countResizesInProgressQuery, key).Scan(&result)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf(countRecentResizesQueryTemplate, rateLimitKeyColumns[limit.Per])
+	err = sqlext.ForeachRow(tx, query, []any{t.Now.Add(-time.Hour), value}, func(rows *sql.Rows) error {
+		var (
+			assetType db.AssetType
+			count     uint64
+		)
+		err := rows.Scan(&assetType, &count)
+		if err == nil && limit.AssetTypeRx.MatchString(string(assetType)) {
+			result += count
+		}
+		return err
+	})
+	return result, err
+}
+
+// Computes the first key of the advisory locks that represent resizes
+// counting against the rate limit with the given index in the config.
+// The result is non-negative since pg_locks reports the key as an OID.
+func rateLimitLockKey(limitIdx int, value string) int32 {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "castellum-resize-rate-limit:%d:%s", limitIdx, value)
+	return int32(h.Sum32() & 0x7FFFFFFF)
+}
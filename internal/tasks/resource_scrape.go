@@ -5,6 +5,7 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,6 +14,7 @@ import (
 	"github.com/sapcc/go-bits/sqlext"
 	"go.xyrillian.de/gg/gsql"
 
+	"github.com/sapcc/castellum/internal/core"
 	"github.com/sapcc/castellum/internal/db"
 )
 
@@ -57,7 +59,7 @@ func (c *Context) discoverResourceScrape(ctx context.Context, tx *gsql.Tx, label
 }
 
 func (c *Context) processResourceScrape(ctx context.Context, tx *gsql.Tx, res db.Resource, labels prometheus.Labels) error {
-	manager, info := c.Team.ForAssetType(res.AssetType)
+	manager, info := c.Team.ForResource(res)
 	if manager == nil {
 		return fmt.Errorf("no asset manager for asset type %q", res.AssetType)
 	}
@@ -65,7 +67,7 @@ func (c *Context) processResourceScrape(ctx context.Context, tx *gsql.Tx, res db
 
 	// check which assets exist in this resource in OpenStack
 	startedAt := c.TimeNow()
-	assetUUIDs, err := manager.ListAssets(ctx, res)
+	assets, exclusions, err := listAssets(ctx, manager, res)
 	finishedAt := c.TimeNow()
 	if err != nil {
 		// In case of error we update next_scrape_at so that the next call continues
@@ -82,10 +84,10 @@ func (c *Context) processResourceScrape(ctx context.Context, tx *gsql.Tx, res db
 		}
 		return fmt.Errorf("cannot list %s assets in scope %s: %s", string(res.AssetType), res.ScopeUUID, err.Error())
 	}
-	logg.Debug("scraped %d assets for %s resource for scope %s", len(assetUUIDs), res.AssetType, res.ScopeUUID)
-	isExistingAsset := make(map[string]bool, len(assetUUIDs))
-	for _, uuid := range assetUUIDs {
-		isExistingAsset[uuid] = true
+	logg.Debug("scraped %d assets for %s resource for scope %s", len(assets), res.AssetType, res.ScopeUUID)
+	exclusionReasons := make(map[string]string, len(assets))
+	for _, asset := range assets {
+		exclusionReasons[asset.UUID] = exclusions.ExclusionReason(asset)
 	}
 
 	// load existing asset entries from DB
@@ -98,7 +100,17 @@ func (c *Context) processResourceScrape(ctx context.Context, tx *gsql.Tx, res db
 	isAssetInDB := make(map[string]bool)
 	for _, dbAsset := range dbAssets {
 		isAssetInDB[dbAsset.UUID] = true
-		if isExistingAsset[dbAsset.UUID] {
+		exclusionReason, exists := exclusionReasons[dbAsset.UUID]
+		if exists {
+			if dbAsset.ExclusionReason != exclusionReason {
+				logg.Info("updating exclusion of %s asset %s in scope %s: %q -> %q",
+					res.AssetType, dbAsset.UUID, res.ScopeUUID, dbAsset.ExclusionReason, exclusionReason)
+				dbAsset.ExclusionReason = exclusionReason
+				err := db.AssetStore.Update(ctx, tx, dbAsset)
+				if err != nil {
+					return err
+				}
+			}
 			continue
 		}
 		logg.Info("removing deleted %s asset from DB: UUID = %s, scope UUID = %s", res.AssetType, dbAsset.UUID, res.ScopeUUID)
@@ -109,18 +121,19 @@ func (c *Context) processResourceScrape(ctx context.Context, tx *gsql.Tx, res db
 	}
 
 	// create entries for new assets
-	for _, assetUUID := range assetUUIDs {
-		if isAssetInDB[assetUUID] {
+	for _, asset := range assets {
+		if isAssetInDB[asset.UUID] {
 			continue
 		}
-		logg.Info("adding new %s asset to DB: UUID = %s, scope UUID = %s", res.AssetType, assetUUID, res.ScopeUUID)
+		logg.Info("adding new %s asset to DB: UUID = %s, scope UUID = %s", res.AssetType, asset.UUID, res.ScopeUUID)
 		err := db.AssetStore.Insert(ctx, tx, &db.Asset{
-			ResourceID:   res.ID,
-			UUID:         assetUUID,
-			Size:         0,
-			Usage:        info.MakeZeroUsageValues(),
-			NextScrapeAt: c.TimeNow(),
-			NeverScraped: true,
+			ResourceID:      res.ID,
+			UUID:            asset.UUID,
+			Size:            0,
+			Usage:           info.MakeZeroUsageValues(),
+			NextScrapeAt:    c.TimeNow(),
+			NeverScraped:    true,
+			ExclusionReason: exclusionReasons[asset.UUID],
 		})
 		if err != nil {
 			return err
@@ -138,3 +151,33 @@ func (c *Context) processResourceScrape(ctx context.Context, tx *gsql.Tx, res db
 
 	return tx.Commit()
 }
+
+// listAssets lists the assets of the given resource in the backend. If the
+// resource excludes assets by name or metadata, their descriptions are
+// obtained from the asset manager, too.
+func listAssets(ctx context.Context, manager core.AssetManager, res db.Resource) ([]core.AssetDescription, core.AssetExclusions, error) {
+	exclusionsOpt, err := core.AssetExclusionsOf(res)
+	if err != nil {
+		return nil, core.AssetExclusions{}, err
+	}
+	exclusions := exclusionsOpt.UnwrapOr(core.AssetExclusions{})
+
+	if exclusions.NeedsDescriptions() {
+		describer, ok := manager.(core.AssetDescriber)
+		if !ok {
+			return nil, exclusions, errors.New("cannot evaluate exclusions by name or metadata: not supported for this asset type")
+		}
+		assets, err := describer.DescribeAssets(ctx, res)
+		return assets, exclusions, err
+	}
+
+	assetUUIDs, err := manager.ListAssets(ctx, res)
+	if err != nil {
+		return nil, exclusions, err
+	}
+	assets := make([]core.AssetDescription, len(assetUUIDs))
+	for idx, uuid := range assetUUIDs {
+		assets[idx] = core.AssetDescription{UUID: uuid}
+	}
+	return assets, exclusions, nil
+}
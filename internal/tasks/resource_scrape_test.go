@@ -139,3 +139,61 @@ func TestResourceScraping(t *testing.T) {
 		s.Clock.Now().Add(30*time.Minute).Unix(),
 	)
 }
+
+func TestResourceScrapingWithExclusions(t *testing.T) {
+	ctx := t.Context()
+	s := test.NewSetup(t,
+		commonSetupOptionsForWorkerTest(),
+	)
+	job := s.TaskContext.ResourceScrapingJob(s.Registry)
+
+	must.SucceedT(t, db.ResourceStore.Insert(ctx, s.DB, &db.Resource{
+		ScopeUUID:                "project1",
+		DomainUUID:               "domain1",
+		AssetType:                "foo",
+		LowThresholdPercent:      castellum.UsageValues{castellum.SingularUsageMetric: 0},
+		HighThresholdPercent:     castellum.UsageValues{castellum.SingularUsageMetric: 0},
+		CriticalThresholdPercent: castellum.UsageValues{castellum.SingularUsageMetric: 0},
+		ExclusionsJSON:           `{"asset_ids":["asset1"],"metadata":{"castellum":"off"}}`,
+		NextScrapeAt:             s.Clock.Now(),
+	}))
+	amStatic := s.ManagerForAssetType("foo")
+	amStatic.Assets = map[string]map[string]plugins.StaticAsset{
+		"project1": {
+			"asset1": {Size: 1000, Usage: 400},
+			"asset2": {Size: 2000, Usage: 1000, Metadata: map[string]string{"castellum": "off"}},
+			"asset3": {Size: 3000, Usage: 1000, Metadata: map[string]string{"castellum": "on"}},
+		},
+	}
+	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
+	tr0.Ignore()
+
+	// new assets are marked as excluded if they match the exclusion rules
+	s.Clock.StepBy(time.Hour)
+	must.SucceedT(t, job.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			INSERT INTO assets (id, resource_id, uuid, size, usage, next_scrape_at, never_scraped, exclusion_reason) VALUES (1, 1, 'asset1', 0, '{"singular":0}', %[1]d, TRUE, 'excluded by asset ID');
+			INSERT INTO assets (id, resource_id, uuid, size, usage, next_scrape_at, never_scraped, exclusion_reason) VALUES (2, 1, 'asset2', 0, '{"singular":0}', %[1]d, TRUE, 'excluded because metadata "castellum" matches "off"');
+			INSERT INTO assets (id, resource_id, uuid, size, usage, next_scrape_at, never_scraped) VALUES (3, 1, 'asset3', 0, '{"singular":0}', %[1]d, TRUE);
+			UPDATE resources SET next_scrape_at = %[2]d WHERE id = 1 AND scope_uuid = 'project1' AND asset_type = 'foo';
+		`,
+		s.Clock.Now().Unix(),
+		s.Clock.Now().Add(30*time.Minute).Unix(),
+	)
+
+	// existing assets are updated when the backend or the exclusion rules change
+	amStatic.Assets["project1"]["asset2"] = plugins.StaticAsset{Size: 2000, Usage: 1000}
+	amStatic.Assets["project1"]["asset3"] = plugins.StaticAsset{Size: 3000, Usage: 1000, Name: "important"}
+	must.SucceedT(t, s.DBExec(`UPDATE resources SET exclusions_json = $1 WHERE id = 1`, `{"names":["imp.*"]}`))
+	tr.DBChanges().Ignore()
+	s.Clock.StepBy(time.Hour)
+	must.SucceedT(t, job.ProcessOne(ctx))
+	tr.DBChanges().AssertEqualf(`
+			UPDATE assets SET exclusion_reason = '' WHERE id = 1 AND resource_id = 1 AND uuid = 'asset1';
+			UPDATE assets SET exclusion_reason = '' WHERE id = 2 AND resource_id = 1 AND uuid = 'asset2';
+			UPDATE assets SET exclusion_reason = 'excluded because name matches "imp.*"' WHERE id = 3 AND resource_id = 1 AND uuid = 'asset3';
+			UPDATE resources SET next_scrape_at = %[1]d WHERE id = 1 AND scope_uuid = 'project1' AND asset_type = 'foo';
+		`,
+		s.Clock.Now().Add(30*time.Minute).Unix(),
+	)
+}
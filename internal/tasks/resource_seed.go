@@ -22,10 +22,10 @@ import (
 // ResourceSeedingJob applies the resource seed from the Config every few minutes.
 //
 // Since the seed is static for the duration of the program's runtime, it looks
-// like it should only be necessary once to do at startup. But project seeds
-// only apply if the project in question exists. Hence, we check back every few
-// minutes to see if a project which we are interested in and which was missing
-// before has now shown up.
+// like it should only be necessary once to do at startup. But project and
+// domain seeds only apply if the project or domain in question exists. Hence,
+// we check back every few minutes to see if a project or domain which we are
+// interested in and which was missing before has now shown up.
 func (c *Context) ResourceSeedingJob(registerer prometheus.Registerer) jobloop.Job {
 	return (&jobloop.CronJob{
 		Metadata: jobloop.JobMetadata{
@@ -43,8 +43,11 @@ func (c *Context) ResourceSeedingJob(registerer prometheus.Registerer) jobloop.J
 }
 
 func (c *Context) applyResourceSeeds(ctx context.Context) error {
+	// use the same config for all seeds, even if the config is reloaded concurrently
+	cfg := c.Config.Current()
+
 	var missingProjects []string
-	for _, seed := range c.Config.ProjectSeeds {
+	for _, seed := range cfg.ProjectSeeds {
 		projectUUID, err := c.ProviderClient.FindProjectID(ctx, seed.ProjectName, seed.DomainName)
 		if err != nil {
 			return fmt.Errorf(`cannot find project "%s/%s": %w`, seed.DomainName, seed.ProjectName, err)
@@ -54,25 +57,69 @@ func (c *Context) applyResourceSeeds(ctx context.Context) error {
 			missingProjects = append(missingProjects, fmt.Sprintf(`"%s/%s"`, seed.DomainName, seed.ProjectName))
 			continue
 		}
+		proj, err := c.ProviderClient.GetProject(ctx, projectUUID)
+		if err != nil {
+			return err
+		}
 
-		err = c.applyProjectSeed(ctx, projectUUID, seed)
+		scope := seedScope{
+			UUID:        projectUUID,
+			DomainUUID:  proj.DomainID,
+			Description: fmt.Sprintf("project %s/%s", seed.DomainName, seed.ProjectName),
+		}
+		err = c.applySeed(ctx, cfg, scope, seed.ResourceSeed)
 		if err != nil {
 			return fmt.Errorf(`while applying seed for project "%s/%s" (%s): %w`, seed.DomainName, seed.ProjectName, projectUUID, err)
 		}
 	}
 
+	var missingDomains []string
+	for _, seed := range cfg.DomainSeeds {
+		domainUUID, err := c.ProviderClient.FindDomainID(ctx, seed.DomainName)
+		if err != nil {
+			return fmt.Errorf(`cannot find domain "%s": %w`, seed.DomainName, err)
+		}
+		if domainUUID == "" {
+			// domain does not exist in Keystone -> skip this domain seed this time
+			missingDomains = append(missingDomains, fmt.Sprintf(`"%s"`, seed.DomainName))
+			continue
+		}
+
+		scope := seedScope{
+			UUID:        domainUUID,
+			DomainUUID:  domainUUID,
+			Description: "domain " + seed.DomainName,
+		}
+		err = c.applySeed(ctx, cfg, scope, seed.ResourceSeed)
+		if err != nil {
+			return fmt.Errorf(`while applying seed for domain "%s" (%s): %w`, seed.DomainName, domainUUID, err)
+		}
+	}
+
 	if len(missingProjects) > 0 {
 		sort.Strings(missingProjects)
 		logg.Info("while applying the resource seed: %d projects were skipped because they do not exist in Keystone: %s",
 			len(missingProjects), strings.Join(missingProjects, ", "))
 	}
+	if len(missingDomains) > 0 {
+		sort.Strings(missingDomains)
+		logg.Info("while applying the resource seed: %d domains were skipped because they do not exist in Keystone: %s",
+			len(missingDomains), strings.Join(missingDomains, ", "))
+	}
 
 	return nil
 }
 
-func (c *Context) applyProjectSeed(ctx context.Context, projectUUID string, seed core.ProjectSeed) error {
+// seedScope identifies the project or domain that a seed is applied to.
+type seedScope struct {
+	UUID        string
+	DomainUUID  string // for domains: equal to .UUID
+	Description string // for log messages, e.g. "project mydomain/myproject"
+}
+
+func (c *Context) applySeed(ctx context.Context, cfg core.Config, scope seedScope, seed core.ResourceSeed) error {
 	// list existing resources
-	dbResources, err := db.ResourceStore.SelectWhere(ctx, c.DB, `scope_uuid = $1`, projectUUID).Collect()
+	dbResources, err := db.ResourceStore.SelectWhere(ctx, c.DB, `scope_uuid = $1`, scope.UUID).Collect()
 	if err != nil {
 		return err
 	}
@@ -87,12 +134,12 @@ func (c *Context) applyProjectSeed(ctx context.Context, projectUUID string, seed
 		if exists {
 			// apply positive seed
 			dbResourceCopy := dbResource
-			errs := core.ApplyResourceSpecInto(ctx, &dbResourceCopy, resource, isExistingResource, c.Config, c.Team)
+			errs := core.ApplyResourceSpecInto(ctx, &dbResourceCopy, resource, isExistingResource, cfg, c.Team)
 			if !errs.IsEmpty() {
 				return fmt.Errorf("cannot apply %s seed: %s", dbResource.AssetType, errs.Join(", "))
 			}
 			if !reflect.DeepEqual(dbResource, dbResourceCopy) {
-				logg.Info("applying %s seed for project %s/%s...", dbResource.AssetType, seed.DomainName, seed.ProjectName)
+				logg.Info("applying %s seed for %s...", dbResource.AssetType, scope.Description)
 				err := db.ResourceStore.Update(ctx, c.DB, dbResourceCopy)
 				if err != nil {
 					return err
@@ -100,7 +147,7 @@ func (c *Context) applyProjectSeed(ctx context.Context, projectUUID string, seed
 			}
 		} else if seed.ForbidsResource(dbResource.AssetType) {
 			// enforce negative seed
-			logg.Info("enforcing negative %s seed for project %s/%s...", dbResource.AssetType, seed.DomainName, seed.ProjectName)
+			logg.Info("enforcing negative %s seed for %s...", dbResource.AssetType, scope.Description)
 			err := db.ResourceStore.Delete(ctx, c.DB, dbResource)
 			if err != nil {
 				return err
@@ -116,21 +163,17 @@ func (c *Context) applyProjectSeed(ctx context.Context, projectUUID string, seed
 			continue
 		}
 
-		proj, err := c.ProviderClient.GetProject(ctx, projectUUID)
-		if err != nil {
-			return err
-		}
 		dbResource := db.Resource{
-			ScopeUUID:    projectUUID,
-			DomainUUID:   proj.DomainID,
+			ScopeUUID:    scope.UUID,
+			DomainUUID:   scope.DomainUUID,
 			AssetType:    assetType,
 			NextScrapeAt: time.Unix(0, 0).UTC(), // give new resources a very early next_scrape_at to prioritize them in the scrape queue
 		}
-		errs := core.ApplyResourceSpecInto(ctx, &dbResource, resource, isExistingResource, c.Config, c.Team)
+		errs := core.ApplyResourceSpecInto(ctx, &dbResource, resource, isExistingResource, cfg, c.Team)
 		if !errs.IsEmpty() {
 			return fmt.Errorf("cannot apply %s seed: %s", dbResource.AssetType, errs.Join(", "))
 		}
-		logg.Info("applying %s seed for project %s/%s...", dbResource.AssetType, seed.DomainName, seed.ProjectName)
+		logg.Info("applying %s seed for %s...", dbResource.AssetType, scope.Description)
 		err = db.ResourceStore.Insert(ctx, c.DB, &dbResource)
 		if err != nil {
 			return err
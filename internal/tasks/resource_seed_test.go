@@ -57,6 +57,29 @@ const resourceSeedingConfigGood = `{
 				}
 			}
 		}
+	],
+	"domain_seeds": [
+		// This domain exists, so this positive seed will be applied.
+		{
+			"domain_name": "First Domain",
+			"resources": {
+				"foo": {
+					"critical_threshold": {
+						"usage_percent": {
+							"singular": 90
+						}
+					},
+					"size_steps": {
+						"single": true
+					}
+				}
+			}
+		},
+		// This domain does not exist, so this seed will be skipped.
+		{
+			"domain_name": "Unknown Domain",
+			"disabled_resources": ["fo*"]
+		}
 	]
 }`
 
@@ -91,11 +114,12 @@ func TestResourceSeedingSuccess(t *testing.T) {
 	tr, tr0 := easypg.NewTracker(t, s.DB.DB)
 	tr0.Ignore()
 
-	// test that seeding job applies the seeds (except for the one project that the MockProviderClient reports as nonexistent)
+	// test that seeding job applies the seeds (except for the one project and the one domain that the MockProviderClient reports as nonexistent)
 	must.SucceedT(t, job.ProcessOne(ctx))
 	tr.DBChanges().AssertEqualf(`
 		DELETE FROM resources WHERE id = 2 AND scope_uuid = 'project2' AND asset_type = 'foo';
 		INSERT INTO resources (id, scope_uuid, asset_type, low_threshold_percent, low_delay_seconds, high_threshold_percent, high_delay_seconds, critical_threshold_percent, size_step_percent, domain_uuid, next_scrape_at) VALUES (3, 'project1', 'foo', '{"singular":0}', 0, '{"singular":0}', 0, '{"singular":95}', 20, 'domain1', 0);
+		INSERT INTO resources (id, scope_uuid, asset_type, low_threshold_percent, low_delay_seconds, high_threshold_percent, high_delay_seconds, critical_threshold_percent, size_step_percent, single_step, domain_uuid, next_scrape_at) VALUES (4, 'domain1', 'foo', '{"singular":0}', 0, '{"singular":0}', 0, '{"singular":90}', 0, TRUE, 'domain1', 0);
 	`)
 
 	// test that the next seeding run does not change anything
@@ -64,6 +64,11 @@ func (c MockProviderClient) FindProjectID(_ context.Context, projectName, projec
 	return "", nil // no such project
 }
 
+// FindDomainID implements the core.ProviderClient interface.
+func (c MockProviderClient) FindDomainID(_ context.Context, domainName string) (string, error) {
+	return c.findDomainID(domainName), nil
+}
+
 func (c MockProviderClient) findDomainID(domainName string) string {
 	for domainID, domain := range c.Domains {
 		if domain.Name == domainName {
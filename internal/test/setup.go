@@ -30,6 +30,7 @@ import (
 
 type setupParams struct {
 	AssetManagers []core.AssetManager
+	Notifiers     []core.Notifier
 	ConfigJSON    string
 }
 
@@ -43,6 +44,13 @@ func WithAssetManagers(managers ...core.AssetManager) SetupOption {
 	}
 }
 
+// WithNotifiers is a SetupOption that adds notifiers to the team.
+func WithNotifiers(notifiers ...core.Notifier) SetupOption {
+	return func(params *setupParams) {
+		params.Notifiers = append(params.Notifiers, notifiers...)
+	}
+}
+
 // WithConfig is a SetupOption that initializes core.Config by unmarshaling the provided JSON payload.
 func WithConfig(configJSON string) SetupOption {
 	return func(params *setupParams) {
@@ -58,6 +66,7 @@ type Setup struct {
 	DB             *gsql.DB
 	ProviderClient MockProviderClient
 	Team           core.AssetManagerTeam
+	Notifiers      core.NotifierTeam
 
 	// for API tests only
 	Auditor   *audittools.MockAuditor
@@ -94,6 +103,7 @@ func NewSetup(t *testing.T, opts ...SetupOption) Setup {
 			},
 		},
 		Team:        core.AssetManagerTeam(params.AssetManagers),
+		Notifiers:   core.NotifierTeam(params.Notifiers),
 		Auditor:     audittools.NewMockAuditor(),
 		Handler:     httptest.Handler{}, // see below
 		Validator:   mock.NewValidator(mock.NewEnforcer(), nil),
@@ -121,15 +131,16 @@ func NewSetup(t *testing.T, opts ...SetupOption) Setup {
 
 	// initialize HTTP handler for API tests
 	s.Handler = httptest.NewHandler(httpapi.Compose(
-		api.NewHandler(s.Config, s.DB, s.Team, s.Validator, s.ProviderClient, s.Auditor, s.Clock.Now),
+		api.NewHandler(core.StaticConfigSource(s.Config), s.DB, s.Team, s.Notifiers, s.Validator, s.ProviderClient, s.Auditor, s.Clock.Now),
 		httpapi.WithoutLogging(),
 	))
 
 	// initialize context for worker tests
 	s.TaskContext = &tasks.Context{
-		Config:         s.Config,
+		Config:         core.StaticConfigSource(s.Config),
 		DB:             s.DB,
 		Team:           s.Team,
+		Notifiers:      s.Notifiers,
 		ProviderClient: s.ProviderClient,
 		TimeNow:        s.Clock.Now,
 		AddJitter:      jobloop.NoJitter,
@@ -6,6 +6,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	_ "time/tzdata" // for maintenance windows (our container image does not contain a timezone database)
 
 	"github.com/dlmiddlecote/sqlstats"
 	"github.com/gophercloud/gophercloud/v2/openstack"
@@ -35,18 +37,25 @@ import (
 	"go.xyrillian.de/gg/pgruntime"
 
 	"github.com/sapcc/castellum/internal/api"
+	"github.com/sapcc/castellum/internal/checkconfig"
 	"github.com/sapcc/castellum/internal/core"
 	"github.com/sapcc/castellum/internal/db"
+	"github.com/sapcc/castellum/internal/export"
+	"github.com/sapcc/castellum/internal/replay"
 	"github.com/sapcc/castellum/internal/tasks"
 
 	// load asset managers
 	_ "github.com/sapcc/castellum/internal/plugins"
 )
 
+// How often api, observer and worker check whether the configuration file has
+// changed. (Reloads can also be triggered immediately with SIGHUP.)
+const configWatchInterval = 30 * time.Second
+
 func usage() {
 	fmt.Fprintf(os.Stderr,
-		"usage:\n\t%s [api|observer|worker] <config-file>\n\t%s test-asset-type <config-file> <type> [<resource-config-json>]\n",
-		os.Args[0], os.Args[0],
+		"usage:\n\t%s [api|observer|worker] <config-file>\n\t%s test-asset-type <config-file> <type> [<resource-config-json>]\n\t%s replay <config-file> <resource-config-json> <usage-timeseries-file>\n\t%s export-history <config-file> <start-date> <end-date> [csv|json]\n\t%s check-config <config-file> [--local|--require-seeded-scopes]\n",
+		os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0],
 	)
 	os.Exit(1)
 }
@@ -61,6 +70,49 @@ func main() {
 
 	logg.ShowDebug = osext.GetenvBool("CASTELLUM_DEBUG")
 
+	// this task works entirely offline, so it does not need any of the initialization below
+	if taskName == "replay" {
+		if len(os.Args) != 5 {
+			usage()
+		}
+		runReplay(context.Background(), must.Return(core.LoadConfig(configPath)), os.Args[3], os.Args[4])
+		return
+	}
+
+	// this task only needs the database, but not OpenStack
+	if taskName == "export-history" {
+		if len(os.Args) != 5 && len(os.Args) != 6 {
+			usage()
+		}
+		format := export.FormatCSV
+		if len(os.Args) == 6 {
+			format = export.Format(os.Args[5])
+		}
+		ctx := context.Background()
+		runExportHistory(must.Return(core.LoadConfig(configPath)), initDB(ctx), os.Args[3], os.Args[4], format)
+		return
+	}
+
+	// in local mode, this task works entirely offline
+	checkConfigMode := ""
+	if taskName == "check-config" {
+		if len(os.Args) > 4 {
+			usage()
+		}
+		if len(os.Args) == 4 {
+			checkConfigMode = os.Args[3]
+		}
+		switch checkConfigMode {
+		case "--local":
+			runCheckConfig(context.Background(), configPath, checkconfig.Options{})
+			return
+		case "", "--require-seeded-scopes":
+			// continue below
+		default:
+			usage()
+		}
+	}
+
 	wrap := httpext.WrapTransport(&http.DefaultTransport)
 	wrap.SetInsecureSkipVerify(osext.GetenvBool("CASTELLUM_INSECURE")) // for debugging with mitmproxy etc. (DO NOT SET IN PRODUCTION)
 	wrap.SetOverrideUserAgent(bininfo.Component(), bininfo.VersionOr("rolling"))
@@ -69,8 +121,8 @@ func main() {
 	ctx := httpext.ContextWithSIGINT(context.Background(), 10*time.Second)
 	providerClient := must.Return(core.NewProviderClient(ctx))
 
-	// get max asset sizes
-	cfg := must.Return(core.LoadConfig(configPath))
+	// load configuration (api, observer and worker reload it when it changes)
+	cfg := must.Return(core.NewConfigSource(configPath))
 
 	// initialize asset managers
 	team := must.Return(core.CreateAssetManagers(
@@ -79,23 +131,36 @@ func main() {
 		providerClient,
 	))
 
+	// initialize notifiers (if any)
+	var notifierTypeIDs []string
+	if value := os.Getenv("CASTELLUM_NOTIFIERS"); value != "" {
+		notifierTypeIDs = strings.Split(value, ",")
+	}
+	notifiers := must.Return(core.CreateNotifiers(ctx, notifierTypeIDs, providerClient))
+
 	httpListenAddr := osext.GetenvOrDefault("CASTELLUM_HTTP_LISTEN_ADDRESS", ":8080")
 	switch taskName {
 	case "api":
 		if len(os.Args) != 3 {
 			usage()
 		}
-		runAPI(ctx, cfg, initDB(ctx), team, providerClient, httpListenAddr)
+		runAPI(ctx, cfg, initDB(ctx), team, notifiers, providerClient, httpListenAddr)
 	case "observer":
 		if len(os.Args) != 3 {
 			usage()
 		}
-		runObserver(ctx, cfg, initDB(ctx), team, providerClient, httpListenAddr)
+		runObserver(ctx, cfg, initDB(ctx), team, notifiers, providerClient, httpListenAddr)
 	case "worker":
 		if len(os.Args) != 3 {
 			usage()
 		}
-		runWorker(ctx, initDB(ctx), team, httpListenAddr)
+		runWorker(ctx, cfg, initDB(ctx), team, notifiers, httpListenAddr)
+	case "check-config":
+		runCheckConfig(ctx, configPath, checkconfig.Options{
+			Team:                team,
+			ProviderClient:      providerClient,
+			RequireSeededScopes: checkConfigMode == "--require-seeded-scopes",
+		})
 	case "test-asset-type":
 		if len(os.Args) != 4 && len(os.Args) != 5 {
 			usage()
@@ -113,7 +178,9 @@ func main() {
 ////////////////////////////////////////////////////////////////////////////////
 // task: API
 
-func runAPI(ctx context.Context, cfg core.Config, dbi *gsql.DB, team core.AssetManagerTeam, providerClient core.ProviderClient, httpListenAddr string) {
+func runAPI(ctx context.Context, cfg *core.ConfigSource, dbi *gsql.DB, team core.AssetManagerTeam, notifiers core.NotifierTeam, providerClient core.ProviderClient, httpListenAddr string) {
+	go cfg.WatchForChanges(ctx, configWatchInterval)
+
 	identityV3, err := providerClient.CloudAdminClient(openstack.NewIdentityV3)
 	if err != nil {
 		logg.Fatal("cannot find Keystone V3 API: " + err.Error())
@@ -144,7 +211,7 @@ func runAPI(ctx context.Context, cfg core.Config, dbi *gsql.DB, team core.AssetM
 		AllowedHeaders: []string{"Content-Type", "User-Agent", "X-Auth-Token"},
 	})
 	handler := httpapi.Compose(
-		api.NewHandler(cfg, dbi, team, &tv, providerClient, auditor, time.Now),
+		api.NewHandler(cfg, dbi, team, notifiers, &tv, providerClient, auditor, time.Now),
 		httpapi.HealthCheckAPI{
 			SkipRequestLog: true,
 			Check: func() error {
@@ -184,8 +251,9 @@ func initDB(ctx context.Context) *gsql.DB {
 ////////////////////////////////////////////////////////////////////////////////
 // task: observer
 
-func runObserver(ctx context.Context, cfg core.Config, dbi *gsql.DB, team core.AssetManagerTeam, providerClient core.ProviderClient, httpListenAddr string) {
-	c := tasks.Context{Config: cfg, DB: dbi, Team: team, ProviderClient: providerClient}
+func runObserver(ctx context.Context, cfg *core.ConfigSource, dbi *gsql.DB, team core.AssetManagerTeam, notifiers core.NotifierTeam, providerClient core.ProviderClient, httpListenAddr string) {
+	go cfg.WatchForChanges(ctx, configWatchInterval)
+	c := tasks.Context{Config: cfg, DB: dbi, Team: team, Notifiers: notifiers, ProviderClient: providerClient}
 	c.ApplyDefaults()
 	prometheus.MustRegister(tasks.StateMetricsCollector{Context: c})
 
@@ -196,6 +264,7 @@ func runObserver(ctx context.Context, cfg core.Config, dbi *gsql.DB, team core.A
 	go c.ResourceScrapingJob(nil).Run(ctx, jobloop.NumGoroutines(3))
 	go c.ResourceSeedingJob(nil).Run(ctx)
 	go c.GarbageCollectionJob(nil).Run(ctx)
+	go c.UsageHistoryGarbageCollectionJob(nil).Run(ctx)
 
 	// use main goroutine to emit Prometheus metrics
 	handler := httpapi.Compose(
@@ -216,14 +285,19 @@ func runObserver(ctx context.Context, cfg core.Config, dbi *gsql.DB, team core.A
 ////////////////////////////////////////////////////////////////////////////////
 // task: worker
 
-func runWorker(ctx context.Context, dbi *gsql.DB, team core.AssetManagerTeam, httpListenAddr string) {
-	c := tasks.Context{DB: dbi, Team: team}
+func runWorker(ctx context.Context, cfg *core.ConfigSource, dbi *gsql.DB, team core.AssetManagerTeam, notifiers core.NotifierTeam, httpListenAddr string) {
+	go cfg.WatchForChanges(ctx, configWatchInterval)
+	c := tasks.Context{Config: cfg, DB: dbi, Team: team, Notifiers: notifiers}
 	c.ApplyDefaults()
 
 	// The worker process has a budget of 16 DB connections. We need one of that
 	// for polling, the rest can go towards resizing workers. Therefore, 12 resize
-	// workers is a safe number that even leaves some headroom for future tasks.
+	// workers is a safe number that even leaves some headroom for future tasks
+	// (like notification delivery, which gets another 2 connections).
 	go c.AssetResizingJob(nil).Run(ctx, jobloop.NumGoroutines(12))
+	if len(notifiers) > 0 {
+		go c.NotificationDeliveryJob(nil).Run(ctx, jobloop.NumGoroutines(2))
+	}
 
 	// use main goroutine to emit Prometheus metrics
 	handler := httpapi.Compose(
@@ -376,3 +450,81 @@ PROMPT:
 
 	os.Stdout.Write([]byte("\n"))
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// task: replay
+
+func runReplay(ctx context.Context, cfg core.Config, resourceJSON, inputPath string) {
+	in := must.Return(replay.LoadInput(inputPath))
+	res, errs := replay.PrepareResource(ctx, in, cfg, resourceJSON)
+	if !errs.IsEmpty() {
+		logg.Fatal("invalid resource configuration: %s", errs.Join(", "))
+	}
+
+	result := replay.Run(in, res, cfg)
+	ops := make([]castellum.StandaloneOperation, 0, len(result.FinishedOperations)+1)
+	for _, op := range result.FinishedOperations {
+		ops = append(ops, api.FinishedOperationFromDB(op, "", nil))
+	}
+	if op, ok := result.PendingOperation.Unpack(); ok {
+		ops = append(ops, api.PendingOperationFromDB(op, "", nil, result.FinishedAt))
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	must.Succeed(enc.Encode(struct {
+		Operations []castellum.StandaloneOperation `json:"operations"`
+	}{ops}))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// task: check-config
+
+func runCheckConfig(ctx context.Context, configPath string, opts checkconfig.Options) {
+	cfg, err := core.LoadConfig(configPath)
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	report := must.Return(checkconfig.Run(ctx, cfg, opts))
+	must.Return(report.WriteTo(os.Stdout))
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// task: export-history
+
+func runExportHistory(cfg core.Config, dbi *gsql.DB, startStr, endStr string, format export.Format) {
+	start, err := time.Parse(time.DateOnly, startStr)
+	if err != nil {
+		logg.Fatal("cannot parse start date: %s", err.Error())
+	}
+	end, err := time.Parse(time.DateOnly, endStr)
+	if err != nil {
+		logg.Fatal("cannot parse end date: %s", err.Error())
+	}
+	if !start.Before(end) {
+		logg.Fatal("start date must be before end date")
+	}
+
+	rows := must.Return(export.Aggregate(dbi, start, end))
+	must.Succeed(export.Write(os.Stdout, rows, format))
+
+	// finished operations that have been garbage-collected cannot be part of the export
+	now := time.Now()
+	warnedAssetTypes := make(map[db.AssetType]bool)
+	for _, row := range rows {
+		if warnedAssetTypes[row.AssetType] {
+			continue
+		}
+		retention := cfg.OperationRetentionFor(row.AssetType)
+		if start.Before(now.Add(-retention)) {
+			logg.Error("finished operations for asset type %q are only kept for %d days, so the export is probably incomplete",
+				row.AssetType, int(retention.Hours()/24))
+			warnedAssetTypes[row.AssetType] = true
+		}
+	}
+}